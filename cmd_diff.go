@@ -44,15 +44,38 @@ func clonePushOptionsToDiff() {
 	}
 
 	descRewrites := map[string]string{
-		"allow-unsafe":    "Permit generating ALTER or DROP operations that are potentially destructive",
-		"alter-wrapper":   "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
-		"brief":           "Don't output DDL to STDOUT; instead output list of instances with at least one difference",
-		"safe-below-size": "Always permit generating destructive operations for tables below this size in bytes",
+		"allow-unsafe":        "Permit generating ALTER or DROP operations that are potentially destructive",
+		"alter-wrapper":       "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
+		"brief":               "Don't output DDL to STDOUT; instead output list of instances with at least one difference",
+		"json":                "Output each DDL statement as a line of JSON, including its predicted ALTER algorithm",
+		"safe-below-size":     "Always permit generating destructive operations for tables below this size in bytes",
+		"safe-below-rows":     "Always permit generating destructive operations for tables with fewer than this many rows",
+		"alter-copy-max-size": "Prevent generating ALTERs expected to use ALGORITHM=COPY on tables of at least this size in bytes",
 	}
 	hiddenRewrites := map[string]bool{
-		"brief":              false,
-		"dry-run":            true,
-		"foreign-key-checks": true,
+		"brief":                 false,
+		"json":                  false,
+		"dry-run":               true,
+		"foreign-key-checks":    true,
+		"blocking-trx-min-time": true,
+		"blocking-trx-action":   true,
+		"blocking-trx-max-wait": true,
+		"replica-lag-threshold": true,
+		"replica-hosts":         true,
+		"replica-lag-max-wait":  true,
+		"ddl-max-attempts":      true,
+		"ddl-retry-backoff":     true,
+		"backup-dir":            true,
+		"backup-max-size":       true,
+		"interactive":           true,
+		"interactive-mode":      true,
+		"pre-push-hook":         true,
+		"post-push-hook":        true,
+		"rollout-canary":        true,
+		"rollout-wave-size":     true,
+		"rollout-pause":         true,
+		"rollout-verify":        true,
+		"rollout-health-check":  true,
 	}
 
 	diffOptions := diff.Options()
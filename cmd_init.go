@@ -206,7 +206,7 @@ func PopulateSchemaDir(s *tengo.Schema, parentDir *fs.Dir, makeSubdir bool) erro
 	}
 
 	if ignoreSchema, err := parentDir.Config.GetRegexp("ignore-schema"); err != nil {
-		return NewExitValue(CodeBadConfig, err.Error())
+		return NewExitValue(CodeBadConfig, "%s", err)
 	} else if ignoreSchema != nil && ignoreSchema.MatchString(s.Name) {
 		log.Debugf("Skipping schema %s because ignore-schema='%s'", s.Name, ignoreSchema)
 		return nil
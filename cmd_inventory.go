@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	summary := "List the hosts and schemas that each directory maps to"
+	desc := "Displays the database hosts and schemas that each directory maps to, based on " +
+		"the host, schema, host-wrapper, and host-inventory options. This is useful for " +
+		"confirming that a host-inventory file is being interpreted as expected.\n\n" +
+		"Output consists of one line per directory and host, with tab-separated columns " +
+		"for the directory path, host address, and schema names. This command does not " +
+		"connect to any database servers, so schema names which can only be determined by " +
+		"querying a server are displayed as the raw value of the schema option.\n\n" +
+		"You may optionally pass an environment name as a CLI arg. This will affect " +
+		"which section of .skeema config files is used. If no environment name is " +
+		"supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("inventory", summary, desc, InventoryHandler)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// InventoryHandler is the handler method for `skeema inventory`
+func InventoryHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	err = inventoryWalker(dir, 5)
+	return NewExitValue(ExitCode(err), "")
+}
+
+func inventoryWalker(dir *fs.Dir, maxDepth int) error {
+	if dir.ParseError != nil {
+		log.Warnf("Skipping %s: %s", dir.Path, dir.ParseError)
+		return NewExitValue(CodeBadConfig, "")
+	}
+
+	// Only display dirs which define their own host or host-inventory, or map to
+	// a schema
+	var definesHost bool
+	if dir.OptionFile != nil {
+		for _, name := range []string{"host", "host-inventory"} {
+			if val, _ := dir.OptionFile.OptionValue(name); val != "" {
+				definesHost = true
+			}
+		}
+	}
+	if definesHost || dir.HasSchema() {
+		// Hosts are obtained without building Instances, since that could prompt
+		// for a password, run password-command, or set up SSH tunnels
+		hosts, err := dir.Hostnames()
+		if err != nil {
+			log.Errorf("Skipping %s: %s", dir, err)
+			return NewExitValue(CodeBadConfig, "") // don't walk subdirs if this dir's configuration is broken
+		}
+		for _, host := range hosts {
+			addr, port, err := inventoryAddress(dir, host)
+			if err != nil {
+				log.Errorf("Skipping %s: %s", dir, err)
+				return NewExitValue(CodeBadConfig, "")
+			}
+			schemas := "-"
+			if dir.HasSchema() {
+				splitHost, _, _ := tengo.SplitHostOptionalPort(host)
+				names, err := dir.InventorySchemaNames(splitHost, port)
+				if err != nil {
+					log.Errorf("Skipping %s: %s", dir, err)
+					return NewExitValue(CodeBadConfig, "")
+				} else if names != nil {
+					schemas = strings.Join(names, ",")
+				} else if raw := dir.Config.GetRaw("schema"); raw != "" {
+					schemas = raw
+				}
+			}
+			fmt.Printf("%s\t%s\t%s\n", dir.RelPath(), addr, schemas)
+		}
+	}
+
+	var result error
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return err
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Errorf("Not walking subdirs of %s: max depth reached", dir)
+		return nil
+	}
+	for _, sub := range subdirs {
+		err := inventoryWalker(sub, maxDepth-1)
+		result = HighestExitCode(result, err)
+	}
+	return result
+}
+
+// inventoryAddress returns the display address for host, in the same format
+// as the corresponding tengo.Instance's String method, as well as its port
+// number. The port is 0 if the host will be reached via a socket.
+func inventoryAddress(dir *fs.Dir, host string) (addr string, port int, err error) {
+	defaultPort, portWasSupplied := dir.Port()
+	if host == "localhost" && dir.Config.GetAllowEnvVar("ssh-host") == "" && (dir.Config.Supplied("socket") || !portWasSupplied) {
+		return "localhost:" + dir.Config.GetAllowEnvVar("socket"), 0, nil
+	}
+	splitHost, splitPort, err := tengo.SplitHostOptionalPort(host)
+	if err != nil {
+		return "", 0, err
+	} else if splitPort == 0 {
+		splitPort = defaultPort
+	}
+	return fmt.Sprintf("%s:%d", splitHost, splitPort), splitPort, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+func TestInventoryHandler(t *testing.T) {
+	repoPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(repoPath, "inventory.yaml"), `
+production:
+  users:
+    port: 3307
+    hosts:
+      - host: users1.db.host
+        schemas: [users_001, users_002]
+      - users2.db.host:3308
+  orders:
+    hosts: [orders1.db.host, orders2.db.host]
+`)
+
+	// The top-level password option without a value would require an
+	// interactive prompt if any connections were made, and password-command
+	// would fail if run
+	fs.WriteTestFile(t, filepath.Join(repoPath, ".skeema"), "password\npassword-command=exit 1\n")
+	fs.WriteTestFile(t, filepath.Join(repoPath, "users", ".skeema"), "host=users\nhost-inventory=../inventory.yaml\nschema=*\n")
+	fs.WriteTestFile(t, filepath.Join(repoPath, "orders", ".skeema"), "host=orders\n")
+
+	// A dir which only sets host-inventory, inheriting host from its parent,
+	// should still be listed
+	fs.WriteTestFile(t, filepath.Join(repoPath, "orders", "shards", ".skeema"), "host-inventory=../../inventory.yaml\n")
+
+	t.Chdir(repoPath)
+	outPath := filepath.Join(t.TempDir(), "inventory.out")
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		t.Fatalf("Unable to redirect stdout to a file: %v", err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = outFile
+	cfg := mybase.ParseFakeCLI(t, CommandSuite, "skeema inventory")
+	err = InventoryHandler(cfg)
+	os.Stdout = oldStdout
+	outFile.Close()
+	if code := ExitCode(err); code != CodeSuccess {
+		t.Errorf("Expected exit code %d, instead found %d (%v)", CodeSuccess, code, err)
+	}
+
+	expected := "orders\torders:3306\t-\n" +
+		"orders/shards\torders1.db.host:3306\t-\n" +
+		"orders/shards\torders2.db.host:3306\t-\n" +
+		"users\tusers1.db.host:3307\tusers_001,users_002\n" +
+		"users\tusers2.db.host:3308\t*\n"
+	if actual := fs.ReadTestFile(t, outPath); actual != expected {
+		t.Errorf("Unexpected output from InventoryHandler\nExpected:\n%sActual:\n%s", expected, actual)
+	}
+}
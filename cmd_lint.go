@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -22,6 +24,11 @@ func init() {
 		"as linter errors.\n\n" +
 		"By default, this command also reformats statements to their canonical form, " +
 		"just like `skeema format`.\n\n" +
+		"With --fix, some problems are corrected automatically, for example non-default " +
+		"int display widths, the legacy utf8mb3 character set, zero-date defaults, " +
+		"disallowed storage engines, or a missing primary key on a table with a single " +
+		"eligible unique key. Corrected statements are verified in a workspace before " +
+		"the *.sql files are rewritten.\n\n" +
 		"This command relies on accessing database instances to test the SQL DDL in a " +
 		"temporary location. See the --workspace option for more information.\n\n" +
 		"You may optionally pass an environment name as a CLI arg. This will affect " +
@@ -32,7 +39,10 @@ func init() {
 		"supplied, the default is \"production\".\n\n" +
 		"An exit code of 0 will be returned if no errors or warnings were emitted and all " +
 		"files were already formatted properly; 1 if any warnings were emitted and/or " +
-		"some files were reformatted; or 2+ if any errors were emitted for any reason."
+		"some files were reformatted; or 2+ if any errors were emitted for any reason.\n\n" +
+		"To adopt new linter rules incrementally, use --write-baseline to record all " +
+		"current problems in the file specified by --baseline. Subsequent runs with " +
+		"--baseline will only report problems which are not present in that file."
 
 	cmd := mybase.NewCommand("lint", summary, desc, LintHandler)
 	linter.AddCommandOptions(cmd)
@@ -40,6 +50,14 @@ func init() {
 		mybase.BoolOption("format", 0, true, "Reformat SQL statements to match canonical SHOW CREATE"),
 		mybase.BoolOption("strip-partitioning", 0, false, "Remove PARTITION BY clauses from *.sql files"),
 	)
+	cmd.AddOptions("Fix",
+		mybase.BoolOption("fix", 0, false, "Automatically correct problems for linter rules which support it, rewriting *.sql files"),
+	)
+	cmd.AddOptions("Baseline",
+		mybase.StringOption("baseline", 0, "", "Only report problems which are not recorded in this baseline file"),
+		mybase.BoolOption("write-baseline", 0, false, "Record all current problems in the file specified by --baseline, instead of comparing against it"),
+		mybase.BoolOption("baseline-report-fixed", 0, false, "Emit warnings for entries in the baseline file which no longer match any problem"),
+	)
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -52,7 +70,50 @@ func LintHandler(cfg *mybase.Config) error {
 		return err
 	}
 
-	result := lintWalker(dir, 5)
+	baselinePath := dir.PathOption("baseline")
+	writeBaseline := dir.Config.GetBool("write-baseline")
+	var baseline *linter.Baseline
+	if baselinePath == "" {
+		if writeBaseline {
+			return NewExitValue(CodeBadConfig, "Option write-baseline requires option baseline to also be set")
+		}
+	} else if writeBaseline {
+		absPath, err := filepath.Abs(baselinePath)
+		if err != nil {
+			return NewExitValue(CodeBadConfig, "%s", err)
+		}
+		baseline = linter.NewBaseline(filepath.Dir(absPath))
+	} else if baseline, err = linter.ReadBaseline(baselinePath); err != nil {
+		return NewExitValue(CodeBadConfig, "%s", err)
+	}
+
+	// When writing a baseline, all annotations are reported and recorded;
+	// otherwise, annotations present in the baseline are filtered out
+	filter := baseline
+	if writeBaseline {
+		filter = nil
+	}
+	result := lintWalker(dir, 5, filter)
+	if len(result.Exceptions) == 0 && baseline != nil {
+		if writeBaseline {
+			baseline.AddResult(result)
+			if err := baseline.WriteFile(baselinePath); err != nil {
+				return NewExitValue(CodeCantCreate, "Unable to write baseline file: %s", err)
+			}
+			log.Infof("Wrote %s to baseline file %s", countAndNoun(len(baseline.Entries), "entry", "entries"), baselinePath)
+			return nil
+		}
+		if result.BaselinedCount > 0 {
+			log.Infof("Ignored %s recorded in baseline file %s", countAndNoun(result.BaselinedCount, "problem", "problems"), baselinePath)
+		}
+		if dir.Config.GetBool("baseline-report-fixed") {
+			for _, entry := range baseline.Fixed(dir.Path) {
+				log.Warnf("Baseline entry no longer matches any problem, and may be removed: %s", entry)
+				result.WarningCount++
+			}
+		}
+	}
+
 	switch {
 	case len(result.Exceptions) > 0:
 		exitCode := ExitCode(HighestExitCode(result.Exceptions...))
@@ -72,19 +133,25 @@ func LintHandler(cfg *mybase.Config) error {
 		return NewExitValue(CodePartialError, "Found %s",
 			countAndNoun(result.WarningCount, "warning", "warnings"),
 		)
-	case result.ReformatCount > 0:
+	case result.ReformatCount > 0 || result.FixCount > 0:
 		return NewExitValue(CodeDifferencesFound, "")
 	}
 	return nil
 }
 
-func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
+// lintWalker lints dir and its subdirs recursively, logging annotations along
+// the way. If baseline is non-nil, any annotations present in the baseline are
+// omitted from the result.
+func lintWalker(dir *fs.Dir, maxDepth int, baseline *linter.Baseline) *linter.Result {
 	if dir.ParseError != nil {
 		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError))
 		return linter.BadConfigResult(dir, dir.ParseError)
 	}
 	log.Infof("Linting %s", dir)
 	result := lintDir(dir)
+	if baseline != nil {
+		baseline.Filter(result)
+	}
 	for _, err := range result.Exceptions {
 		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.RelPath(), err))
 	}
@@ -107,7 +174,7 @@ func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
 		subdirErr = fmt.Errorf("Not walking subdirs of %s: max depth reached", dir)
 	} else {
 		for _, sub := range subdirs {
-			result.Merge(lintWalker(sub, maxDepth-1))
+			result.Merge(lintWalker(sub, maxDepth-1, baseline))
 		}
 	}
 	if subdirErr != nil {
@@ -155,6 +222,18 @@ func lintDir(dir *fs.Dir) *linter.Result {
 			result.Fatal(err)
 			continue
 		}
+
+		// Automatically fix problems if requested. This must be done prior to
+		// reformatting, so that the fixed statements are also reformatted.
+		if dir.Config.GetBool("fix") {
+			var fixCount int
+			wsSchema, fixCount, err = fixLogicalSchema(dir, wsSchema, wsOpts, opts)
+			result.FixCount += fixCount
+			if err != nil {
+				result.Fatal(err)
+				continue
+			}
+		}
 		result.AnnotateStatementErrors(wsSchema.Failures, opts)
 
 		// Reformat statements if requested. This must be done prior to checking for
@@ -201,6 +280,69 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	return result
 }
 
+// fixLogicalSchema applies automatic fixes for problems found in wsSchema,
+// rewriting the corresponding *.sql files. The fixed statements are first
+// executed in a workspace to verify them; any fix which causes an error there is
+// reverted. The returned workspace.Schema reflects the fixed statements, along
+// with a count of fixed statements.
+func fixLogicalSchema(dir *fs.Dir, wsSchema *workspace.Schema, wsOpts workspace.Options, opts linter.Options) (*workspace.Schema, int, error) {
+	fixes := linter.FixSchema(wsSchema, opts)
+	if len(fixes) == 0 {
+		return wsSchema, 0, nil
+	}
+	origTexts := make([]string, len(fixes))
+	revertAll := func() {
+		for n, fix := range fixes {
+			fix.Statement.Text = origTexts[n]
+			dir.FileFor(fix.Statement).Dirty = false
+		}
+	}
+	for n, fix := range fixes {
+		origTexts[n] = fix.Statement.Text
+		dir.FileFor(fix.Statement).EditStatementText(fix.Statement, fix.Text, false)
+	}
+	fixedSchema, err := workspace.ExecLogicalSchema(wsSchema.LogicalSchema, wsOpts)
+	if err != nil {
+		revertAll()
+		return wsSchema, 0, err
+	}
+
+	// Revert any fixes that failed verification, and write the rest
+	failedKeys := make(map[tengo.ObjectKey]bool)
+	for _, key := range fixedSchema.FailedKeys() {
+		failedKeys[key] = true
+	}
+	filesToWrite := make(map[*fs.SQLFile]bool)
+	var fixCount int
+	for n, fix := range fixes {
+		sqlFile := dir.FileFor(fix.Statement)
+		if failedKeys[fix.Statement.ObjectKey()] {
+			log.Warnf("Unable to automatically fix %s at %s: corrected statement failed verification", fix.Statement.ObjectKey(), fix.Statement.Location())
+			fix.Statement.Text = origTexts[n]
+			if !filesToWrite[sqlFile] {
+				sqlFile.Dirty = false
+			}
+			continue
+		}
+		log.Infof("Fixed %s at %s (%s)", fix.Statement.ObjectKey(), fix.Statement.Location(), "lint-"+strings.Join(fix.RuleNames, ", lint-"))
+		filesToWrite[sqlFile] = true
+		sqlFile.Dirty = true
+		fixCount++
+	}
+	if fixCount < len(fixes) {
+		if fixedSchema, err = workspace.ExecLogicalSchema(wsSchema.LogicalSchema, wsOpts); err != nil {
+			revertAll()
+			return wsSchema, 0, err
+		}
+	}
+	for sqlFile := range filesToWrite {
+		if _, err := sqlFile.Write(); err != nil {
+			return fixedSchema, fixCount, fmt.Errorf("Unable to write fixed statements to %s: %w", sqlFile.FilePath, err)
+		}
+	}
+	return fixedSchema, fixCount, nil
+}
+
 func countAndNoun(n int, singular, plural string) string {
 	if n == 1 {
 		return fmt.Sprintf("1 %s", singular)
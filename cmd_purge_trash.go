@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	summary := "Drop tables previously moved to the trash schema by push"
+	desc := "Permanently drops tables which `skeema push --drop-mode=trash` moved into " +
+		"the trash schema, once they are older than the --older-than option. The age " +
+		"of each table is determined by the timestamp suffix of its name; tables in " +
+		"the trash schema without such a suffix are never dropped.\n\n" +
+		"Each database instance mapped to by the directory tree is processed once. " +
+		"The dropped tables are not recoverable, so consider using --dry-run first to " +
+		"review which tables would be affected.\n\n" +
+		"You may optionally pass an environment name as a CLI arg. This will affect " +
+		"which section of .skeema config files is used for processing. If no " +
+		"environment name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("purge-trash", summary, desc, PurgeTrashHandler)
+	cmd.AddOption(mybase.StringOption("older-than", 0, "7d", `Only drop tables moved to trash longer ago than this duration (e.g. "12h", "30d")`))
+	cmd.AddOption(mybase.StringOption("trash-schema", 0, "_skeema_trash", "Schema containing tables moved by --drop-mode=trash"))
+	cmd.AddOption(mybase.BoolOption("dry-run", 0, false, "Output DROP TABLE statements but don't run them"))
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// PurgeTrashHandler is the handler method for `skeema purge-trash`
+func PurgeTrashHandler(cfg *mybase.Config) error {
+	age, err := applier.ParseAge(cfg.Get("older-than"))
+	if err != nil {
+		return NewExitValue(CodeBadConfig, "Invalid value for option older-than: %s", err)
+	}
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-age)
+	seen := make(map[string]bool)
+	err = purgeTrashWalker(dir, cutoff, seen, 5)
+	return NewExitValue(ExitCode(err), "")
+}
+
+func purgeTrashWalker(dir *fs.Dir, cutoff time.Time, seen map[string]bool, maxDepth int) error {
+	if dir.ParseError != nil {
+		log.Warnf("Skipping %s: %s", dir, dir.ParseError)
+		return NewExitValue(CodeBadConfig, "")
+	}
+
+	var result error
+	trashSchema := dir.Config.Get("trash-schema")
+	if dir.Config.Changed("host") && trashSchema != "" {
+		instances, err := dir.Instances()
+		if err != nil {
+			log.Errorf("Skipping %s: %s", dir, err)
+			return NewExitValue(CodeBadConfig, "")
+		}
+		for _, inst := range instances {
+			key := inst.String() + " " + trashSchema
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if err := purgeTrash(inst, trashSchema, cutoff, dir.Config.GetBool("dry-run")); err != nil {
+				log.Errorf("Error purging trash schema %s on %s: %s", trashSchema, inst, err)
+				result = NewExitValue(CodeFatalError, "")
+			}
+		}
+	}
+
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return NewExitValue(CodeFatalError, "")
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Warnf("Not walking subdirs of %s: max depth reached", dir)
+		return result
+	}
+	for _, sub := range subdirs {
+		err := purgeTrashWalker(sub, cutoff, seen, maxDepth-1)
+		result = HighestExitCode(result, err)
+	}
+	return result
+}
+
+// purgeTrash drops tables in trashSchema on inst which were moved there before
+// cutoff. With dryRun, the DROP TABLE statements are output but not run.
+func purgeTrash(inst *tengo.Instance, trashSchema string, cutoff time.Time, dryRun bool) error {
+	names, err := applier.TrashTablesBefore(inst, trashSchema, cutoff)
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	db, err := inst.CachedConnectionPool(trashSchema, "readTimeout=0")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("-- instance: %s\nUSE %s;\n", inst, tengo.EscapeIdentifier(trashSchema))
+	for _, name := range names {
+		stmt := "DROP TABLE " + tengo.EscapeIdentifier(name)
+		fmt.Printf("%s;\n", stmt)
+		if !dryRun {
+			if _, err := db.Exec(stmt); err != nil {
+				return err
+			}
+		}
+	}
+	if dryRun {
+		log.Infof("%s: would drop %s from %s", inst, countAndNoun(len(names), "table", "tables"), trashSchema)
+	} else {
+		log.Infof("%s: dropped %s from %s", inst, countAndNoun(len(names), "table", "tables"), trashSchema)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
@@ -9,6 +10,7 @@ import (
 	"github.com/skeema/skeema/internal/applier"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/skeema/internal/workspace"
 	"golang.org/x/sync/errgroup"
 )
@@ -25,8 +27,9 @@ func init() {
 		"[staging] section of config files, as well as any sectionless directives at the " +
 		"top of the file. If no environment name is supplied, the default is \"production\".\n\n" +
 		"An exit code of 0 will be returned if the operation was fully successful; 1 if " +
-		"at least one table could not be updated due to use of unsupported features, or if " +
-		"the --dry-run option was used and differences were found; or 2+ if a fatal error " +
+		"at least one table could not be updated due to use of unsupported features, if " +
+		"any statements were declined with --interactive, or if the --dry-run option " +
+		"was used and differences were found; or 2+ if a fatal error " +
 		"occurred."
 
 	cmd := mybase.NewCommand("push", summary, desc, PushHandler)
@@ -38,6 +41,9 @@ func init() {
 		mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`),
 		mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy")`),
 		mybase.StringOption("partitioning", 0, "keep", `Specify handling of partitioning status on the database side (valid values: "keep", "remove", "modify")`),
+		mybase.StringOption("only-table", 0, "", "Restrict DDL to tables matching regex; with any only-* option, non-matching objects of all types are skipped"),
+		mybase.StringOption("only-proc", 0, "", "Restrict DDL to stored procedures matching regex; see --only-table"),
+		mybase.StringOption("only-func", 0, "", "Restrict DDL to functions matching regex; see --only-table"),
 	)
 
 	cmd.AddOptions("External tool",
@@ -57,12 +63,46 @@ func init() {
 		mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"),
 		mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"),
 		mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"),
+		mybase.StringOption("safe-below-rows", 0, "0", "Always permit destructive operations for tables with fewer than this many rows"),
+		mybase.BoolOption("safe-narrowing-check", 0, false, "Permit narrowing column types if querying the table confirms all existing values fit"),
+		mybase.StringOption("safe-narrowing-max-rows", 0, "1000000", "With --safe-narrowing-check, skip querying tables with more than this many rows"),
+		mybase.StringOption("blocking-trx-min-time", 0, "0", "Before direct ALTER or DROP TABLE, check for transactions/queries on the table running this many seconds"),
+		mybase.StringOption("blocking-trx-action", 0, "abort", `Handling of sessions found by --blocking-trx-min-time (valid values: "abort", "wait", "kill")`),
+		mybase.StringOption("blocking-trx-max-wait", 0, "300", "With --blocking-trx-action=wait, max seconds to wait before aborting"),
+		mybase.StringOption("alter-copy-max-size", 0, "0", "Prevent ALTERs expected to use ALGORITHM=COPY on tables of at least this size in bytes"),
+		mybase.StringOption("replica-lag-threshold", 0, "0", "Before each DDL statement, pause while any replica is lagging more than this many seconds"),
+		mybase.StringOption("replica-hosts", 0, "", "Comma-separated list of replicas to check for replica-lag-threshold (default discover via SHOW REPLICAS)"),
+		mybase.StringOption("replica-lag-max-wait", 0, "3600", "With --replica-lag-threshold, max seconds to pause before aborting (0 for no limit)"),
+		mybase.StringOption("ddl-max-attempts", 0, "1", "Max attempts for table DDL failing with a lock wait timeout or deadlock"),
+		mybase.StringOption("ddl-retry-backoff", 0, "5", "With --ddl-max-attempts, seconds to wait before first retry, doubling for each subsequent retry"),
+		mybase.StringOption("drop-mode", 0, "drop", `Handling of tables removed from the filesystem: "drop" them, or move them to trash-schema ("trash")`),
+		mybase.StringOption("trash-schema", 0, "_skeema_trash", "With --drop-mode=trash, schema to move removed tables into"),
+		mybase.StringOption("deprecation-grace-period", 0, "7d", `Permit dropping columns deprecated (made invisible) by a previous push at least this long ago (e.g. "12h", "30d")`),
+		mybase.StringOption("backup-dir", 0, "", "Before destructive DDL on a non-empty table, export the affected data to a compressed file in this dir"),
+		mybase.StringOption("backup-max-size", 0, "1G", "With --backup-dir, prevent destructive DDL if the data to export exceeds this size in bytes (0 for no limit)"),
+		mybase.BoolOption("interactive", 0, false, "Display planned DDL and prompt for confirmation before running it; requires STDIN to be a TTY"),
+		mybase.StringOption("interactive-mode", 0, "target", `With --interactive, prompt once per "target" (schema on an instance) or per "statement"`),
+	)
+
+	cmd.AddOptions("hooks",
+		mybase.StringOption("pre-push-hook", 0, "", "External command to run before pushing changes to each instance and schema; see manual for template vars"),
+		mybase.StringOption("post-push-hook", 0, "", "External command to run after pushing changes to each instance and schema; see manual for template vars"),
 	)
 
 	cmd.AddOptions("sharding",
 		mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple instances or schemas, just run against the first per dir"),
 		mybase.BoolOption("brief", 'q', false, "<overridden by diff command>").Hidden(),
+		mybase.BoolOption("json", 0, false, "<overridden by diff command>").Hidden(),
 		mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of instances concurrently"),
+		mybase.StringOption("concurrent-schemas", 0, "1", "Perform operations on this number of schemas concurrently per instance"),
+	)
+
+	cmd.AddOptions("staged rollout",
+		mybase.StringOption("rollout-canary", 0, "", "Push to this number (or percentage, e.g. \"5%\") of instances first, before any others"),
+		mybase.StringOption("rollout-wave-size", 0, "", "With --rollout-canary, push to remaining instances in waves of this number or percentage (default all at once)"),
+		mybase.StringOption("rollout-pause", 0, "0", `With --rollout-canary, seconds to pause between waves, or "prompt" to ask before continuing`),
+		mybase.BoolOption("rollout-verify", 0, true, "With --rollout-canary, re-introspect each instance after pushing to confirm no differences remain"),
+		mybase.StringOption("rollout-health-check", 0, "", "With --rollout-canary, external command to run after pushing to each instance; see manual for template vars"),
 	)
 
 	workspace.AddCommandOptions(cmd)
@@ -77,8 +117,10 @@ func PushHandler(cfg *mybase.Config) error {
 	// * --brief only affects `skeema diff` (aka `skeema push --dry-run`)
 	// * --brief automatically uses --skip-verify --skip-lint --allow-unsafe
 	// * --brief omits INFO-level logging, unless --debug was used
+	// --json similarly only affects `skeema diff`, and is ignored with --brief.
 	if !cfg.GetBool("dry-run") {
 		cfg.SetRuntimeOverride("brief", "0")
+		cfg.SetRuntimeOverride("json", "0")
 	} else if cfg.GetBool("brief") {
 		cfg.SetRuntimeOverride("verify", "0")
 		cfg.SetRuntimeOverride("lint", "0")
@@ -95,48 +137,115 @@ func PushHandler(cfg *mybase.Config) error {
 
 	concurrency, err := dir.Config.GetInt("concurrent-instances")
 	if err != nil {
-		return NewExitValue(CodeBadConfig, err.Error())
+		return NewExitValue(CodeBadConfig, "%s", err)
 	} else if concurrency < 1 {
 		return NewExitValue(CodeBadConfig, "concurrent-instances cannot be less than 1")
 	}
+	schemaConcurrency, err := dir.Config.GetInt("concurrent-schemas")
+	if err != nil {
+		return NewExitValue(CodeBadConfig, "%s", err)
+	} else if schemaConcurrency < 1 {
+		return NewExitValue(CodeBadConfig, "concurrent-schemas cannot be less than 1")
+	}
+	if dir.Config.GetBool("interactive") && !dir.Config.GetBool("dry-run") && !util.StdinIsTerminal() {
+		return NewExitValue(CodeBadConfig, "Option interactive requires STDIN to be a TTY")
+	}
 	printer := applier.NewPrinter(dir.Config)
 
-	g, ctx := errgroup.WithContext(context.Background())
-	g.SetLimit(concurrency)
 	groups, skipCount := applier.TargetGroupsForDir(dir)
 	sum := applier.Result{SkipCount: skipCount}
+	plan, err := applier.RolloutPlanForDir(dir.Config, groups)
+	if err != nil {
+		return err
+	}
+
+	// Without staged rollout, all instances are handled in a single wave
+	waves := [][]applier.TargetGroup{groups}
+	if plan != nil {
+		waves = plan.Waves
+		log.Infof("Staged rollout: pushing to %s in %s, beginning with %s", countAndNoun(len(groups), "instance", "instances"), countAndNoun(len(waves), "wave", "waves"), countAndNoun(len(waves[0]), "canary instance", "canary instances"))
+	}
+	var pushedCount int
+	for n, wave := range waves {
+		waveResult, err := pushWave(wave, printer, concurrency, schemaConcurrency, plan, n+1)
+		sum.Merge(waveResult)
+		if err != nil {
+			return err
+		}
+		pushedCount += len(wave)
+		if plan == nil {
+			continue
+		} else if waveResult.SkipCount > 0 {
+			log.Errorf("Stopping staged rollout due to failure in wave %d of %d; %s not pushed", n+1, len(waves), countAndNoun(len(groups)-pushedCount, "remaining instance", "remaining instances"))
+			return NewExitValue(CodeFatalError, "%s", sum.Summary())
+		} else if n+1 < len(waves) {
+			if err := plan.BetweenWaves(n + 1); err != nil {
+				log.Errorf("Stopping staged rollout: %s; %s not pushed", err, countAndNoun(len(groups)-pushedCount, "remaining instance", "remaining instances"))
+				return NewExitValue(CodeFatalError, "%s", sum.Summary())
+			}
+		}
+	}
+
+	if sum.SkipCount > 0 {
+		return NewExitValue(CodeFatalError, "%s", sum.Summary())
+	} else if sum.UnsupportedCount > 0 {
+		return NewExitValue(CodePartialError, "%s", sum.Summary())
+	} else if sum.DeclinedCount > 0 {
+		// Declining statements in interactive mode isn't a failure, but the
+		// database still differs from the filesystem
+		return NewExitValue(CodeDifferencesFound, "%s", sum.Summary())
+	} else if dir.Config.GetBool("dry-run") && sum.Differences {
+		return NewExitValue(CodeDifferencesFound, "")
+	}
+	return nil
+}
+
+// errStopWave is used internally by pushWave to cancel the rest of a staged
+// rollout wave after a failure.
+var errStopWave = errors.New("stopping wave due to failure")
+
+// pushWave applies the supplied target groups, operating on up to concurrency
+// instances at once, and up to schemaConcurrency schemas at once per instance.
+// It returns the combined result. If plan is non-nil, each instance is checked
+// after being pushed, and the first failure cancels the rest of the wave; in
+// this case the returned result will have a non-zero SkipCount.
+func pushWave(groups []applier.TargetGroup, printer applier.Printer, concurrency, schemaConcurrency int, plan *applier.RolloutPlan, waveNumber int) (applier.Result, error) {
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(concurrency)
+	var sum applier.Result
 	var sumLock sync.Mutex
 
 	for n := range groups {
 		tg := groups[n] // avoid loop iteration variable in closure below
 		g.Go(func() error {
 			defer panicHandler()
-			for _, t := range tg {
-				select {
-				case <-ctx.Done():
-					return nil // Exit early if context cancelled
-				default:
-					result, err := applier.ApplyTarget(t, printer)
-					if err != nil {
-						return err
-					}
-					sumLock.Lock()
-					sum.Merge(result)
-					sumLock.Unlock()
+			groupResult, err := applier.ApplyTargetGroup(ctx, tg, printer, schemaConcurrency)
+			sumLock.Lock()
+			sum.Merge(groupResult)
+			sumLock.Unlock()
+			if err != nil {
+				return err
+			} else if plan == nil {
+				return nil
+			} else if groupResult.SkipCount > 0 {
+				return errStopWave
+			} else if err := plan.CheckGroup(tg, waveNumber); err != nil {
+				if _, ok := err.(applier.ConfigError); ok {
+					return err
 				}
+				log.Errorf("%s\n", err)
+				sumLock.Lock()
+				sum.SkipCount++
+				sumLock.Unlock()
+				return errStopWave
 			}
 			return nil
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return err
-	} else if sum.SkipCount > 0 {
-		return NewExitValue(CodeFatalError, sum.Summary())
-	} else if sum.UnsupportedCount > 0 {
-		return NewExitValue(CodePartialError, sum.Summary())
-	} else if dir.Config.GetBool("dry-run") && sum.Differences {
-		return NewExitValue(CodeDifferencesFound, "")
+	err := g.Wait()
+	if err == errStopWave {
+		err = nil
 	}
-	return nil
+	return sum, err
 }
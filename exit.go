@@ -153,6 +153,6 @@ func panicHandler() {
 			"This situation indicates a bug in Skeema. Use --debug to view full stack trace.",
 			"Please file an issue report at https://github.com/skeema/skeema/issues with any available background information.",
 		}
-		Exit(NewExitValue(CodeFatalError, strings.Join(messages, "\n")))
+		Exit(NewExitValue(CodeFatalError, "%s", strings.Join(messages, "\n")))
 	}
 }
@@ -1,6 +1,6 @@
 module github.com/skeema/skeema
 
-go 1.19
+go 1.26.0
 
 require (
 	github.com/VividCortex/mysqlerr v1.0.0
@@ -12,8 +12,10 @@ require (
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/sirupsen/logrus v1.9.0
 	github.com/skeema/mybase v1.0.17
+	golang.org/x/crypto v0.57.0
 	golang.org/x/sync v0.1.0
-	golang.org/x/term v0.9.0
+	golang.org/x/term v0.46.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -34,6 +36,6 @@ require (
 	github.com/opencontainers/runc v1.1.5 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	golang.org/x/mod v0.8.0 // indirect
-	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 )
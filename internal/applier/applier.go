@@ -3,13 +3,19 @@
 package applier
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/linter"
 	"github.com/skeema/skeema/internal/tengo"
 	"github.com/skeema/skeema/internal/util"
+	"golang.org/x/sync/errgroup"
 )
 
 // ClientState provides information on where and how a SQL statement would be
@@ -35,6 +41,7 @@ type Result struct {
 	Differences      bool
 	SkipCount        int
 	UnsupportedCount int
+	DeclinedCount    int // statements not executed at user request in interactive mode
 }
 
 // Merge modifies the receiver to include the sub-totals from the supplied arg.
@@ -42,25 +49,34 @@ func (r *Result) Merge(other Result) {
 	r.Differences = r.Differences || other.Differences
 	r.SkipCount += other.SkipCount
 	r.UnsupportedCount += other.UnsupportedCount
+	r.DeclinedCount += other.DeclinedCount
 }
 
 // Summary returns a string reflecting the contents of the result.
 func (r Result) Summary() string {
-	if r.SkipCount+r.UnsupportedCount == 0 {
-		return ""
-	}
-	var plural, reason string
-	if r.SkipCount+r.UnsupportedCount > 1 {
-		plural = "s"
+	var parts []string
+	if r.SkipCount+r.UnsupportedCount > 0 {
+		var plural, reason string
+		if r.SkipCount+r.UnsupportedCount > 1 {
+			plural = "s"
+		}
+		if r.SkipCount == 0 {
+			reason = "unsupported feature"
+		} else if r.UnsupportedCount == 0 {
+			reason = "problem"
+		} else {
+			reason = "problems or unsupported feature"
+		}
+		parts = append(parts, fmt.Sprintf("Skipped %d operation%s due to %s%s", r.SkipCount+r.UnsupportedCount, plural, reason, plural))
 	}
-	if r.SkipCount == 0 {
-		reason = "unsupported feature"
-	} else if r.UnsupportedCount == 0 {
-		reason = "problem"
-	} else {
-		reason = "problems or unsupported feature"
+	if r.DeclinedCount > 0 {
+		verb := "Declined"
+		if len(parts) > 0 {
+			verb = "declined"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s at user request", verb, countAndNoun(r.DeclinedCount, "operation")))
 	}
-	return fmt.Sprintf("Skipped %d operation%s due to %s%s", r.SkipCount+r.UnsupportedCount, plural, reason, plural)
+	return strings.Join(parts, "; ")
 }
 
 // ApplyTarget generates the diff for the supplied target, prints the resulting
@@ -92,8 +108,13 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 		// intentionally want to de-partition it.
 		stripPartitionClauses(schemaFromDir.Tables, mods.Flavor)
 	}
+	applyDeprecations(schemaFromDir, schemaFromInstance, t, mods.Flavor)
 
 	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
+	restricted, err := restrictDiff(diff, t.Dir)
+	if err != nil {
+		return result, err
+	}
 	if vopts, err := VerifierOptionsForTarget(t); err != nil {
 		return result, err
 	} else if err := VerifyDiff(diff, vopts); err != nil {
@@ -107,6 +128,9 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 	stmts := make([]PlannedStatement, 0, len(objDiffs))
 	keys := make([]tengo.ObjectKey, 0, len(objDiffs))
 	for _, objDiff := range objDiffs {
+		if restricted && objDiff.ObjectKey().Type == tengo.ObjectTypeDatabase && objDiff.DiffType() != tengo.DiffTypeCreate {
+			continue // only-* options exclude database-level changes, aside from creating the schema
+		}
 		ddl, err := NewDDLStatement(objDiff, mods, t)
 		if ddl == nil && err == nil {
 			continue // Skip entirely if mods made the statement a noop
@@ -124,7 +148,7 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 			log.Debug(unsupportedErr.ExtendedError())
 		} else {
 			result.SkipCount += len(objDiffs)
-			log.Errorf(err.Error())
+			log.Error(err.Error())
 			if len(objDiffs) > 1 {
 				log.Warnf("Skipping %d additional operations for %s %s due to previous error\n", len(objDiffs)-1, t.Instance, t.SchemaName)
 			}
@@ -153,12 +177,102 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 		}
 	}
 
+	// If throttling on replication lag, determine which replicas to monitor
+	throttler, err := replicaThrottlerForTarget(t)
+	if _, ok := err.(ConfigError); ok {
+		return result, err
+	} else if err != nil {
+		result.SkipCount += len(stmts)
+		log.Errorf("Skipping %s %s: %s\n", t.Instance, t.SchemaName, err)
+		return result, nil
+	}
+
+	// Prepare pre-push-hook and post-push-hook if configured. These are run by
+	// processSQL, so that in interactive mode they only run after confirmation.
+	hooks, err := newPushHooks(t, stmts)
+	if err != nil {
+		return result, err
+	} else if hooks != nil {
+		defer hooks.cleanup()
+	}
+
 	// Print SQL; if not dry-run, execute it; final logging; return result
-	result.SkipCount += t.processSQL(stmts, printer)
+	// (In interactive mode, a non-nil error means the user quit or input could
+	// not be read, in which case no further targets should be processed)
+	skipCount, declinedCount, err := t.processSQL(stmts, printer, throttler, hooks)
+	result.SkipCount += skipCount
+	result.DeclinedCount += declinedCount
+	if err != nil {
+		return result, err
+	}
 	t.logApplyEnd(result)
 	return result, nil
 }
 
+// ApplyTargetGroup applies each target in tg, which should all share the same
+// instance, by calling ApplyTarget. Up to concurrency targets are processed at
+// once, each using a separate workspace. The combined result is returned. If
+// ApplyTarget returns a fatal error, or ctx is cancelled, any targets which
+// have not yet been started are skipped.
+func ApplyTargetGroup(ctx context.Context, tg TargetGroup, printer Printer, concurrency int) (Result, error) {
+	var sum Result
+	if concurrency <= 1 {
+		for _, t := range tg {
+			if ctx.Err() != nil {
+				break // Exit early if context cancelled
+			}
+			result, err := ApplyTarget(t, printer)
+			sum.Merge(result)
+			if err != nil {
+				return sum, err
+			}
+		}
+		return sum, nil
+	}
+
+	var sumLock sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	slots := make(chan int, concurrency)
+	for n := 0; n < concurrency; n++ {
+		slots <- n
+	}
+	for _, t := range tg {
+		t := t // avoid loop iteration variable in closure below
+		slot := <-slots
+		if ctx.Err() != nil {
+			break // Exit early if context cancelled
+		}
+		g.Go(func() error {
+			defer func() { slots <- slot }()
+			t.workspaceSlot = slot
+			result, err := ApplyTarget(t, printer)
+			sumLock.Lock()
+			sum.Merge(result)
+			sumLock.Unlock()
+			return err
+		})
+	}
+	return sum, g.Wait()
+}
+
+// restrictDiff applies the only-table, only-proc, and only-func options of dir
+// to diff, removing any table or routine diffs that do not match. It returns
+// true if any of these options are in use.
+func restrictDiff(diff *tengo.SchemaDiff, dir *fs.Dir) (bool, error) {
+	patterns, err := util.OnlyPatterns(dir.Config)
+	if err != nil {
+		return false, ConfigError(err.Error())
+	} else if patterns == nil {
+		return false, nil
+	}
+	before := len(diff.TableDiffs) + len(diff.RoutineDiffs)
+	diff.RetainMatches(patterns)
+	if excluded := before - len(diff.TableDiffs) - len(diff.RoutineDiffs); excluded > 0 {
+		log.Debugf("Excluding %s for %s due to only-table, only-proc, or only-func options", countAndNoun(excluded, "object difference"), dir)
+	}
+	return true, nil
+}
+
 func stripPartitionClauses(tables []*tengo.Table, flavor tengo.Flavor) {
 	for _, table := range tables {
 		if table.Partitioning != nil {
@@ -183,6 +297,23 @@ func countAndNoun(n int, nouns ...string) string {
 	return fmt.Sprintf("%d %s", n, plural)
 }
 
+// ParseAge converts value to a time.Duration. In addition to the units
+// supported by time.ParseDuration, a suffix of "d" may be used for days.
+func ParseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseUint(days, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q is not a valid duration", value)
+	}
+	return d, nil
+}
+
 // StatementModifiersForDir returns a set of DDL modifiers, based on the
 // directory's configuration.
 func StatementModifiersForDir(dir *fs.Dir) (mods tengo.StatementModifiers, err error) {
@@ -212,6 +343,16 @@ func StatementModifiersForDir(dir *fs.Dir) (mods tengo.StatementModifiers, err e
 		"modify": tengo.PartitioningPermissive,
 	}
 	mods.Partitioning = partMap[partitioning]
+
+	// With drop-mode=trash, tables are renamed rather than dropped, so there is
+	// no reason to drop their partitions first. With backup-dir, the partitions
+	// must not be dropped first, since only the DROP TABLE is backed up; dropping
+	// partitions separately would destroy their data without any backup.
+	var trashSchema string
+	if trashSchema, err = trashSchemaForDir(dir.Config); err != nil {
+		return
+	}
+	mods.SkipPreDropAlters = (trashSchema != "" || dir.Config.Get("backup-dir") != "")
 	return
 }
 
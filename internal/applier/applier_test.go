@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/skeema/skeema/internal/tengo"
 	"github.com/skeema/skeema/internal/util"
@@ -23,6 +24,7 @@ func TestResultMerge(t *testing.T) {
 		Differences:      false,
 		SkipCount:        1,
 		UnsupportedCount: 0,
+		DeclinedCount:    2,
 	}
 	other := Result{
 		Differences:      true,
@@ -33,6 +35,7 @@ func TestResultMerge(t *testing.T) {
 		Differences:      true,
 		SkipCount:        4,
 		UnsupportedCount: 5,
+		DeclinedCount:    2,
 	}
 	r.Merge(other)
 	if r != expectSum {
@@ -40,6 +43,21 @@ func TestResultMerge(t *testing.T) {
 	}
 }
 
+func TestResultSummary(t *testing.T) {
+	cases := map[Result]string{
+		{Differences: true}:                     "",
+		{SkipCount: 1}:                          "Skipped 1 operation due to problem",
+		{SkipCount: 2, UnsupportedCount: 1}:     "Skipped 3 operations due to problems or unsupported features",
+		{DeclinedCount: 1}:                      "Declined 1 operation at user request",
+		{UnsupportedCount: 2, DeclinedCount: 3}: "Skipped 2 operations due to unsupported features; declined 3 operations at user request",
+	}
+	for r, expected := range cases {
+		if actual := r.Summary(); actual != expected {
+			t.Errorf("Unexpected result from Summary() on %+v: expected %q, found %q", r, expected, actual)
+		}
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	images := tengo.SplitEnv("SKEEMA_TEST_IMAGES")
 	if len(images) == 0 {
@@ -111,3 +129,23 @@ func (s *ApplierIntegrationSuite) BeforeTest(backend string) error {
 	}
 	return g.Wait()
 }
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{
+		"7d":   7 * 24 * time.Hour,
+		"0d":   0,
+		"12h":  12 * time.Hour,
+		"90m":  90 * time.Minute,
+		"1h1s": time.Hour + time.Second,
+	}
+	for input, expected := range cases {
+		if actual, err := ParseAge(input); err != nil || actual != expected {
+			t.Errorf("Unexpected result from ParseAge(%q): %s, %v", input, actual, err)
+		}
+	}
+	for _, input := range []string{"", "d", "-3d", "1.5d", "-2h", "7"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("Expected ParseAge(%q) to return an error, but it did not", input)
+		}
+	}
+}
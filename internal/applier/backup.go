@@ -0,0 +1,253 @@
+package applier
+
+import (
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// backupRowsPerInsert is the max number of rows per INSERT statement in a
+// backup file.
+const backupRowsPerInsert = 100
+
+// dataBackup represents an export of data which is about to be lost by a
+// destructive DDL statement. The data is written as INSERT statements to a
+// gzip-compressed file.
+type dataBackup struct {
+	instance   *tengo.Instance
+	schemaName string
+	table      *tengo.Table
+	columns    []*tengo.Column
+	wholeTable bool // true for DROP TABLE
+	path       string
+	maxSize    uint64 // max bytes of uncompressed SQL to write
+}
+
+// newDataBackup returns a dataBackup for diff if the backup-dir option is set
+// and diff is a DROP TABLE, or an ALTER TABLE which drops or unsafely modifies
+// columns. For an ALTER, only the affected columns and the primary key are
+// backed up. A nil dataBackup is returned if no backup is needed, including
+// when the table has no rows. tableSize should be a value previously returned
+// by getTableSize.
+func newDataBackup(diff tengo.ObjectDiff, target *Target, tableSize int64) (*dataBackup, error) {
+	backupDir := target.Dir.PathOption("backup-dir")
+	td, ok := diff.(*tengo.TableDiff)
+	if backupDir == "" || !ok || tableSize == 0 {
+		return nil, nil
+	}
+	maxSize, err := target.Dir.Config.GetBytes("backup-max-size")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	}
+	backup := &dataBackup{
+		instance:   target.Instance,
+		schemaName: target.SchemaName,
+		table:      td.From,
+		maxSize:    maxSize,
+	}
+	switch td.Type {
+	case tengo.DiffTypeDrop:
+		backup.columns = backupColumns(td.From, nil)
+		backup.wholeTable = true
+	case tengo.DiffTypeAlter:
+		lossy := td.LossyColumns()
+		if len(lossy) == 0 {
+			return nil, nil
+		}
+		backup.columns = backupColumns(td.From, lossy)
+	default:
+		return nil, nil
+	}
+
+	// The table size includes indexes, so only use it to reject a backup before
+	// any DDL is run if the whole table is being dropped. Otherwise, the size is
+	// only enforced while writing.
+	if backup.wholeTable && maxSize > 0 && uint64(tableSize) > maxSize {
+		return nil, fmt.Errorf("Preventing execution of %s on %s: table size %d exceeds backup-max-size=%d.\nIncrease backup-max-size, or unset backup-dir to skip backing up this table's data.", td.Type, diff.ObjectKey(), tableSize, maxSize)
+	}
+
+	fileName := fmt.Sprintf("%s.%s.%s.%s.sql.gz",
+		backupFileNamePart(target.Instance.String()),
+		backupFileNamePart(target.SchemaName),
+		backupFileNamePart(td.From.Name),
+		time.Now().UTC().Format("20060102150405"),
+	)
+	backup.path = filepath.Join(backupDir, fileName)
+	return backup, nil
+}
+
+// backupColumns returns the columns of table that should be exported in order
+// to restore the data of lossy columns: the lossy columns themselves, along
+// with the primary key columns. If lossy is nil or the table has no primary
+// key, all columns are returned. Generated columns are always omitted, since
+// their values cannot be inserted. Columns are returned in the table's order.
+func backupColumns(table *tengo.Table, lossy []*tengo.Column) []*tengo.Column {
+	var include map[string]bool
+	if lossy != nil && table.PrimaryKey != nil {
+		include = make(map[string]bool, len(lossy)+len(table.PrimaryKey.Parts))
+		for _, col := range lossy {
+			include[col.Name] = true
+		}
+		for _, part := range table.PrimaryKey.Parts {
+			include[part.ColumnName] = true
+		}
+	}
+	var cols []*tengo.Column
+	for _, col := range table.Columns {
+		if col.GenerationExpr == "" && (include == nil || include[col.Name]) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+var reBackupFileNameUnsafe = regexp.MustCompile(`[^\w.-]+`)
+
+// backupFileNamePart returns s with any characters that may be problematic in
+// file names replaced.
+func backupFileNamePart(s string) string {
+	return reBackupFileNameUnsafe.ReplaceAllString(s, "_")
+}
+
+// hexColumn returns true if values of col should be written as hex literals,
+// rather than quoted strings, in order to preserve binary data exactly.
+func hexColumn(col *tengo.Column) bool {
+	typ := strings.ToLower(col.TypeInDB)
+	if strings.Contains(typ, "binary") || strings.Contains(typ, "blob") || strings.HasPrefix(typ, "bit") {
+		return true
+	}
+	for _, spatial := range []string{"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geomcollection", "geometrycollection"} {
+		if typ == spatial || strings.HasPrefix(typ, spatial+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// backupValue returns a SQL literal representing value, which was obtained
+// from col.
+func backupValue(col *tengo.Column, value sql.RawBytes) string {
+	if value == nil {
+		return "NULL"
+	} else if len(value) > 0 && hexColumn(col) {
+		return "0x" + hex.EncodeToString(value)
+	}
+	return "'" + tengo.EscapeValueForCreateTable(string(value)) + "'"
+}
+
+// Run exports the data to the backup file. If the export fails or exceeds the
+// configured max size, the partial file is removed and an error is returned,
+// so that the destructive DDL is not executed.
+func (backup *dataBackup) Run() (err error) {
+	if err := os.MkdirAll(filepath.Dir(backup.path), 0755); err != nil {
+		return fmt.Errorf("Unable to create backup-dir: %w", err)
+	}
+	f, err := os.OpenFile(backup.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to create backup file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(backup.path)
+			err = fmt.Errorf("Backup of %s.%s failed, so the destructive statement was not run: %w", backup.schemaName, backup.table.Name, err)
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	rowCount, err := backup.write(bufio.NewWriter(gz))
+	if err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Backed up %s of %s.%s from %s to %s", countAndNoun(rowCount, "row"), backup.schemaName, backup.table.Name, backup.instance, backup.path)
+	return nil
+}
+
+// write streams the backup's rows to w, returning the number of rows written.
+func (backup *dataBackup) write(w *bufio.Writer) (rowCount int, err error) {
+	escapedCols := make([]string, len(backup.columns))
+	for n, col := range backup.columns {
+		escapedCols[n] = tengo.EscapeIdentifier(col.Name)
+	}
+	colList := strings.Join(escapedCols, ", ")
+	from := tengo.EscapeIdentifier(backup.schemaName) + "." + tengo.EscapeIdentifier(backup.table.Name)
+
+	var size uint64
+	writeString := func(s string) error {
+		size += uint64(len(s))
+		if backup.maxSize > 0 && size > backup.maxSize {
+			return fmt.Errorf("data exceeds backup-max-size=%d", backup.maxSize)
+		}
+		_, err := w.WriteString(s)
+		return err
+	}
+
+	header := fmt.Sprintf("-- Data backed up from %s %s by skeema push at %s\n", backup.instance, from, time.Now().UTC().Format(time.RFC3339))
+	if backup.wholeTable {
+		header += backup.table.CreateStatement + ";\n"
+	} else {
+		header += "-- Only columns affected by the ALTER, plus any primary key, are included. To\n-- restore, load into a scratch table and join back to the original table.\n"
+	}
+	if err := writeString(header); err != nil {
+		return 0, err
+	}
+
+	db, err := backup.instance.CachedConnectionPool(backup.schemaName, "readTimeout=0")
+	if err != nil {
+		return 0, err
+	}
+	rows, err := db.Query("SELECT " + colList + " FROM " + from)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	values := make([]sql.RawBytes, len(backup.columns))
+	dest := make([]interface{}, len(values))
+	for n := range values {
+		dest[n] = &values[n]
+	}
+	literals := make([]string, len(values))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return rowCount, err
+		}
+		for n := range values {
+			literals[n] = backupValue(backup.columns[n], values[n])
+		}
+		prefix := ",\n"
+		if rowCount%backupRowsPerInsert == 0 {
+			prefix = "INSERT INTO " + tengo.EscapeIdentifier(backup.table.Name) + " (" + colList + ") VALUES\n"
+			if rowCount > 0 {
+				prefix = ";\n" + prefix
+			}
+		}
+		if err := writeString(prefix + "(" + strings.Join(literals, ",") + ")"); err != nil {
+			return rowCount, err
+		}
+		rowCount++
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, err
+	}
+	if rowCount > 0 {
+		if err := writeString(";\n"); err != nil {
+			return rowCount, err
+		}
+	}
+	return rowCount, w.Flush()
+}
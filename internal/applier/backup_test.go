@@ -0,0 +1,240 @@
+package applier
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestBackupColumns(t *testing.T) {
+	table := &tengo.Table{
+		Name: "tbl",
+		Columns: []*tengo.Column{
+			{Name: "a"},
+			{Name: "b"},
+			{Name: "c"},
+			{Name: "d", GenerationExpr: "a + b"},
+			{Name: "e"},
+		},
+		PrimaryKey: &tengo.Index{Parts: []tengo.IndexPart{{ColumnName: "b"}, {ColumnName: "a"}}},
+	}
+	names := func(cols []*tengo.Column) string {
+		var result []string
+		for _, col := range cols {
+			result = append(result, col.Name)
+		}
+		return strings.Join(result, ",")
+	}
+	if actual := names(backupColumns(table, nil)); actual != "a,b,c,e" {
+		t.Errorf("Unexpected result from backupColumns with no lossy columns: %s", actual)
+	}
+	if actual := names(backupColumns(table, []*tengo.Column{table.Columns[4]})); actual != "a,b,e" {
+		t.Errorf("Unexpected result from backupColumns: %s", actual)
+	}
+	table.PrimaryKey = nil
+	if actual := names(backupColumns(table, []*tengo.Column{table.Columns[4]})); actual != "a,b,c,e" {
+		t.Errorf("Unexpected result from backupColumns without primary key: %s", actual)
+	}
+}
+
+func TestBackupValue(t *testing.T) {
+	cases := []struct {
+		typ      string
+		value    []byte
+		expected string
+	}{
+		{"int", []byte("123"), "'123'"},
+		{"varchar(20)", []byte("it's\na\\test"), `'it''s\na\\test'`},
+		{"varchar(20)", []byte{}, "''"},
+		{"varchar(20)", nil, "NULL"},
+		{"varbinary(20)", []byte{0, 1, 255}, "0x0001ff"},
+		{"blob", []byte{}, "''"},
+		{"bit(8)", []byte{5}, "0x05"},
+		{"point", []byte{1}, "0x01"},
+		{"point /*!80003 SRID 4326 */", []byte{1}, "0x01"},
+		{"pointless", []byte("x"), "'x'"},
+	}
+	for _, c := range cases {
+		col := &tengo.Column{Name: "col", TypeInDB: c.typ}
+		if actual := backupValue(col, c.value); actual != c.expected {
+			t.Errorf("For type %s and value %q: expected %s, found %s", c.typ, c.value, c.expected, actual)
+		}
+	}
+}
+
+func TestBackupFileNamePart(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3.4:3306":   "1.2.3.4_3306",
+		"[::1]:3306":     "_1_3306",
+		"product":        "product",
+		"my table/../x":  "my_table_.._x",
+		"under_score-ok": "under_score-ok",
+	}
+	for input, expected := range cases {
+		if actual := backupFileNamePart(input); actual != expected {
+			t.Errorf("Expected backupFileNamePart(%q) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+}
+
+func (s ApplierIntegrationSuite) TestDataBackup(t *testing.T) {
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (name, credits) VALUES ('a', 1.5), ('b''s', NULL), ('c', 3)"); err != nil {
+		t.Fatalf("Unexpected error inserting rows: %s", err)
+	}
+	schema, err := s.d[0].Schema("product")
+	if err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	}
+	users := schema.Table("users")
+	backupDir := t.TempDir()
+	target := &Target{Instance: s.d[0].Instance, Dir: getDir(t, "testdata/simple", "--backup-dir="+backupDir), SchemaName: "product"}
+
+	readBackup := func(diff tengo.ObjectDiff) string {
+		t.Helper()
+		tableSize, err := getTableSize(target, users.Name)
+		if err != nil {
+			t.Fatalf("Unexpected error from getTableSize: %v", err)
+		}
+		backup, err := newDataBackup(diff, target, tableSize)
+		if err != nil || backup == nil {
+			t.Fatalf("Unexpected result from newDataBackup: %+v, %v", backup, err)
+		}
+		if err := backup.Run(); err != nil {
+			t.Fatalf("Unexpected error from Run: %v", err)
+		}
+		f, err := os.Open(backup.path)
+		if err != nil {
+			t.Fatalf("Unable to open backup file: %v", err)
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("Unable to read backup file: %v", err)
+		}
+		contents, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatalf("Unable to read backup file: %v", err)
+		}
+		return string(contents)
+	}
+
+	// DROP TABLE backs up entire table, including its definition
+	contents := readBackup(tengo.NewDropTable(users))
+	if !strings.Contains(contents, users.CreateStatement) {
+		t.Errorf("Expected backup to contain CREATE TABLE, instead found:\n%s", contents)
+	}
+	if !strings.Contains(contents, "INSERT INTO `users` (`id`, `name`, `credits`, `last_modified`) VALUES\n('1','a','1.50','") || !strings.Contains(contents, "('2','b''s',NULL,'") {
+		t.Errorf("Backup did not contain expected rows:\n%s", contents)
+	}
+
+	// Dropping a column just backs up that column and the primary key
+	to := *users
+	to.Columns = []*tengo.Column{users.Columns[0], users.Columns[1], users.Columns[3]}
+	to.CreateStatement = to.GeneratedCreateStatement(s.d[0].Flavor())
+	contents = readBackup(tengo.NewAlterTable(users, &to))
+	if !strings.Contains(contents, "INSERT INTO `users` (`id`, `credits`) VALUES\n('1','1.50'),\n('2',NULL),\n('3','3.00');\n") {
+		t.Errorf("Backup did not contain expected rows:\n%s", contents)
+	}
+
+	// Size limit is enforced, and partial files are removed
+	target.Dir = getDir(t, "testdata/simple", "--backup-dir="+backupDir+" --backup-max-size=20")
+	if backup, err := newDataBackup(tengo.NewDropTable(users), target, 1000); err == nil || backup != nil {
+		t.Errorf("Expected newDataBackup to fail due to backup-max-size, instead found %+v, %v", backup, err)
+	}
+	backup, err := newDataBackup(tengo.NewAlterTable(users, &to), target, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error from newDataBackup: %v", err)
+	} else if err := backup.Run(); err == nil {
+		t.Error("Expected Run to fail due to backup-max-size, but err was nil")
+	} else if _, err := os.Stat(backup.path); !os.IsNotExist(err) {
+		t.Errorf("Expected partial backup file to be removed, but Stat returned %v", err)
+	}
+
+	// Tables without rows are not backed up
+	if backup, err := newDataBackup(tengo.NewDropTable(users), target, 0); err != nil || backup != nil {
+		t.Errorf("Expected no backup of empty table, instead found %+v, %v", backup, err)
+	}
+}
+
+func TestBackupSkipsPreDropAlters(t *testing.T) {
+	table := &tengo.Table{
+		Name:    "parted",
+		Engine:  "InnoDB",
+		Columns: []*tengo.Column{{Name: "id", TypeInDB: "int"}},
+		Partitioning: &tengo.TablePartitioning{
+			Method:     "RANGE",
+			Expression: "`id`",
+			Partitions: []*tengo.Partition{
+				{Name: "p0", Values: "100", Engine: "InnoDB"},
+				{Name: "p1", Values: "200", Engine: "InnoDB"},
+				{Name: "p2", Values: "MAXVALUE", Engine: "InnoDB"},
+			},
+		},
+	}
+	from := &tengo.Schema{Name: "s", Tables: []*tengo.Table{table}}
+	to := &tengo.Schema{Name: "s"}
+	objDiffs := tengo.NewSchemaDiff(from, to).ObjectDiffs()
+	if len(objDiffs) != 3 {
+		t.Fatalf("Expected 3 diffs for dropping partitioned table, instead found %d", len(objDiffs))
+	}
+
+	// Without backup-dir, partitions are dropped by separate ALTERs first
+	dir := getDir(t, "testdata/simple", "--allow-unsafe")
+	mods, err := StatementModifiersForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from StatementModifiersForDir: %v", err)
+	}
+	if stmt, _ := objDiffs[0].Statement(mods); !strings.Contains(stmt, "DROP PARTITION") {
+		t.Errorf("Expected first statement to drop a partition, instead found %q", stmt)
+	}
+
+	// With backup-dir, the only statement should be the DROP TABLE, so that the
+	// entire table's data is backed up
+	dir = getDir(t, "testdata/simple", "--allow-unsafe --backup-dir="+t.TempDir())
+	if mods, err = StatementModifiersForDir(dir); err != nil {
+		t.Fatalf("Unexpected error from StatementModifiersForDir: %v", err)
+	}
+	var stmts []string
+	for _, od := range objDiffs {
+		if stmt, _ := od.Statement(mods); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	if len(stmts) != 1 || stmts[0] != "DROP TABLE `parted`" {
+		t.Errorf("With backup-dir, expected only a DROP TABLE statement, instead found %v", stmts)
+	}
+}
+
+func TestBackupDirRelativePath(t *testing.T) {
+	// A relative backup-dir in an option file is relative to that file's dir,
+	// not the working directory
+	dirPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "backup-dir=backups\n")
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	target := &Target{Instance: inst, Dir: getDir(t, dirPath, ""), SchemaName: "product"}
+	table := &tengo.Table{Name: "foo", Columns: []*tengo.Column{{Name: "id", TypeInDB: "int"}}}
+	diff := tengo.NewSchemaDiff(&tengo.Schema{Name: "product", Tables: []*tengo.Table{table}}, &tengo.Schema{Name: "product"}).ObjectDiffs()[0]
+	backup, err := newDataBackup(diff, target, 1024)
+	if err != nil || backup == nil {
+		t.Fatalf("Unexpected return from newDataBackup: %+v, %v", backup, err)
+	}
+	if expected := filepath.Join(dirPath, "backups"); filepath.Dir(backup.path) != expected {
+		t.Errorf("Expected backup file in %s, instead found path %s", expected, backup.path)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -16,13 +17,23 @@ import (
 // It may represent an external command to shell out to, or a DDL statement to
 // run directly against a DB.
 type DDLStatement struct {
-	stmt     string
-	compound bool
-	shellOut *util.ShellOut
+	stmt      string
+	compound  bool
+	shellOut  *util.ShellOut
+	algorithm tengo.AlterAlgorithm
+	blockers  *blockerPolicy
+	retries   *retryPolicy
 
 	instance      *tengo.Instance
 	schemaName    string
+	tableName     string
+	expectedState string // CREATE TABLE after successful execution, sans auto-inc; only used with retries
 	connectParams string
+	trashSchema   string      // only populated for DROP TABLE with drop-mode=trash
+	backup        *dataBackup // only populated with backup-dir
+
+	annotations []string // only populated with --interactive
+	unsafe      bool     // only populated with --interactive
 }
 
 // NewDDLStatement creates and returns a DDLStatement. If the statement ends up
@@ -42,8 +53,8 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		ddl.schemaName = ""
 	}
 
-	// Get table size, but only if actually needed; apply --safe-below-size if
-	// specified
+	// Get table size, but only if actually needed; apply --safe-below-size and
+	// --safe-below-rows if specified
 	var tableSize int64
 	if needTableSize(diff, target.Dir.Config) {
 		if tableSize, err = getTableSize(target, diff.ObjectKey().Name); err != nil {
@@ -58,6 +69,13 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 			mods.AllowUnsafe = true
 			log.Debugf("Allowing unsafe operations for %s: size=%d < safe-below-size=%d", diff.ObjectKey(), tableSize, safeBelowSize)
 		}
+
+		// Similarly for --safe-below-rows, based on the table's row count
+		if !mods.AllowUnsafe {
+			if mods.AllowUnsafe, err = belowSafeRows(target, diff.ObjectKey(), tableSize); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Options may indicate some/all DDL gets executed by shelling out to another program.
@@ -66,11 +84,51 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		return nil, err
 	}
 
+	// If --safe-narrowing-check option in use, permit unsafe column narrowing if
+	// the existing data in the table is confirmed to fit
+	if td, ok := diff.(*tengo.TableDiff); ok && !mods.AllowUnsafe && target.Dir.Config.GetBool("safe-narrowing-check") {
+		if mods.AllowUnsafe, err = narrowingFits(td, target); err != nil {
+			return nil, err
+		}
+	}
+
+	// Permit dropping columns that were previously deprecated, once the grace
+	// period has elapsed
+	if td, ok := diff.(*tengo.TableDiff); ok && !mods.AllowUnsafe {
+		if mods.AllowUnsafe, err = deprecationGraceElapsed(td, target); err != nil {
+			return nil, err
+		}
+	}
+
+	// With drop-mode=trash, DROP TABLE is replaced by moving the table into the
+	// trash schema. This is not destructive, so --allow-unsafe is not required.
+	if ddl.trashSchema, err = trashSchemaForDir(target.Dir.Config); err != nil {
+		return nil, err
+	} else if ddl.trashSchema == target.SchemaName {
+		return nil, ConfigError(fmt.Sprintf("trash-schema cannot be the same as the schema being pushed (%s)", target.SchemaName))
+	}
+	key := diff.ObjectKey()
+	if key.Type != tengo.ObjectTypeTable || diff.DiffType() != tengo.DiffTypeDrop {
+		ddl.trashSchema = ""
+	}
+
+	// Tables with user-named constraints cannot safely be moved into the shared
+	// trash schema, since constraint names must be unique there
+	if td, ok := diff.(*tengo.TableDiff); ok && ddl.trashSchema != "" {
+		if names := trashConstraintConflicts(td.From, target.Instance.Flavor()); len(names) > 0 {
+			return nil, fmt.Errorf("Preventing drop-mode=trash for table %s: its constraint names (%s) are retained by RENAME TABLE, but must be unique within trash schema %s, which is shared by all schemas on %s. Remove these names from the table's constraint definitions to use server-generated names, or use drop-mode=drop for this operation.",
+				tengo.EscapeIdentifier(key.Name), strings.Join(names, ", "), ddl.trashSchema, target.Instance)
+		}
+	}
+
 	// Get the raw DDL statement as a string, handling errors and noops correctly
-	if ddl.stmt, err = diff.Statement(mods); tengo.IsForbiddenDiff(err) {
+	if ddl.trashSchema != "" {
+		trashName := uniqueTrashTableName(target.Instance, ddl.trashSchema, target.SchemaName, key.Name, time.Now())
+		ddl.stmt = trashRenameStatement(target.SchemaName, key.Name, ddl.trashSchema, trashName)
+	} else if ddl.stmt, err = diff.Statement(mods); tengo.IsForbiddenDiff(err) {
 		terminalWidth, _ := util.TerminalWidth(int(os.Stderr.Fd()))
 		commentedOutStmt := "  # " + util.WrapStringWithPadding(ddl.stmt, terminalWidth-29, "  # ")
-		return nil, fmt.Errorf("Preventing execution of unsafe or potentially destructive statement:\n%s\nUse --allow-unsafe or --safe-below-size to permit this operation. For more information, see Safety Options section of --help.", commentedOutStmt)
+		return nil, fmt.Errorf("Preventing execution of unsafe or potentially destructive statement:\n%s\nUse --allow-unsafe, --safe-below-size, or --safe-below-rows to permit this operation. For more information, see Safety Options section of --help.", commentedOutStmt)
 	} else if err != nil {
 		// Leave the error untouched/unwrapped to allow caller to handle appropriately
 		return nil, err
@@ -79,6 +137,39 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		return nil, nil
 	}
 
+	// If --backup-dir option in use, export data that the statement would destroy
+	// prior to execution. Drops with drop-mode=trash don't destroy any data.
+	if ddl.trashSchema == "" {
+		if ddl.backup, err = newDataBackup(diff, target, tableSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// In interactive mode, describe the statement's safety and table size, so
+	// that the user can make an informed decision before confirming execution.
+	// Unsafe statements are still flagged even if --allow-unsafe or
+	// --safe-below-size permitted them.
+	if target.Dir.Config.GetBool("interactive") && !target.Dir.Config.GetBool("dry-run") {
+		strictMods := mods
+		strictMods.AllowUnsafe = false
+		if ddl.trashSchema != "" {
+			ddl.annotations = append(ddl.annotations, "table will be moved to trash schema "+ddl.trashSchema)
+		} else if _, err := diff.Statement(strictMods); tengo.IsForbiddenDiff(err) {
+			ddl.unsafe = true
+			ddl.annotations = append(ddl.annotations, "WARNING: unsafe or potentially destructive statement")
+		}
+		if ddl.backup != nil {
+			ddl.annotations = append(ddl.annotations, "affected data will first be backed up to "+ddl.backup.path)
+		}
+		if diff.ObjectKey().Type == tengo.ObjectTypeTable && diff.DiffType() != tengo.DiffTypeCreate {
+			if tableSize == 0 {
+				ddl.annotations = append(ddl.annotations, "table size: 0 bytes (no rows)")
+			} else {
+				ddl.annotations = append(ddl.annotations, fmt.Sprintf("table size: %d bytes", tableSize))
+			}
+		}
+	}
+
 	// Determine if the statement is a compound statement, requiring special
 	// delimiter handling in output. Only stored program diffs (e.g. procs, funcs)
 	// implement this interface; others never generate compound statements.
@@ -88,35 +179,52 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 
 	if wrapper == "" {
 		ddl.connectParams = getConnectParams(diff, target.Dir.Config)
-	} else {
-		var socket, port, connOpts string
-		if ddl.instance.SocketPath != "" {
-			socket = ddl.instance.SocketPath
-		} else {
-			port = strconv.Itoa(ddl.instance.Port)
+
+		// For ALTER TABLE run directly by Skeema, predict which algorithm the server
+		// will use, and block table copies of large tables if configured to do so
+		if td, ok := diff.(*tengo.TableDiff); ok && td.Type == tengo.DiffTypeAlter {
+			ddl.algorithm = td.PredictAlgorithm(mods)
+			if maxCopySize, err := target.Dir.Config.GetBytes("alter-copy-max-size"); err != nil {
+				return nil, ConfigError(err.Error())
+			} else if maxCopySize > 0 && ddl.algorithm == tengo.AlterAlgorithmCopy && tableSize >= int64(maxCopySize) {
+				return nil, fmt.Errorf("Preventing execution of ALTER TABLE on %s: this change is expected to require ALGORITHM=COPY, and the table size %d >= alter-copy-max-size=%d.\nUse --alter-wrapper with an external online schema change tool, or increase alter-copy-max-size to permit this operation.", diff.ObjectKey(), tableSize, maxCopySize)
+			}
 		}
-		if connOpts, err = util.RealConnectOptions(target.Dir.Config.Get("connect-options")); err != nil {
-			return nil, ConfigError(err.Error())
+
+		if key := diff.ObjectKey(); key.Type == tengo.ObjectTypeTable {
+			ddl.tableName = key.Name
+
+			// For ALTER TABLE or DROP TABLE, optionally check for long-running
+			// transactions which could block the statement's metadata lock
+			if diff.DiffType() != tengo.DiffTypeCreate {
+				if ddl.blockers, err = blockerPolicyForDir(target.Dir.Config); err != nil {
+					return nil, err
+				}
+			}
+
+			// Optionally retry upon transient errors, which requires knowing what the
+			// table should look like after success
+			if ddl.retries, err = retryPolicyForDir(target.Dir.Config); err != nil {
+				return nil, err
+			} else if ddl.retries != nil {
+				if td, ok := diff.(*tengo.TableDiff); ok && td.To != nil {
+					ddl.expectedState, _ = tengo.ParseCreateAutoInc(td.To.CreateStatement)
+				}
+			}
 		}
-		variables := map[string]string{
-			"HOST":        ddl.instance.Host,
-			"PORT":        port,
-			"SOCKET":      socket,
-			"SCHEMA":      ddl.schemaName,
-			"USER":        target.Dir.Config.GetAllowEnvVar("user"),
-			"PASSWORD":    target.Dir.Config.GetAllowEnvVar("password"),
-			"ENVIRONMENT": target.Dir.Config.Get("environment"),
-			"DDL":         ddl.stmt,
-			"CLAUSES":     "", // filled in below only for tables
-			"NAME":        diff.ObjectKey().Name,
-			"TABLE":       "", // filled in below only for tables
-			"SIZE":        strconv.FormatInt(tableSize, 10),
-			"TYPE":        diff.DiffType().String(),
-			"CLASS":       diff.ObjectKey().Type.Caps(),
-			"CONNOPTS":    connOpts,
-			"DIRNAME":     target.Dir.BaseName(),
-			"DIRPATH":     target.Dir.Path,
+	} else {
+		variables, err := target.shellOutVariables()
+		if err != nil {
+			return nil, err
 		}
+		variables["SCHEMA"] = ddl.schemaName
+		variables["DDL"] = ddl.stmt
+		variables["CLAUSES"] = "" // filled in below only for tables
+		variables["NAME"] = diff.ObjectKey().Name
+		variables["TABLE"] = "" // filled in below only for tables
+		variables["SIZE"] = strconv.FormatInt(tableSize, 10)
+		variables["TYPE"] = diff.DiffType().String()
+		variables["CLASS"] = diff.ObjectKey().Type.Caps()
 		if diff.ObjectKey().Type == tengo.ObjectTypeTable {
 			td := diff.(*tengo.TableDiff)
 			variables["CLAUSES"], _ = td.Clauses(mods)
@@ -142,13 +250,24 @@ func needTableSize(diff tengo.ObjectDiff, config *mybase.Config) bool {
 		return false
 	}
 
-	// If safe-below-size or alter-wrapper-min-size options in use, size is needed
-	for _, opt := range []string{"safe-below-size", "alter-wrapper-min-size"} {
+	// If safe-below-size, safe-below-rows, alter-wrapper-min-size, or backup-dir
+	// options in use, size is needed
+	for _, opt := range []string{"safe-below-size", "safe-below-rows", "alter-wrapper-min-size", "backup-dir"} {
 		if config.Changed(opt) {
 			return true
 		}
 	}
 
+	// Interactive mode displays the table size before confirmation
+	if config.GetBool("interactive") && !config.GetBool("dry-run") {
+		return true
+	}
+
+	// alter-copy-max-size only affects ALTER TABLE
+	if diff.DiffType() == tengo.DiffTypeAlter && config.Changed("alter-copy-max-size") {
+		return true
+	}
+
 	// If any wrapper option uses the {SIZE} variable placeholder, size is needed
 	for _, opt := range []string{"alter-wrapper", "ddl-wrapper"} {
 		if strings.Contains(strings.ToUpper(config.Get(opt)), "{SIZE}") {
@@ -170,6 +289,72 @@ func getTableSize(target *Target, tableName string) (int64, error) {
 	return target.Instance.TableSize(target.SchemaName, tableName)
 }
 
+// rowCountTimeout is the maximum time to spend on an exact row count for
+// safe-below-rows, before falling back to an estimate.
+const rowCountTimeout = 5 * time.Second
+
+// belowSafeRows returns true if the safe-below-rows option is in use, and the
+// table has fewer rows than its value. tableSize should be a value previously
+// returned by getTableSize, which is 0 for tables without any rows.
+func belowSafeRows(target *Target, key tengo.ObjectKey, tableSize int64) (bool, error) {
+	safeBelowRows, err := target.Dir.Config.GetInt("safe-below-rows")
+	if err != nil {
+		return false, ConfigError(err.Error())
+	} else if safeBelowRows <= 0 {
+		return false, nil
+	}
+	var rows int64
+	exact := true
+	if tableSize > 0 {
+		if rows, exact, err = target.Instance.TableRowCount(target.SchemaName, key.Name, rowCountTimeout); err != nil {
+			return false, err
+		}
+	}
+	method := "exact count"
+	if !exact {
+		method = "estimate"
+	}
+	if rows < int64(safeBelowRows) {
+		log.Debugf("Allowing unsafe operations for %s: rows=%d (%s) < safe-below-rows=%d", key, rows, method, safeBelowRows)
+		return true, nil
+	}
+	return false, nil
+}
+
+// narrowingFits returns true if every unsafe clause in td narrows a column,
+// and the existing data in the table has been confirmed to fit within each
+// narrowed column. The evidence is logged either way. Tables with more rows
+// than safe-narrowing-max-rows are not checked.
+func narrowingFits(td *tengo.TableDiff, target *Target) (bool, error) {
+	checks, ok := td.NarrowingChecks()
+	if !ok {
+		return false, nil
+	}
+	maxRows, err := target.Dir.Config.GetInt("safe-narrowing-max-rows")
+	if err != nil {
+		return false, ConfigError(err.Error())
+	}
+	key := td.ObjectKey()
+	if exceeds, err := target.Instance.TableRowsExceed(target.SchemaName, key.Name, int64(maxRows)); err != nil {
+		return false, err
+	} else if exceeds {
+		log.Warnf("Skipping safe-narrowing-check for %s: table has more than safe-narrowing-max-rows=%d rows", key, maxRows)
+		return false, nil
+	}
+	for _, check := range checks {
+		fits, evidence, err := target.Instance.CheckNarrowing(target.SchemaName, check)
+		if err != nil {
+			return false, err
+		} else if !fits {
+			log.Warnf("safe-narrowing-check for %s on %s %s: existing data does not fit: %s", key, target.Instance, target.SchemaName, evidence)
+			return false, nil
+		}
+		log.Infof("safe-narrowing-check for %s on %s %s: %s", key, target.Instance, target.SchemaName, evidence)
+	}
+	log.Infof("Allowing unsafe operations for %s on %s %s: existing data fits narrowed column definitions", key, target.Instance, target.SchemaName)
+	return true, nil
+}
+
 // getWrapper returns the command-line for executing diff as a shell-out, if
 // configured to do so. Any variable placeholders in the returned string have
 // NOT been interpolated yet.
@@ -227,17 +412,50 @@ func getConnectParams(diff tengo.ObjectDiff, config *mybase.Config) string {
 // Execute runs the DDL statement, either by running a SQL query against a DB,
 // or shelling out to an external program, as appropriate.
 func (ddl *DDLStatement) Execute() error {
+	if ddl.backup != nil {
+		if err := ddl.backup.Run(); err != nil {
+			return err
+		}
+	}
+	if ddl.trashSchema != "" {
+		if err := ddl.createTrashSchema(); err != nil {
+			return err
+		}
+	}
 	if ddl.shellOut != nil {
 		return ddl.shellOut.Run()
 	}
+	if ddl.blockers != nil {
+		if err := ddl.checkBlockers(); err != nil {
+			return err
+		}
+	}
 	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, ddl.connectParams)
 	if err != nil {
 		return err
 	}
+	if ddl.retries != nil {
+		exec := func() error {
+			_, err := db.Exec(ddl.stmt)
+			return err
+		}
+		return ddl.execWithRetries(exec, ddl.tableState)
+	}
 	_, err = db.Exec(ddl.stmt)
 	return err
 }
 
+// createTrashSchema creates the trash schema used by drop-mode=trash, if it
+// does not already exist.
+func (ddl *DDLStatement) createTrashSchema() error {
+	db, err := ddl.instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("CREATE DATABASE IF NOT EXISTS " + tengo.EscapeIdentifier(ddl.trashSchema))
+	return err
+}
+
 // Statement returns a string representation of ddl. If an external command is
 // in use, the returned string will be prefixed with "\!", the MySQL CLI command
 // shortcut for "system" shellout.
@@ -248,6 +466,21 @@ func (ddl *DDLStatement) Statement() string {
 	return ddl.stmt
 }
 
+// PredictedAlgorithm returns the ALTER TABLE algorithm that the database server
+// is expected to use for ddl. tengo.AlterAlgorithmUnknown is returned for
+// statements other than ALTER TABLE, as well as for any statement that is
+// executed using an external command.
+func (ddl *DDLStatement) PredictedAlgorithm() tengo.AlterAlgorithm {
+	return ddl.algorithm
+}
+
+// Annotations returns informational notes to display alongside ddl, and
+// whether the statement should be highlighted as unsafe. Annotations are only
+// generated in interactive mode.
+func (ddl *DDLStatement) Annotations() (notes []string, warn bool) {
+	return ddl.annotations, ddl.unsafe
+}
+
 // ClientState returns a representation of the client state which would be
 // used in execution of the statement.
 func (ddl *DDLStatement) ClientState() ClientState {
@@ -52,18 +52,27 @@ func (s ApplierIntegrationSuite) TestNewDDLStatement(t *testing.T) {
 	// Hackily set up test args manually
 	flavor := s.d[0].Flavor()
 	configMap := map[string]string{
-		"user":                   "root",
-		"password":               s.d[0].Instance.Password,
-		"debug":                  "1",
-		"allow-unsafe":           "1",
-		"ddl-wrapper":            "/bin/echo ddl-wrapper {SCHEMA}.{NAME} {TYPE} {CLASS}",
-		"alter-wrapper":          "/bin/echo alter-wrapper {SCHEMA}.{TABLE} {TYPE} {CLAUSES}",
-		"alter-wrapper-min-size": "1",
-		"alter-algorithm":        "inplace",
-		"alter-lock":             "none",
-		"safe-below-size":        "0",
-		"connect-options":        "",
-		"environment":            "production",
+		"user":                     "root",
+		"password":                 s.d[0].Instance.Password,
+		"debug":                    "1",
+		"allow-unsafe":             "1",
+		"ddl-wrapper":              "/bin/echo ddl-wrapper {SCHEMA}.{NAME} {TYPE} {CLASS}",
+		"alter-wrapper":            "/bin/echo alter-wrapper {SCHEMA}.{TABLE} {TYPE} {CLAUSES}",
+		"alter-wrapper-min-size":   "1",
+		"alter-algorithm":          "inplace",
+		"alter-lock":               "none",
+		"safe-below-size":          "0",
+		"safe-below-rows":          "0",
+		"safe-narrowing-check":     "0",
+		"interactive":              "0",
+		"drop-mode":                "drop",
+		"trash-schema":             "_skeema_trash",
+		"backup-dir":               "",
+		"deprecation-grace-period": "7d",
+		"alter-copy-max-size":      "0",
+		"ddl-max-attempts":         "1",
+		"connect-options":          "",
+		"environment":              "production",
 	}
 	if flavor.Matches(tengo.FlavorMySQL55) {
 		delete(configMap, "alter-algorithm")
@@ -171,3 +180,37 @@ func objectDiffExpected(t *testing.T, diff tengo.ObjectDiff, ddl *DDLStatement,
 	}
 	return
 }
+
+func (s ApplierIntegrationSuite) TestBelowSafeRows(t *testing.T) {
+	if _, err := s.d[0].SourceSQL(filepath.Join("testdata", "setup.sql")); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	db, err := s.d[0].CachedConnectionPool("product", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (name) VALUES ('a'), ('b'), ('c')"); err != nil {
+		t.Fatalf("Unexpected error inserting rows: %s", err)
+	}
+
+	assertBelowSafeRows := func(safeBelowRows int, tableName string, expected bool) {
+		t.Helper()
+		dir := getDir(t, "testdata/simple", fmt.Sprintf("--safe-below-rows=%d", safeBelowRows))
+		target := &Target{Instance: s.d[0].Instance, Dir: dir, SchemaName: "product"}
+		tableSize, err := getTableSize(target, tableName)
+		if err != nil {
+			t.Fatalf("Unexpected error from getTableSize: %v", err)
+		}
+		key := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: tableName}
+		if actual, err := belowSafeRows(target, key, tableSize); err != nil {
+			t.Errorf("Unexpected error from belowSafeRows: %v", err)
+		} else if actual != expected {
+			t.Errorf("With safe-below-rows=%d, expected belowSafeRows for %s to return %t, instead found %t", safeBelowRows, tableName, expected, actual)
+		}
+	}
+	assertBelowSafeRows(0, "users", false)
+	assertBelowSafeRows(0, "posts", false)
+	assertBelowSafeRows(3, "users", false)
+	assertBelowSafeRows(4, "users", true)
+	assertBelowSafeRows(1, "posts", true)
+}
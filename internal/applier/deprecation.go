@@ -0,0 +1,169 @@
+package applier
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// Columns are deprecated in two phases. First, a column definition in a
+// CREATE TABLE file is annotated with an inline "-- skeema:deprecated"
+// comment. Push then makes the column invisible, and records the time of
+// deprecation by appending a marker to the column's comment. Later, once the
+// column is removed from the CREATE TABLE file, push permits the DROP COLUMN
+// without --allow-unsafe, but only after deprecation-grace-period has elapsed.
+// Commands which rewrite CREATE TABLE files (pull, format, lint) retain the
+// inline annotations, and never write the marker to the filesystem; see
+// tengo.AnnotateDeprecatedColumns.
+
+// deprecatedTimestampFormat is the format of the time in a deprecation marker.
+const deprecatedTimestampFormat = "20060102150405"
+
+// deprecatedColumnNames returns the names of columns annotated as deprecated
+// in the supplied CREATE TABLE statement text, in sorted order.
+func deprecatedColumnNames(createText string) []string {
+	annotations := tengo.DeprecatedColumnAnnotations(createText)
+	names := make([]string, 0, len(annotations))
+	for name := range annotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// columnDeprecatedAt returns the time that col was deprecated, based on the
+// marker in its comment. The returned bool is false if col has no marker.
+func columnDeprecatedAt(col *tengo.Column) (time.Time, bool) {
+	_, marker := tengo.ParseDeprecationMarker(col.Comment)
+	if marker == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(deprecatedTimestampFormat, strings.TrimPrefix(marker, tengo.DeprecationMarkerPrefix), time.UTC)
+	return t, err == nil
+}
+
+// invisibleColumnsSupported returns true if flavor supports invisible columns.
+func invisibleColumnsSupported(flavor tengo.Flavor) bool {
+	return flavor.Min(tengo.FlavorMySQL80.Dot(23)) || flavor.Min(tengo.FlavorMariaDB103)
+}
+
+// applyDeprecations adjusts the desired schema to reflect any columns that
+// are annotated as deprecated in t's CREATE TABLE files, using the live schema
+// to determine whether each column was already deprecated previously. Tables
+// are modified by replacement, since the desired schema's tables may be shared
+// with other targets.
+func applyDeprecations(desired, live *tengo.Schema, t *Target, flavor tengo.Flavor) {
+	if t.DesiredSchema == nil || t.DesiredSchema.LogicalSchema == nil {
+		return
+	}
+	liveTables := live.TablesByName()
+	tables := make([]*tengo.Table, len(desired.Tables))
+	copy(tables, desired.Tables)
+	now := time.Now()
+	for n, table := range tables {
+		stmt := t.DesiredSchema.LogicalSchema.Creates[table.ObjectKey()]
+		liveTable := liveTables[table.Name]
+		if stmt == nil || liveTable == nil {
+			continue
+		}
+		if names := deprecatedColumnNames(stmt.Text); len(names) > 0 {
+			tables[n] = deprecateColumns(table, liveTable, names, flavor, now)
+		}
+	}
+	desired.Tables = tables
+}
+
+// deprecateColumns returns a copy of table, in which the named columns are
+// invisible (if supported by flavor) and have a deprecation marker in their
+// comment. If the corresponding column in liveTable already has a marker, it
+// is retained; otherwise, a new marker is created using now. Columns which do
+// not exist in liveTable are not modified. If no changes are necessary, table
+// is returned as-is.
+func deprecateColumns(table, liveTable *tengo.Table, names []string, flavor tengo.Flavor, now time.Time) *tengo.Table {
+	liveCols := liveTable.ColumnsByName()
+	var result *tengo.Table
+	for _, name := range names {
+		pos := -1
+		for n, col := range table.Columns {
+			if col.Name == name {
+				pos = n
+				break
+			}
+		}
+		liveCol := liveCols[name]
+		if pos < 0 || liveCol == nil {
+			continue
+		}
+		col := *table.Columns[pos]
+		_, marker := tengo.ParseDeprecationMarker(liveCol.Comment)
+		if marker == "" {
+			marker = tengo.DeprecationMarkerPrefix + now.UTC().Format(deprecatedTimestampFormat)
+		}
+		if comment, _ := tengo.ParseDeprecationMarker(col.Comment); comment == "" {
+			col.Comment = marker
+		} else {
+			col.Comment = comment + " " + marker
+		}
+		col.Invisible = col.Invisible || invisibleColumnsSupported(flavor)
+		if col.Equals(table.Columns[pos]) {
+			continue
+		}
+
+		if result == nil {
+			tableCopy := *table
+			tableCopy.Columns = make([]*tengo.Column, len(table.Columns))
+			copy(tableCopy.Columns, table.Columns)
+			result = &tableCopy
+		}
+		oldDef := "\n  " + result.Columns[pos].Definition(flavor, table)
+		newDef := "\n  " + col.Definition(flavor, table)
+		result.Columns[pos] = &col
+		if strings.Contains(result.CreateStatement, oldDef) {
+			result.CreateStatement = strings.Replace(result.CreateStatement, oldDef, newDef, 1)
+		} else {
+			result.CreateStatement = result.GeneratedCreateStatement(flavor)
+		}
+		log.Debugf("Deprecating column %s of %s: %s", tengo.EscapeIdentifier(name), table.ObjectKey(), marker)
+	}
+	if result == nil {
+		return table
+	}
+	return result
+}
+
+// deprecationGraceElapsed returns true if every unsafe clause in td drops a
+// column that was deprecated at least deprecation-grace-period ago. If the
+// flavor supports invisible columns, each dropped column must also be
+// invisible.
+func deprecationGraceElapsed(td *tengo.TableDiff, target *Target) (bool, error) {
+	clauses := td.UnsafeClauses()
+	if len(clauses) == 0 {
+		return false, nil
+	}
+	grace, err := ParseAge(target.Dir.Config.Get("deprecation-grace-period"))
+	if err != nil {
+		return false, ConfigError("Invalid value for option deprecation-grace-period: " + err.Error())
+	}
+	requireInvisible := invisibleColumnsSupported(target.Instance.Flavor())
+	key := td.ObjectKey()
+	for _, clause := range clauses {
+		dc, ok := clause.(tengo.DropColumn)
+		if !ok {
+			return false, nil
+		}
+		deprecatedAt, ok := columnDeprecatedAt(dc.Column)
+		if !ok || (requireInvisible && !dc.Column.Invisible) {
+			return false, nil
+		}
+		if permittedAt := deprecatedAt.Add(grace); time.Now().Before(permittedAt) {
+			log.Warnf("Column %s of %s was deprecated at %s. Dropping it will be permitted without --allow-unsafe after %s, once deprecation-grace-period=%s has elapsed.",
+				tengo.EscapeIdentifier(dc.Column.Name), key, deprecatedAt.Format(time.RFC3339), permittedAt.Format(time.RFC3339), target.Dir.Config.Get("deprecation-grace-period"))
+			return false, nil
+		}
+	}
+	log.Infof("Allowing unsafe operations for %s on %s %s: dropped columns were deprecated longer ago than deprecation-grace-period", key, target.Instance, target.SchemaName)
+	return true, nil
+}
@@ -0,0 +1,192 @@
+package applier
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/dumper"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestDeprecatedColumnNames(t *testing.T) {
+	createText := "CREATE TABLE foo (\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  `old_name` varchar(30) DEFAULT NULL, -- skeema:deprecated\n" +
+		"  `weird``col` int, --skeema:deprecated since last week\n" +
+		"  other int -- some other comment\n" +
+		"  , legacy int -- skeema:deprecated\n" +
+		"  notdeprecated int -- skeema:deprecatedness\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n"
+	expected := "old_name,weird`col"
+	if actual := strings.Join(deprecatedColumnNames(createText), ","); actual != expected {
+		t.Errorf("Expected deprecatedColumnNames to return %q, instead found %q", expected, actual)
+	}
+}
+
+// deprecationTestTable returns a table with columns id and name, where name
+// has the supplied comment and visibility.
+func deprecationTestTable(flavor tengo.Flavor, comment string, invisible bool) *tengo.Table {
+	table := &tengo.Table{
+		Name:      "users",
+		Engine:    "InnoDB",
+		CharSet:   "latin1",
+		Collation: "latin1_swedish_ci",
+		Columns: []*tengo.Column{
+			{Name: "id", TypeInDB: "int unsigned"},
+			{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", Comment: comment, Invisible: invisible},
+		},
+	}
+	table.PrimaryKey = &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+	table.CreateStatement = table.GeneratedCreateStatement(flavor)
+	return table
+}
+
+func TestDeprecateColumns(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.30")
+	now := time.Date(2026, 10, 16, 13, 4, 5, 0, time.UTC)
+	desired := deprecationTestTable(flavor, "the name", false)
+	live := deprecationTestTable(flavor, "the name", false)
+
+	// Newly deprecated column becomes invisible, with marker using current time
+	result := deprecateColumns(desired, live, []string{"name", "nonexistent"}, flavor, now)
+	expected := deprecationTestTable(flavor, "the name skeema:deprecated@20261016130405", true)
+	if result == desired || desired.Columns[1].Invisible {
+		t.Error("Expected deprecateColumns to return a modified copy, without modifying the original")
+	}
+	if result.CreateStatement != expected.CreateStatement || !result.Columns[1].Equals(expected.Columns[1]) {
+		t.Errorf("Unexpected result from deprecateColumns:\n%s\nexpected:\n%s", result.CreateStatement, expected.CreateStatement)
+	}
+
+	// Previously deprecated column retains its existing marker, so no change
+	live = expected
+	later := now.Add(48 * time.Hour)
+	if result := deprecateColumns(expected, live, []string{"name"}, flavor, later); result != expected {
+		t.Errorf("Expected deprecateColumns to return table as-is, instead found:\n%s", result.CreateStatement)
+	}
+	result = deprecateColumns(desired, live, []string{"name"}, flavor, later)
+	if result.CreateStatement != expected.CreateStatement {
+		t.Errorf("Unexpected result from deprecateColumns:\n%s\nexpected:\n%s", result.CreateStatement, expected.CreateStatement)
+	}
+
+	// Flavors without invisible columns just get the marker
+	oldFlavor := tengo.ParseFlavor("mysql:5.7")
+	desired = deprecationTestTable(oldFlavor, "", false)
+	result = deprecateColumns(desired, desired, []string{"name"}, oldFlavor, now)
+	if col := result.Columns[1]; col.Invisible || col.Comment != "skeema:deprecated@20261016130405" {
+		t.Errorf("Unexpected column after deprecateColumns: %+v", col)
+	}
+}
+
+// TestDeprecationRoundTrip confirms that deprecation state survives commands
+// which rewrite CREATE TABLE files: lint or format followed by push, and then
+// push followed by pull.
+func TestDeprecationRoundTrip(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.30")
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "users.sql")
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=product\n")
+	fs.WriteTestFile(t, filePath, "CREATE TABLE users (\n  id int unsigned NOT NULL,\n  name varchar(30), -- skeema:deprecated\n  PRIMARY KEY (id)\n);\n")
+	dumpTable := func(table *tengo.Table) string {
+		t.Helper()
+		schema := &tengo.Schema{Name: "product", Tables: []*tengo.Table{table}}
+		if _, err := dumper.DumpSchema(schema, getDir(t, dirPath, ""), dumper.Options{}); err != nil {
+			t.Fatalf("Unexpected error from DumpSchema: %v", err)
+		}
+		return fs.ReadTestFile(t, filePath)
+	}
+	pushTable := func(desired, live *tengo.Table) *tengo.Table {
+		t.Helper()
+		dir := getDir(t, dirPath, "")
+		target := &Target{Dir: dir, SchemaName: "product", DesiredSchema: &workspace.Schema{LogicalSchema: dir.LogicalSchemas[0]}}
+		desiredSchema := &tengo.Schema{Name: "product", Tables: []*tengo.Table{desired}}
+		applyDeprecations(desiredSchema, &tengo.Schema{Name: "product", Tables: []*tengo.Table{live}}, target, flavor)
+		return desiredSchema.Tables[0]
+	}
+
+	// Reformatting via lint or format uses a workspace table, which has no
+	// knowledge of inline comments; the annotation must be retained
+	expectLine := "  `name` varchar(30) DEFAULT NULL, -- skeema:deprecated\n"
+	if contents := dumpTable(deprecationTestTable(flavor, "", false)); !strings.Contains(contents, expectLine) {
+		t.Fatalf("Expected reformatted file to contain line %q, instead found:\n%s", expectLine, contents)
+	}
+
+	// Subsequent push deprecates the column
+	live := deprecationTestTable(flavor, "", false)
+	pushed := pushTable(deprecationTestTable(flavor, "", false), live)
+	if col := pushed.Columns[1]; !col.Invisible || !strings.HasPrefix(col.Comment, tengo.DeprecationMarkerPrefix) {
+		t.Fatalf("Expected column to be deprecated after push, instead found %+v", col)
+	}
+
+	// Pull must not write the marker to the file, but retains the annotation
+	live = pushed
+	expectLine = "  `name` varchar(30) DEFAULT NULL /*!80023 INVISIBLE */, -- skeema:deprecated\n"
+	if contents := dumpTable(live); !strings.Contains(contents, expectLine) || strings.Contains(contents, tengo.DeprecationMarkerPrefix) {
+		t.Fatalf("Expected pulled file to contain line %q and no marker, instead found:\n%s", expectLine, contents)
+	}
+
+	// Push after pull has no differences, since the marker from the live table is
+	// retained
+	pushed = pushTable(deprecationTestTable(flavor, "", true), live)
+	if diff := tengo.NewAlterTable(live, pushed); diff != nil {
+		t.Errorf("Expected no differences after pull, instead found %+v", diff)
+	}
+}
+
+func TestDeprecationGraceElapsed(t *testing.T) {
+	flavor := tengo.ParseFlavor("mysql:8.0.30")
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	inst.ForceFlavor(flavor)
+	marker := func(age time.Duration) string {
+		return "skeema:deprecated@" + time.Now().Add(-age).UTC().Format(deprecatedTimestampFormat)
+	}
+	dropped := deprecationTestTable(flavor, "", false)
+	dropped.Columns = dropped.Columns[0:1]
+	dropped.CreateStatement = dropped.GeneratedCreateStatement(flavor)
+
+	cases := []struct {
+		flags     string
+		comment   string
+		invisible bool
+		expected  bool
+	}{
+		{"", marker(8 * 24 * time.Hour), true, true},
+		{"", marker(6 * 24 * time.Hour), true, false},
+		{"--deprecation-grace-period=5d", marker(6 * 24 * time.Hour), true, true},
+		{"--deprecation-grace-period=1h", marker(2 * time.Hour), true, true},
+		{"", marker(8 * 24 * time.Hour), false, false},
+		{"", "", true, false},
+	}
+	for _, c := range cases {
+		target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", c.flags), SchemaName: "product"}
+		td := tengo.NewAlterTable(deprecationTestTable(flavor, c.comment, c.invisible), dropped)
+		if actual, err := deprecationGraceElapsed(td, target); err != nil {
+			t.Errorf("Unexpected error from deprecationGraceElapsed: %v", err)
+		} else if actual != c.expected {
+			t.Errorf("With flags %q, comment %q, invisible=%t: expected %t, found %t", c.flags, c.comment, c.invisible, c.expected, actual)
+		}
+	}
+
+	// Other unsafe clauses are not permitted
+	target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", ""), SchemaName: "product"}
+	modified := deprecationTestTable(flavor, "", false)
+	modified.Columns[0] = &tengo.Column{Name: "id", TypeInDB: "smallint unsigned"}
+	modified.CreateStatement = modified.GeneratedCreateStatement(flavor)
+	td := tengo.NewAlterTable(deprecationTestTable(flavor, marker(30*24*time.Hour), true), modified)
+	if actual, err := deprecationGraceElapsed(td, target); actual || err != nil {
+		t.Errorf("Unexpected result from deprecationGraceElapsed: %t, %v", actual, err)
+	}
+
+	target.Dir = getDir(t, "testdata/simple", "--deprecation-grace-period=soon")
+	td = tengo.NewAlterTable(deprecationTestTable(flavor, marker(30*24*time.Hour), true), dropped)
+	if _, err := deprecationGraceElapsed(td, target); err == nil {
+		t.Error("Expected error from invalid deprecation-grace-period, but err was nil")
+	}
+}
@@ -0,0 +1,105 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// pushHooks manages execution of the pre-push-hook and post-push-hook options
+// for a single target. These external commands run once per target, rather
+// than once per statement like alter-wrapper or ddl-wrapper, which makes them
+// useful for pausing jobs, taking snapshots, or sending notifications.
+type pushHooks struct {
+	target    *Target
+	stmtCount int
+	ddlFile   string // path to temp file containing the planned DDL
+}
+
+// newPushHooks returns a pushHooks for the target, or nil if no hooks are
+// configured or if this is a dry run. If non-nil, the caller should defer a
+// call to its cleanup method.
+func newPushHooks(t *Target, stmts []PlannedStatement) (*pushHooks, error) {
+	if t.Dir.Config.GetBool("dry-run") || len(stmts) == 0 {
+		return nil, nil
+	} else if t.Dir.Config.Get("pre-push-hook") == "" && t.Dir.Config.Get("post-push-hook") == "" {
+		return nil, nil
+	}
+	f, err := os.CreateTemp("", "skeema-push-*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create temp file for push hooks: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(plannedDDL(stmts)); err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("Unable to write temp file for push hooks: %w", err)
+	}
+	return &pushHooks{
+		target:    t,
+		stmtCount: len(stmts),
+		ddlFile:   f.Name(),
+	}, nil
+}
+
+// run executes the command in the supplied option name, if one is configured.
+// skipCount should be the number of statements that were skipped due to
+// errors, or 0 when running pre-push-hook. A non-nil error is returned if the
+// command cannot be interpolated, or if it exits non-zero.
+func (hooks *pushHooks) run(optionName string, skipCount int) error {
+	command := hooks.target.Dir.Config.Get(optionName)
+	if command == "" {
+		return nil
+	}
+	variables, err := hooks.target.shellOutVariables()
+	if err != nil {
+		return err
+	}
+	variables["STATEMENTS"] = strconv.Itoa(hooks.stmtCount)
+	variables["SKIPPED"] = strconv.Itoa(skipCount)
+	variables["DDLFILE"] = hooks.ddlFile
+	s, err := util.NewInterpolatedShellOut(command, variables)
+	if err != nil {
+		return ConfigError(fmt.Sprintf("Invalid %s for %s: %s", optionName, hooks.target.Dir, err))
+	}
+	s.Dir = hooks.target.Dir.Path
+	log.Infof("Running %s for %s %s: %s", optionName, hooks.target.Instance, hooks.target.SchemaName, s)
+	return s.Run()
+}
+
+// cleanup removes the temp file containing the planned DDL.
+func (hooks *pushHooks) cleanup() {
+	os.Remove(hooks.ddlFile)
+}
+
+// plannedDDL returns the supplied statements as a single string, in the same
+// format used by the standard printer, suitable for piping to a client.
+func plannedDDL(stmts []PlannedStatement) string {
+	var b strings.Builder
+	var lastSchema string
+	lastDelimiter := ";"
+	for _, stmt := range stmts {
+		cs := stmt.ClientState()
+		if cs.SchemaName != lastSchema && cs.SchemaName != "" {
+			if lastDelimiter != ";" {
+				b.WriteString("DELIMITER ;\n")
+				lastDelimiter = ";"
+			}
+			fmt.Fprintf(&b, "USE %s;\n", tengo.EscapeIdentifier(cs.SchemaName))
+			lastSchema = cs.SchemaName
+		}
+		if cs.Delimiter != lastDelimiter && cs.Delimiter != "" {
+			fmt.Fprintf(&b, "DELIMITER %s\n", cs.Delimiter)
+			lastDelimiter = cs.Delimiter
+		}
+		b.WriteString(stmt.Statement() + cs.Delimiter + "\n")
+	}
+	if lastDelimiter != ";" {
+		b.WriteString("DELIMITER ;\n")
+	}
+	return b.String()
+}
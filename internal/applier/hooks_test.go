@@ -0,0 +1,69 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+type fakeStatement struct {
+	stmt string
+	cs   ClientState
+}
+
+func (fs fakeStatement) Execute() error           { return nil }
+func (fs fakeStatement) Statement() string        { return fs.stmt }
+func (fs fakeStatement) ClientState() ClientState { return fs.cs }
+
+func TestPushHooks(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	stmts := []PlannedStatement{
+		fakeStatement{stmt: "CREATE TABLE foo (id int)", cs: ClientState{InstanceName: inst.String(), SchemaName: "product", Delimiter: ";"}},
+		fakeStatement{stmt: "CREATE PROCEDURE bar() BEGIN SELECT 1; END", cs: ClientState{InstanceName: inst.String(), SchemaName: "product", Delimiter: "//"}},
+	}
+	expectDDL := "USE `product`;\nCREATE TABLE foo (id int);\nDELIMITER //\nCREATE PROCEDURE bar() BEGIN SELECT 1; END//\nDELIMITER ;\n"
+	if actual := plannedDDL(stmts); actual != expectDDL {
+		t.Errorf("Unexpected result from plannedDDL: %q", actual)
+	}
+
+	// No hooks configured, or dry-run: nil pushHooks
+	target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", ""), SchemaName: "product"}
+	if hooks, err := newPushHooks(target, stmts); hooks != nil || err != nil {
+		t.Errorf("Expected nil hooks and nil error without hook options, instead found %+v, %v", hooks, err)
+	}
+	target.Dir = getDir(t, "testdata/simple", "--pre-push-hook=true --dry-run")
+	if hooks, err := newPushHooks(target, stmts); hooks != nil || err != nil {
+		t.Errorf("Expected nil hooks and nil error with dry-run, instead found %+v, %v", hooks, err)
+	}
+
+	target.Dir = getDir(t, "testdata/simple", `--pre-push-hook='test {STATEMENTS} = 2 && test {HOST} = 1.2.3.4 && grep -q "CREATE TABLE foo" {DDLFILE}' --post-push-hook='test {SKIPPED} = 0'`)
+	hooks, err := newPushHooks(target, stmts)
+	if hooks == nil || err != nil {
+		t.Fatalf("Expected non-nil hooks and nil error, instead found %+v, %v", hooks, err)
+	}
+	defer hooks.cleanup()
+	if err := hooks.run("pre-push-hook", 0); err != nil {
+		t.Errorf("Unexpected error from pre-push-hook: %v", err)
+	}
+	if err := hooks.run("post-push-hook", 0); err != nil {
+		t.Errorf("Unexpected error from post-push-hook: %v", err)
+	}
+	if err := hooks.run("post-push-hook", 1); err == nil {
+		t.Error("Expected error from post-push-hook with nonzero skip count, but err was nil")
+	}
+
+	target.Dir = getDir(t, "testdata/simple", "--pre-push-hook='echo {NOPE}'")
+	hooks2, err := newPushHooks(target, stmts)
+	if err != nil {
+		t.Fatalf("Unexpected error from newPushHooks: %v", err)
+	}
+	defer hooks2.cleanup()
+	if err := hooks2.run("pre-push-hook", 0); err == nil {
+		t.Error("Expected error from unknown variable, but err was nil")
+	} else if _, ok := err.(ConfigError); !ok {
+		t.Errorf("Expected error to be a ConfigError, instead found %T", err)
+	}
+}
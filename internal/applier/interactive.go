@@ -0,0 +1,111 @@
+package applier
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// ErrInteractiveQuit is returned when the user chooses to quit at an
+// interactive confirmation prompt. No further targets should be processed.
+var ErrInteractiveQuit = errors.New("Push stopped at user request")
+
+type interactiveMode int
+
+// Constants enumerating valid values of the interactive-mode option
+const (
+	interactiveOff       interactiveMode = iota
+	interactiveTarget                    // prompt once per target, before any of its statements run
+	interactiveStatement                 // prompt before each statement
+)
+
+// interactiveState tracks state shared by all targets in interactive mode.
+// Prompting holds the lock for the duration of processing a target, so that
+// prompts and output from concurrent targets are never interleaved.
+var interactiveState struct {
+	sync.Mutex
+	approveAll bool // user answered "all" to a per-target prompt
+}
+
+// interactiveModeForDir returns the interactive confirmation mode configured
+// for dir. Interactive mode is never used for dry runs.
+func interactiveModeForDir(config *mybase.Config) (interactiveMode, error) {
+	if !config.GetBool("interactive") || config.GetBool("dry-run") {
+		return interactiveOff, nil
+	}
+	value, err := config.GetEnum("interactive-mode", "target", "statement")
+	if err != nil {
+		return interactiveOff, ConfigError(err.Error())
+	} else if value == "statement" {
+		return interactiveStatement, nil
+	}
+	return interactiveTarget, nil
+}
+
+// promptChoice prompts until the user answers yes, no, all, or quit, returning
+// the first letter of the answer.
+func promptChoice(format string, a ...interface{}) (byte, error) {
+	for {
+		answer, err := util.PromptLine(format+" [y]es/[n]o/[a]ll/[q]uit: ", a...)
+		if err != nil {
+			return 0, err
+		}
+		switch answer = strings.ToLower(answer); answer {
+		case "y", "yes", "n", "no", "a", "all", "q", "quit":
+			return answer[0], nil
+		}
+	}
+}
+
+// summarizeStatement returns the first line of stmt's SQL, truncated if
+// necessary, for use in a prompt.
+func summarizeStatement(stmt PlannedStatement) string {
+	summary, _, _ := strings.Cut(stmt.Statement(), "\n")
+	if len(summary) > 70 {
+		summary = summary[:67] + "..."
+	}
+	return summary
+}
+
+// confirmTarget prompts once for whether to execute all of stmts. It returns
+// false if the user declines. A per-target answer of "all" applies to all
+// subsequent targets as well, without further prompting.
+func (t *Target) confirmTarget(stmts []PlannedStatement) (bool, error) {
+	if interactiveState.approveAll {
+		return true, nil
+	}
+	answer, err := promptChoice("Execute %s on %s %s?", countAndNoun(len(stmts), "statement"), t.Instance, t.SchemaName)
+	if err != nil {
+		return false, err
+	}
+	switch answer {
+	case 'q':
+		return false, ErrInteractiveQuit
+	case 'a':
+		interactiveState.approveAll = true
+	}
+	return answer != 'n', nil
+}
+
+// confirmStatement prompts for whether to execute stmts[i]. A per-statement
+// answer of "all" applies to the remaining statements of the same target,
+// which is tracked by the caller via all.
+func (t *Target) confirmStatement(stmts []PlannedStatement, i int, all *bool) (bool, error) {
+	if *all {
+		return true, nil
+	}
+	answer, err := promptChoice("Execute statement %d of %d on %s %s (%s)?", i+1, len(stmts), t.Instance, t.SchemaName, summarizeStatement(stmts[i]))
+	if err != nil {
+		return false, err
+	}
+	switch answer {
+	case 'q':
+		return false, ErrInteractiveQuit
+	case 'a':
+		*all = true
+	}
+	return answer != 'n', nil
+}
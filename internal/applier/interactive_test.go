@@ -0,0 +1,111 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// countingStatement is a PlannedStatement which tracks how many times it has
+// been executed.
+type countingStatement struct {
+	fakeStatement
+	executed *int
+}
+
+func (cs countingStatement) Execute() error {
+	*cs.executed++
+	return nil
+}
+
+func TestProcessSQLInteractive(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	var executed int
+	stmts := make([]PlannedStatement, 3)
+	for n := range stmts {
+		stmts[n] = countingStatement{
+			fakeStatement: fakeStatement{stmt: "CREATE TABLE foo (id int)", cs: ClientState{InstanceName: inst.String(), SchemaName: "product", Delimiter: ";"}},
+			executed:      &executed,
+		}
+	}
+	origInput := util.LinePromptInput
+	defer func() {
+		util.LinePromptInput = origInput
+		interactiveState.approveAll = false
+	}()
+
+	cases := []struct {
+		flags          string
+		responses      []string
+		expectSkip     int
+		expectDeclined int
+		expectExecute  int
+		expectErr      string // "quit" for ErrInteractiveQuit, or "input" for any other error
+	}{
+		{"", nil, 0, 0, 3, ""},
+		{"--interactive --dry-run", nil, 0, 0, 0, ""},
+		{"--interactive", []string{"y"}, 0, 0, 3, ""},
+		{"--interactive", []string{"huh", "no"}, 0, 3, 0, ""},
+		{"--interactive", []string{"q"}, 0, 3, 0, "quit"},
+		{"--interactive --interactive-mode=statement", []string{"y", "n", "yes"}, 0, 1, 2, ""},
+		{"--interactive --interactive-mode=statement", []string{"n", "a"}, 0, 1, 2, ""},
+		{"--interactive --interactive-mode=statement", []string{"y", "quit"}, 0, 2, 1, "quit"},
+		{"--interactive --interactive-mode=statement", []string{"y"}, 2, 0, 1, "input"}, // mock input exhausted
+		{"--interactive", []string{"all"}, 0, 0, 3, ""},
+		{"--interactive", nil, 0, 0, 3, ""}, // no prompt needed after previous "all"
+	}
+	for _, c := range cases {
+		executed = 0
+		util.LinePromptInput = util.NewMockLineInput(c.responses...)
+		target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", c.flags), SchemaName: "product"}
+		skipCount, declinedCount, err := target.processSQL(stmts, NewPrinter(target.Dir.Config), nil, nil)
+		if c.expectErr == "" && err != nil {
+			t.Errorf("With flags %q and responses %v: unexpected error %v", c.flags, c.responses, err)
+		} else if c.expectErr == "quit" && err != ErrInteractiveQuit {
+			t.Errorf("With flags %q and responses %v: expected ErrInteractiveQuit, instead found %v", c.flags, c.responses, err)
+		} else if c.expectErr == "input" && (err == nil || err == ErrInteractiveQuit) {
+			t.Errorf("With flags %q and responses %v: expected input error, instead found %v", c.flags, c.responses, err)
+		}
+		if skipCount != c.expectSkip || declinedCount != c.expectDeclined || executed != c.expectExecute {
+			t.Errorf("With flags %q and responses %v: expected skipCount=%d declinedCount=%d executed=%d, instead found skipCount=%d declinedCount=%d executed=%d", c.flags, c.responses, c.expectSkip, c.expectDeclined, c.expectExecute, skipCount, declinedCount, executed)
+		}
+	}
+
+	// Push hooks should only run once the user has confirmed at least one
+	// statement
+	interactiveState.approveAll = false
+	hookDir := t.TempDir()
+	preFile, postFile := filepath.Join(hookDir, "pre"), filepath.Join(hookDir, "post")
+	hookFlags := fmt.Sprintf("--interactive --pre-push-hook='touch %s' --post-push-hook='touch %s'", preFile, postFile)
+	for _, response := range []string{"n", "y"} {
+		util.LinePromptInput = util.NewMockLineInput(response)
+		target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", hookFlags), SchemaName: "product"}
+		hooks, err := newPushHooks(target, stmts)
+		if err != nil {
+			t.Fatalf("Unexpected error from newPushHooks: %v", err)
+		}
+		if _, _, err := target.processSQL(stmts, NewPrinter(target.Dir.Config), nil, hooks); err != nil {
+			t.Errorf("Unexpected error from processSQL: %v", err)
+		}
+		hooks.cleanup()
+		_, preErr := os.Stat(preFile)
+		_, postErr := os.Stat(postFile)
+		if expectRan := (response == "y"); expectRan != (preErr == nil) || expectRan != (postErr == nil) {
+			t.Errorf("With response %q: expected hooks ran=%t, instead found pre-push-hook error %v, post-push-hook error %v", response, expectRan, preErr, postErr)
+		}
+	}
+
+	target := &Target{Instance: inst, Dir: getDir(t, "testdata/simple", "--interactive --interactive-mode=whatever"), SchemaName: "product"}
+	if _, _, err := target.processSQL(stmts, NewPrinter(target.Dir.Config), nil, nil); err == nil {
+		t.Error("Expected error from invalid interactive-mode, but err was nil")
+	} else if _, ok := err.(ConfigError); !ok {
+		t.Errorf("Expected error to be a ConfigError, instead found %T", err)
+	}
+}
@@ -0,0 +1,122 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// blockerPolicy controls how to handle sessions which may block execution of
+// an ALTER TABLE or DROP TABLE, by holding a metadata lock on the table. Such a
+// blocked DDL statement is especially dangerous, since all subsequent queries
+// on the table will queue up behind the DDL's pending metadata lock request.
+type blockerPolicy struct {
+	minSeconds   int           // only consider transactions or queries running at least this long
+	action       string        // "abort", "wait", or "kill"
+	maxWait      time.Duration // with action "wait", abort if still blocked after this long
+	pollInterval time.Duration
+}
+
+// blockerPolicyForDir returns a blockerPolicy based on the directory's
+// configuration, or nil if the check is not enabled.
+func blockerPolicyForDir(config *mybase.Config) (*blockerPolicy, error) {
+	if !config.Changed("blocking-trx-min-time") {
+		return nil, nil
+	}
+	minSeconds, err := config.GetInt("blocking-trx-min-time")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	} else if minSeconds < 0 {
+		return nil, ConfigError("blocking-trx-min-time cannot be negative")
+	}
+	policy := &blockerPolicy{
+		minSeconds:   minSeconds,
+		pollInterval: 5 * time.Second,
+	}
+	if policy.action, err = config.GetEnum("blocking-trx-action", "abort", "wait", "kill"); err != nil {
+		return nil, ConfigError(err.Error())
+	}
+	if maxWait, err := config.GetInt("blocking-trx-max-wait"); err != nil {
+		return nil, ConfigError(err.Error())
+	} else if maxWait < 0 {
+		return nil, ConfigError("blocking-trx-max-wait cannot be negative")
+	} else {
+		policy.maxWait = time.Duration(maxWait) * time.Second
+	}
+	return policy, nil
+}
+
+// checkBlockers looks for sessions which may block ddl's execution, and then
+// waits for them, kills them, or returns an error, depending on the policy.
+// This is only relevant to ALTER TABLE and DROP TABLE run directly by Skeema,
+// rather than by an external wrapper command.
+func (ddl *DDLStatement) checkBlockers() error {
+	policy := ddl.blockers
+	deadline := time.Now().Add(policy.maxWait)
+	var warned bool
+	for {
+		blockers, err := ddl.instance.BlockingSessions(ddl.schemaName, ddl.tableName, policy.minSeconds)
+		if err != nil {
+			return fmt.Errorf("Unable to check for transactions blocking DDL on %s: %w", ddl.tableName, err)
+		} else if len(blockers) == 0 {
+			return nil
+		}
+		descriptions := make([]string, len(blockers))
+		for n, bs := range blockers {
+			descriptions[n] = "  " + bs.String()
+		}
+		desc := fmt.Sprintf("DDL for table %s on %s %s may be blocked by %s:\n%s", tengo.EscapeIdentifier(ddl.tableName), ddl.instance, ddl.schemaName, countAndNoun(len(blockers), "session"), strings.Join(descriptions, "\n"))
+
+		// Only kill sessions which are confirmed to hold a metadata lock on the
+		// table. If the server cannot provide this information, blockers were found
+		// heuristically, and could be unrelated to this table.
+		if policy.action == "kill" && !allHoldMDL(blockers) {
+			fallback := &blockerPolicy{minSeconds: policy.minSeconds, action: "abort"}
+			if policy.maxWait > 0 {
+				fallback.action, fallback.maxWait, fallback.pollInterval = "wait", policy.maxWait, policy.pollInterval
+			}
+			log.Warnf("Unable to confirm which sessions hold metadata locks on table %s on %s %s, since performance_schema.metadata_locks is unavailable or its instrument is disabled. Using blocking-trx-action=%s instead of kill.", tengo.EscapeIdentifier(ddl.tableName), ddl.instance, ddl.schemaName, fallback.action)
+			policy = fallback
+		}
+
+		switch policy.action {
+		case "kill":
+			log.Warnf("%s\nKilling these sessions due to blocking-trx-action=kill", desc)
+			for _, bs := range blockers {
+				if err := ddl.instance.KillSession(bs.ID); err != nil {
+					return fmt.Errorf("Unable to kill session %d: %w", bs.ID, err)
+				}
+			}
+			policy = &blockerPolicy{minSeconds: policy.minSeconds, action: "abort"} // re-check once, without killing again
+			time.Sleep(time.Second)
+		case "wait":
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%s\nGiving up after waiting %s (blocking-trx-max-wait)", desc, policy.maxWait)
+			}
+			if !warned {
+				log.Warnf("%s\nWaiting for these sessions to complete (blocking-trx-action=wait)", desc)
+				warned = true
+			} else {
+				log.Debug(desc)
+			}
+			time.Sleep(policy.pollInterval)
+		default:
+			return fmt.Errorf("%s\nUse blocking-trx-action=wait or blocking-trx-action=kill to permit this operation.", desc)
+		}
+	}
+}
+
+// allHoldMDL returns true if every supplied session is confirmed to hold a
+// metadata lock.
+func allHoldMDL(sessions []tengo.BlockingSession) bool {
+	for _, bs := range sessions {
+		if !bs.HoldsMDL {
+			return false
+		}
+	}
+	return true
+}
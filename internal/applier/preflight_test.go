@@ -0,0 +1,37 @@
+package applier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockerPolicyForDir(t *testing.T) {
+	assertPolicy := func(cliFlags string, expectNil, expectErr bool) *blockerPolicy {
+		t.Helper()
+		cfg := getBaseConfig(t, cliFlags)
+		policy, err := blockerPolicyForDir(cfg)
+		if expectErr != (err != nil) {
+			t.Errorf("With flags %q, expected error=%t, instead found err=%v", cliFlags, expectErr, err)
+		} else if expectNil != (policy == nil) {
+			t.Errorf("With flags %q, expected nil policy=%t, instead found %+v", cliFlags, expectNil, policy)
+		}
+		return policy
+	}
+
+	assertPolicy("", true, false)
+	assertPolicy("--blocking-trx-action=kill", true, false)
+	assertPolicy("--blocking-trx-min-time=banana", true, true)
+	assertPolicy("--blocking-trx-min-time=-5", true, true)
+	assertPolicy("--blocking-trx-min-time=30 --blocking-trx-action=wat", true, true)
+	assertPolicy("--blocking-trx-min-time=30 --blocking-trx-max-wait=-1", true, true)
+	if policy := assertPolicy("--blocking-trx-min-time=30", false, false); policy != nil {
+		if policy.minSeconds != 30 || policy.action != "abort" || policy.maxWait != 300*time.Second {
+			t.Errorf("Unexpected policy values: %+v", policy)
+		}
+	}
+	if policy := assertPolicy("--blocking-trx-min-time=10 --blocking-trx-action=wait --blocking-trx-max-wait=60", false, false); policy != nil {
+		if policy.minSeconds != 10 || policy.action != "wait" || policy.maxWait != 60*time.Second {
+			t.Errorf("Unexpected policy values: %+v", policy)
+		}
+	}
+}
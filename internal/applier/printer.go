@@ -1,11 +1,15 @@
 package applier
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
 )
 
 // Printer formats and displays a statement, ideally in a manner that is
@@ -14,6 +18,19 @@ type Printer interface {
 	Print(ps PlannedStatement)
 }
 
+// algorithmPredictor is an optional interface for PlannedStatements which can
+// predict the ALTER TABLE algorithm that the server will use.
+type algorithmPredictor interface {
+	PredictedAlgorithm() tengo.AlterAlgorithm
+}
+
+// annotator is an optional interface for PlannedStatements which have notes to
+// display as comments preceding the statement. If warn is true, the notes are
+// highlighted when STDOUT is a terminal.
+type annotator interface {
+	Annotations() (notes []string, warn bool)
+}
+
 // standardPrinter displays full output for each statement.
 type standardPrinter struct {
 	lastStdoutInstance  string
@@ -31,18 +48,43 @@ type instanceDiffPrinter struct {
 	m            sync.Mutex
 }
 
+// jsonPrinter displays each statement as a JSON object on its own line, for
+// consumption by other programs.
+type jsonPrinter struct {
+	enc *json.Encoder
+	m   sync.Mutex
+}
+
+// jsonStatement is the structure of each line of jsonPrinter output.
+type jsonStatement struct {
+	Instance           string   `json:"instance"`
+	Schema             string   `json:"schema,omitempty"`
+	Statement          string   `json:"statement"`
+	Delimiter          string   `json:"delimiter,omitempty"`
+	PredictedAlgorithm string   `json:"predicted_algorithm,omitempty"`
+	Annotations        []string `json:"annotations,omitempty"`
+}
+
 // NewPrinter returns a standard printer (displaying all generated SQL), unless
 // the supplied configuration requests only outputting names of instances that
-// have differences.
+// have differences, or requests JSON output.
 func NewPrinter(cfg *mybase.Config) Printer {
 	if cfg.GetBool("brief") {
 		return &instanceDiffPrinter{
 			seenInstance: make(map[string]bool),
 		}
+	} else if cfg.GetBool("json") {
+		return newJSONPrinter(os.Stdout)
 	}
 	return &standardPrinter{lastStdoutDelimiter: ";"}
 }
 
+func newJSONPrinter(w io.Writer) *jsonPrinter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonPrinter{enc: enc}
+}
+
 // Print outputs stmt to STDOUT, in a way that prevents interleaving of output
 // from multiple goroutines.
 // TODO: buffer output from external commands and also prevent interleaving there
@@ -71,6 +113,21 @@ func (p *standardPrinter) Print(stmt PlannedStatement) {
 		fmt.Printf("DELIMITER %s\n", cs.Delimiter)
 		p.lastStdoutDelimiter = cs.Delimiter
 	}
+	if ap, ok := stmt.(algorithmPredictor); ok {
+		if algo := ap.PredictedAlgorithm(); algo != tengo.AlterAlgorithmUnknown {
+			fmt.Printf("-- predicted algorithm: %s\n", algo)
+		}
+	}
+	if a, ok := stmt.(annotator); ok {
+		notes, warn := a.Annotations()
+		var startColor, endColor string
+		if warn && util.StdoutIsTerminal() {
+			startColor, endColor = "\x1b[31;1m", "\x1b[0m" // bright red
+		}
+		for _, note := range notes {
+			fmt.Printf("%s-- %s%s\n", startColor, note, endColor)
+		}
+	}
 	fmt.Print(stmt.Statement(), cs.Delimiter, "\n")
 }
 
@@ -84,3 +141,25 @@ func (idp *instanceDiffPrinter) Print(stmt PlannedStatement) {
 		idp.seenInstance[instString] = true
 	}
 }
+
+// Print outputs stmt as a single line of JSON.
+func (jp *jsonPrinter) Print(stmt PlannedStatement) {
+	cs := stmt.ClientState()
+	js := jsonStatement{
+		Instance:  cs.InstanceName,
+		Schema:    cs.SchemaName,
+		Statement: stmt.Statement(),
+		Delimiter: cs.Delimiter,
+	}
+	if ap, ok := stmt.(algorithmPredictor); ok {
+		if algo := ap.PredictedAlgorithm(); algo != tengo.AlterAlgorithmUnknown {
+			js.PredictedAlgorithm = algo.String()
+		}
+	}
+	if a, ok := stmt.(annotator); ok {
+		js.Annotations, _ = a.Annotations()
+	}
+	jp.m.Lock()
+	defer jp.m.Unlock()
+	jp.enc.Encode(js) // write errors are ignored, as with fmt.Print in the other printers
+}
@@ -0,0 +1,43 @@
+package applier
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestJSONPrinter(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	stmts := []PlannedStatement{
+		&DDLStatement{
+			stmt:        "ALTER TABLE `foo` ADD COLUMN `name` varchar(30) DEFAULT NULL",
+			instance:    inst,
+			schemaName:  "product",
+			algorithm:   tengo.AlterAlgorithmInstant,
+			annotations: []string{"Adds column name"},
+		},
+		&DDLStatement{
+			stmt:       "CREATE PROCEDURE bar() BEGIN SELECT '<1>'; END",
+			instance:   inst,
+			schemaName: "product",
+			compound:   true,
+		},
+		fakeStatement{stmt: "DROP TABLE `baz`", cs: ClientState{InstanceName: inst.String(), SchemaName: "product", Delimiter: ";"}},
+	}
+	var buf bytes.Buffer
+	jp := newJSONPrinter(&buf)
+	for _, stmt := range stmts {
+		jp.Print(stmt)
+	}
+	expected := `{"instance":"1.2.3.4:3306","schema":"product","statement":"ALTER TABLE ` + "`foo`" + ` ADD COLUMN ` + "`name`" + ` varchar(30) DEFAULT NULL","delimiter":";","predicted_algorithm":"INSTANT","annotations":["Adds column name"]}
+{"instance":"1.2.3.4:3306","schema":"product","statement":"CREATE PROCEDURE bar() BEGIN SELECT '<1>'; END","delimiter":"//"}
+{"instance":"1.2.3.4:3306","schema":"product","statement":"DROP TABLE ` + "`baz`" + `","delimiter":";"}
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected output from jsonPrinter:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
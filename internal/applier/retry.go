@@ -0,0 +1,87 @@
+package applier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/VividCortex/mysqlerr"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// retryPolicy controls retrying of table DDL which fails due to a transient
+// error: a lock wait timeout or deadlock.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration // delay before the first retry; doubles for each subsequent retry
+}
+
+// retryPolicyForDir returns a retryPolicy based on the directory's
+// configuration, or nil if retries are not enabled.
+func retryPolicyForDir(config *mybase.Config) (*retryPolicy, error) {
+	maxAttempts, err := config.GetInt("ddl-max-attempts")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	} else if maxAttempts < 1 {
+		return nil, ConfigError("ddl-max-attempts must be at least 1")
+	} else if maxAttempts == 1 {
+		return nil, nil
+	}
+	backoff, err := config.GetInt("ddl-retry-backoff")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	} else if backoff < 0 {
+		return nil, ConfigError("ddl-retry-backoff cannot be negative")
+	}
+	return &retryPolicy{
+		maxAttempts: maxAttempts,
+		backoff:     time.Duration(backoff) * time.Second,
+	}, nil
+}
+
+// execWithRetries runs ddl's statement using exec, retrying upon lock wait
+// timeouts or deadlocks. The server rolls back a statement which fails with one
+// of these errors, but since DDL is not transactional, the table is still
+// re-introspected using state after each failure as a safeguard against
+// running a non-idempotent statement twice: the statement is only retried if
+// the table is unchanged from before the first attempt.
+func (ddl *DDLStatement) execWithRetries(exec func() error, state func() (string, error)) error {
+	before, err := state()
+	if err != nil {
+		return err
+	}
+	delay := ddl.retries.backoff
+	for attempt := 1; ; attempt++ {
+		err = exec()
+		if err == nil || !tengo.IsTransientError(err) || attempt >= ddl.retries.maxAttempts {
+			return err
+		}
+		after, stateErr := state()
+		if stateErr != nil {
+			return fmt.Errorf("%w\nNot retrying, since the current state of table %s could not be determined: %v", err, tengo.EscapeIdentifier(ddl.tableName), stateErr)
+		} else if after == ddl.expectedState {
+			log.Warnf("Statement on %s %s returned error %q, but table %s is now in the expected state; not retrying", ddl.instance, ddl.schemaName, err, tengo.EscapeIdentifier(ddl.tableName))
+			return nil
+		} else if after != before {
+			return fmt.Errorf("%w\nNot retrying, since table %s was modified despite this error. Confirm its current state before running push again.", err, tengo.EscapeIdentifier(ddl.tableName))
+		}
+		log.Warnf("Transient error running statement on %s %s: %s\nRetrying in %s (attempt %d of %d)", ddl.instance, ddl.schemaName, err, delay, attempt+1, ddl.retries.maxAttempts)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// tableState returns the table's current CREATE TABLE statement, without any
+// next-auto-increment value since that may change independently of DDL. If the
+// table does not exist, an empty string is returned.
+func (ddl *DDLStatement) tableState() (string, error) {
+	create, err := ddl.instance.ShowCreateTable(ddl.schemaName, ddl.tableName)
+	if tengo.IsDatabaseError(err, mysqlerr.ER_NO_SUCH_TABLE) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	create, _ = tengo.ParseCreateAutoInc(create)
+	return create, nil
+}
@@ -0,0 +1,87 @@
+package applier
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VividCortex/mysqlerr"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestRetryPolicyForDir(t *testing.T) {
+	assertPolicy := func(cliFlags string, expectNil, expectErr bool) *retryPolicy {
+		t.Helper()
+		cfg := getBaseConfig(t, cliFlags)
+		policy, err := retryPolicyForDir(cfg)
+		if expectErr != (err != nil) {
+			t.Errorf("With flags %q, expected error=%t, instead found err=%v", cliFlags, expectErr, err)
+		} else if expectNil != (policy == nil) {
+			t.Errorf("With flags %q, expected nil policy=%t, instead found %+v", cliFlags, expectNil, policy)
+		}
+		return policy
+	}
+
+	assertPolicy("", true, false)
+	assertPolicy("--ddl-retry-backoff=10", true, false)
+	assertPolicy("--ddl-max-attempts=0", true, true)
+	assertPolicy("--ddl-max-attempts=banana", true, true)
+	assertPolicy("--ddl-max-attempts=3 --ddl-retry-backoff=-1", true, true)
+	if policy := assertPolicy("--ddl-max-attempts=3", false, false); policy != nil {
+		if policy.maxAttempts != 3 || policy.backoff != 5*time.Second {
+			t.Errorf("Unexpected policy values: %+v", policy)
+		}
+	}
+}
+
+func TestExecWithRetries(t *testing.T) {
+	lockErr := &mysql.MySQLError{Number: mysqlerr.ER_LOCK_WAIT_TIMEOUT, Message: "Lock wait timeout exceeded; try restarting transaction"}
+	ddl := &DDLStatement{
+		schemaName:    "product",
+		tableName:     "users",
+		expectedState: "after",
+		retries:       &retryPolicy{maxAttempts: 3},
+	}
+
+	cases := []struct {
+		name         string
+		execErrs     []error  // result of each exec call; nil once exhausted
+		states       []string // result of each state call
+		expectExecs  int
+		expectErrMsg string // substring of expected error, or "" if no error expected
+	}{
+		{"success", []error{}, []string{"before"}, 1, ""},
+		{"retry then success", []error{lockErr}, []string{"before", "before"}, 2, ""},
+		{"already in expected state", []error{lockErr}, []string{"before", "after"}, 1, ""},
+		{"modified by failed attempt", []error{lockErr}, []string{"before", "other"}, 1, "was modified"},
+		{"attempts exhausted", []error{lockErr, lockErr, lockErr}, []string{"before", "before", "before"}, 3, "Lock wait timeout"},
+		{"non-transient error", []error{mysql.ErrInvalidConn}, []string{"before"}, 1, "invalid connection"},
+	}
+	for _, c := range cases {
+		var execs, stateCalls int
+		exec := func() (err error) {
+			if execs < len(c.execErrs) {
+				err = c.execErrs[execs]
+			}
+			execs++
+			return err
+		}
+		state := func() (string, error) {
+			if stateCalls >= len(c.states) {
+				return "", errors.New("unexpected extra call to state")
+			}
+			stateCalls++
+			return c.states[stateCalls-1], nil
+		}
+		err := ddl.execWithRetries(exec, state)
+		if c.expectErrMsg == "" && err != nil {
+			t.Errorf("Case %q: unexpected error %v", c.name, err)
+		} else if c.expectErrMsg != "" && (err == nil || !strings.Contains(err.Error(), c.expectErrMsg)) {
+			t.Errorf("Case %q: expected error containing %q, instead found %v", c.name, c.expectErrMsg, err)
+		}
+		if execs != c.expectExecs {
+			t.Errorf("Case %q: expected %d exec calls, instead found %d", c.name, c.expectExecs, execs)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package applier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// RolloutPlan divides a push into sequential waves of instances, beginning with
+// a canary wave. Each instance is checked after being pushed, and the push
+// pauses between waves, so that a problematic change can be caught before it
+// reaches every instance.
+type RolloutPlan struct {
+	Waves       [][]TargetGroup
+	verify      bool
+	healthCheck string
+	pause       time.Duration
+	prompt      bool
+}
+
+// RolloutPlanForDir returns a RolloutPlan dividing groups into waves based on
+// the dir's rollout-canary and rollout-wave-size options, or nil if staged
+// rollout is not enabled, this is a dry run, or there are no groups. The order
+// of groups is retained, so the canary wave consists of the first groups.
+func RolloutPlanForDir(config *mybase.Config, groups []TargetGroup) (*RolloutPlan, error) {
+	if config.GetBool("dry-run") || config.Get("rollout-canary") == "" || len(groups) == 0 {
+		return nil, nil
+	}
+	canarySize, err := parseWaveSize(config.Get("rollout-canary"), len(groups))
+	if err != nil {
+		return nil, ConfigError(fmt.Sprintf("Invalid value for rollout-canary: %s", err))
+	}
+	waveSize := len(groups)
+	if config.Get("rollout-wave-size") != "" {
+		if waveSize, err = parseWaveSize(config.Get("rollout-wave-size"), len(groups)); err != nil {
+			return nil, ConfigError(fmt.Sprintf("Invalid value for rollout-wave-size: %s", err))
+		}
+	}
+	plan := &RolloutPlan{
+		verify:      config.GetBool("rollout-verify"),
+		healthCheck: config.Get("rollout-health-check"),
+	}
+	if pause := config.Get("rollout-pause"); strings.ToLower(pause) == "prompt" {
+		if !util.StdinIsTerminal() {
+			return nil, ConfigError("rollout-pause=prompt requires STDIN to be a TTY")
+		}
+		plan.prompt = true
+	} else if seconds, err := strconv.ParseUint(pause, 10, 32); err != nil {
+		return nil, ConfigError(fmt.Sprintf("Invalid value for rollout-pause: %q; expected a number of seconds or \"prompt\"", pause))
+	} else {
+		plan.pause = time.Duration(seconds) * time.Second
+	}
+
+	for len(groups) > 0 {
+		size := waveSize
+		if len(plan.Waves) == 0 {
+			size = canarySize
+		}
+		if size > len(groups) {
+			size = len(groups)
+		}
+		plan.Waves = append(plan.Waves, groups[:size])
+		groups = groups[size:]
+	}
+	return plan, nil
+}
+
+// parseWaveSize converts value, which may be an integer or a percentage, to a
+// number of instances out of total. Percentages are rounded up, so that any
+// non-zero percentage always results in at least one instance.
+func parseWaveSize(value string, total int) (int, error) {
+	if strings.HasSuffix(value, "%") {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || pct <= 0 || pct > 100 {
+			return 0, fmt.Errorf("%q is not a valid percentage", value)
+		}
+		size := int(float64(total) * pct / 100)
+		if float64(size) < float64(total)*pct/100 {
+			size++
+		}
+		return size, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("%q is not a positive integer or percentage", value)
+	}
+	return size, nil
+}
+
+// CheckGroup confirms that the instance in tg was successfully pushed. If
+// rollout-verify is enabled, each target's schema is re-introspected to confirm
+// that no differences remain. If rollout-health-check is set, it is then run
+// once for the instance. A non-nil error is returned if any check fails.
+func (plan *RolloutPlan) CheckGroup(tg TargetGroup, waveNumber int) error {
+	if len(tg) == 0 {
+		return nil
+	}
+	if plan.verify {
+		for _, t := range tg {
+			if err := verifyTargetApplied(t); err != nil {
+				return err
+			}
+		}
+	}
+	if plan.healthCheck == "" {
+		return nil
+	}
+	t := tg[0]
+	variables, err := t.shellOutVariables()
+	if err != nil {
+		return err
+	}
+	schemaNames := make([]string, len(tg))
+	for n := range tg {
+		schemaNames[n] = tg[n].SchemaName
+	}
+	variables["SCHEMAS"] = strings.Join(schemaNames, ",")
+	variables["WAVE"] = strconv.Itoa(waveNumber)
+	s, err := util.NewInterpolatedShellOut(plan.healthCheck, variables)
+	if err != nil {
+		return ConfigError(fmt.Sprintf("Invalid rollout-health-check: %s", err))
+	}
+	s.Dir = t.Dir.Path
+	log.Infof("Running rollout-health-check for %s: %s", t.Instance, s)
+	if err := s.Run(); err != nil {
+		return fmt.Errorf("rollout-health-check failed for %s: %w", t.Instance, err)
+	}
+	return nil
+}
+
+// verifyTargetApplied re-introspects the target's schema, and returns an error
+// if any differences from the filesystem remain. Differences which are not
+// supported by Skeema, or which are ignored by the dir's configuration, are
+// not considered.
+func verifyTargetApplied(t *Target) error {
+	schemaFromInstance, err := t.SchemaFromInstance()
+	if err != nil {
+		return fmt.Errorf("Unable to re-introspect %s schema %s: %w", t.Instance, t.SchemaName, err)
+	}
+	mods, err := StatementModifiersForDir(t.Dir)
+	if err != nil {
+		return ConfigError(err.Error())
+	}
+	mods.Flavor = t.Instance.Flavor()
+	schemaFromDir := t.SchemaFromDir()
+	if mods.Partitioning == tengo.PartitioningRemove {
+		stripPartitionClauses(schemaFromDir.Tables, mods.Flavor)
+	}
+	applyDeprecations(schemaFromDir, schemaFromInstance, t, mods.Flavor)
+	mods.AllowUnsafe = true // only care whether a difference remains, not whether it is safe
+	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
+	if _, err := restrictDiff(diff, t.Dir); err != nil {
+		return err
+	}
+	var remaining []string
+	for _, objDiff := range diff.ObjectDiffs() {
+		stmt, err := objDiff.Statement(mods)
+		if _, ok := err.(*tengo.UnsupportedDiffError); ok {
+			continue
+		} else if stmt != "" || err != nil {
+			remaining = append(remaining, objDiff.ObjectKey().String())
+		}
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("Verification after push found %d remaining difference(s) on %s schema %s: %s", len(remaining), t.Instance, t.SchemaName, strings.Join(remaining, ", "))
+	}
+	return nil
+}
+
+// BetweenWaves is called after waveNumber (1-indexed) has been completed
+// successfully, prior to beginning the next wave. It pauses or prompts as
+// configured. A non-nil error is returned if the rollout should not continue.
+func (plan *RolloutPlan) BetweenWaves(waveNumber int) error {
+	remaining := 0
+	for _, wave := range plan.Waves[waveNumber:] {
+		remaining += len(wave)
+	}
+	noun := "wave"
+	if waveNumber == 1 {
+		noun = "canary wave"
+	}
+	if plan.prompt {
+		ok, err := util.PromptConfirm("Completed %s %d of %d. Continue with %s?", noun, waveNumber, len(plan.Waves), countAndNoun(remaining, "remaining instance"))
+		if err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("Rollout stopped by user after %s %d of %d", noun, waveNumber, len(plan.Waves))
+		}
+	} else if plan.pause > 0 {
+		log.Infof("Completed %s %d of %d. Pausing %s before continuing with %s", noun, waveNumber, len(plan.Waves), plan.pause, countAndNoun(remaining, "remaining instance"))
+		time.Sleep(plan.pause)
+	} else {
+		log.Infof("Completed %s %d of %d. Continuing with %s", noun, waveNumber, len(plan.Waves), countAndNoun(remaining, "remaining instance"))
+	}
+	return nil
+}
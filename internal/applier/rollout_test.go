@@ -0,0 +1,143 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestParseWaveSize(t *testing.T) {
+	cases := []struct {
+		value    string
+		total    int
+		expected int
+	}{
+		{"1", 256, 1},
+		{"10", 256, 10},
+		{"300", 256, 300},
+		{"5%", 256, 13},
+		{"50%", 10, 5},
+		{"0.1%", 10, 1},
+		{"100%", 7, 7},
+	}
+	for _, c := range cases {
+		if actual, err := parseWaveSize(c.value, c.total); err != nil || actual != c.expected {
+			t.Errorf("Expected parseWaveSize(%q, %d) to return %d, nil; instead found %d, %v", c.value, c.total, c.expected, actual, err)
+		}
+	}
+	for _, value := range []string{"0", "-1", "0%", "101%", "banana", "five%", ""} {
+		if _, err := parseWaveSize(value, 10); err == nil {
+			t.Errorf("Expected error from parseWaveSize(%q), but err was nil", value)
+		}
+	}
+}
+
+func TestRolloutPlanForDir(t *testing.T) {
+	groups := make([]TargetGroup, 10)
+	for n := range groups {
+		groups[n] = TargetGroup{&Target{SchemaName: string(rune('a' + n))}}
+	}
+	assertWaveSizes := func(cliFlags string, expected ...int) {
+		t.Helper()
+		plan, err := RolloutPlanForDir(getBaseConfig(t, cliFlags), groups)
+		if err != nil {
+			t.Fatalf("Unexpected error from RolloutPlanForDir with %q: %v", cliFlags, err)
+		}
+		if len(expected) == 0 {
+			if plan != nil {
+				t.Errorf("Expected nil plan with %q, instead found %+v", cliFlags, plan)
+			}
+			return
+		}
+		var actual []int
+		for _, wave := range plan.Waves {
+			actual = append(actual, len(wave))
+		}
+		if len(actual) != len(expected) {
+			t.Errorf("With %q, expected wave sizes %v, instead found %v", cliFlags, expected, actual)
+			return
+		}
+		for n := range actual {
+			if actual[n] != expected[n] {
+				t.Errorf("With %q, expected wave sizes %v, instead found %v", cliFlags, expected, actual)
+				return
+			}
+		}
+		if plan.Waves[0][0][0].SchemaName != "a" {
+			t.Errorf("With %q, expected canary wave to begin with first group", cliFlags)
+		}
+	}
+	assertWaveSizes("")
+	assertWaveSizes("--rollout-canary=1 --dry-run")
+	assertWaveSizes("--rollout-canary=1", 1, 9)
+	assertWaveSizes("--rollout-canary=2 --rollout-wave-size=3", 2, 3, 3, 2)
+	assertWaveSizes("--rollout-canary=15%", 2, 8)
+	assertWaveSizes("--rollout-canary=1 --rollout-wave-size=50%", 1, 5, 4)
+	assertWaveSizes("--rollout-canary=20", 10)
+
+	for _, cliFlags := range []string{"--rollout-canary=0", "--rollout-canary=1 --rollout-wave-size=0%", "--rollout-canary=1 --rollout-pause=soon"} {
+		if _, err := RolloutPlanForDir(getBaseConfig(t, cliFlags), groups); err == nil {
+			t.Errorf("Expected error from RolloutPlanForDir with %q, but err was nil", cliFlags)
+		} else if _, ok := err.(ConfigError); !ok {
+			t.Errorf("Expected ConfigError from RolloutPlanForDir with %q, instead found %T", cliFlags, err)
+		}
+	}
+
+	// Without a pause or prompt, BetweenWaves should return immediately
+	plan, _ := RolloutPlanForDir(getBaseConfig(t, "--rollout-canary=1"), groups)
+	if err := plan.BetweenWaves(1); err != nil {
+		t.Errorf("Unexpected error from BetweenWaves: %v", err)
+	}
+}
+
+func TestRolloutHealthCheck(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3306)/")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	dir := getDir(t, "testdata/simple", "--rollout-canary=1 --skip-rollout-verify --rollout-health-check='test {HOST} = 1.2.3.4 && test {SCHEMAS} = one,two && test {WAVE} = 1'")
+	tg := TargetGroup{
+		&Target{Instance: inst, Dir: dir, SchemaName: "one"},
+		&Target{Instance: inst, Dir: dir, SchemaName: "two"},
+	}
+	plan, err := RolloutPlanForDir(dir.Config, []TargetGroup{tg})
+	if err != nil {
+		t.Fatalf("Unexpected error from RolloutPlanForDir: %v", err)
+	}
+	if err := plan.CheckGroup(tg, 1); err != nil {
+		t.Errorf("Unexpected error from CheckGroup: %v", err)
+	}
+	if err := plan.CheckGroup(tg, 2); err == nil {
+		t.Error("Expected error from CheckGroup with failing health check, but err was nil")
+	}
+	plan.healthCheck = "echo {NOPE}"
+	if err := plan.CheckGroup(tg, 1); err == nil {
+		t.Error("Expected error from CheckGroup with invalid variable, but err was nil")
+	} else if _, ok := err.(ConfigError); !ok {
+		t.Errorf("Expected ConfigError, instead found %T", err)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestVerifyTargetApplied(t *testing.T) {
+	setupHostList(t, s.d[0].Instance)
+	dir := getDir(t, "testdata/simple", "")
+	targets, skipCount := TargetsForDir(dir, 1)
+	if len(targets) != 2 || skipCount != 0 {
+		t.Fatalf("Unexpected result from TargetsForDir: %+v, %d", targets, skipCount)
+	}
+
+	// Before pushing, differences remain
+	if err := verifyTargetApplied(targets[0]); err == nil || !strings.Contains(err.Error(), "remaining difference") {
+		t.Errorf("Expected error about remaining differences, instead found %v", err)
+	}
+
+	// After pushing, no differences remain
+	printer := NewPrinter(dir.Config)
+	if result, err := ApplyTarget(targets[0], printer); err != nil || result.SkipCount > 0 {
+		t.Fatalf("Unexpected result from ApplyTarget: %+v, %v", result, err)
+	}
+	if err := verifyTargetApplied(targets[0]); err != nil {
+		t.Errorf("Unexpected error from verifyTargetApplied: %v", err)
+	}
+}
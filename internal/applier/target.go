@@ -3,10 +3,12 @@ package applier
 import (
 	"database/sql"
 	"os"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/skeema/internal/workspace"
 )
 
@@ -19,6 +21,10 @@ type Target struct {
 	Dir           *fs.Dir
 	SchemaName    string
 	DesiredSchema *workspace.Schema
+
+	// workspaceSlot distinguishes concurrent workers operating on the same
+	// instance, so that each can use a separate workspace; see ApplyTargetGroup
+	workspaceSlot int
 }
 
 // SchemaFromInstance introspects and returns the instance's version of the
@@ -39,6 +45,33 @@ func (t *Target) SchemaFromDir() *tengo.Schema {
 	return &schemaCopy
 }
 
+// shellOutVariables returns variables describing the target, for use in
+// interpolating external commands such as alter-wrapper or pre-push-hook.
+func (t *Target) shellOutVariables() (map[string]string, error) {
+	var socket, port string
+	if t.Instance.SocketPath != "" {
+		socket = t.Instance.SocketPath
+	} else {
+		port = strconv.Itoa(t.Instance.Port)
+	}
+	connOpts, err := util.RealConnectOptions(t.Dir.Config.Get("connect-options"))
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	}
+	return map[string]string{
+		"HOST":        t.Instance.Host,
+		"PORT":        port,
+		"SOCKET":      socket,
+		"SCHEMA":      t.SchemaName,
+		"USER":        t.Instance.User,
+		"PASSWORD":    t.Instance.Password,
+		"ENVIRONMENT": t.Dir.Config.Get("environment"),
+		"CONNOPTS":    connOpts,
+		"DIRNAME":     t.Dir.BaseName(),
+		"DIRPATH":     t.Dir.Path,
+	}, nil
+}
+
 func (t *Target) logApplyStart() {
 	if t.Dir.Config.GetBool("dry-run") {
 		log.Infof("Generating diff of %s %s vs %s%c*.sql", t.Instance, t.SchemaName, t.Dir, os.PathSeparator)
@@ -62,22 +95,95 @@ func (t *Target) logApplyEnd(result Result) {
 	}
 }
 
-func (t *Target) processSQL(stmts []PlannedStatement, printer Printer) (skipCount int) {
+// processSQL prints stmts and, if not a dry run, executes them, returning the
+// number of statements which were skipped due to problems, and separately the
+// number which the user declined to execute in interactive mode. If hooks is non-nil, pre-push-hook
+// is run just before the first statement is executed, which means it does not
+// run at all if the user declines every statement in interactive mode. In that
+// case post-push-hook is skipped as well; otherwise it runs after the last
+// statement, even if execution stopped early.
+func (t *Target) processSQL(stmts []PlannedStatement, printer Printer, throttler *replicaThrottler, hooks *pushHooks) (skipCount, declinedCount int, err error) {
+	var hooksStarted bool
+	if hooks != nil {
+		defer func() {
+			if !hooksStarted {
+				return
+			}
+			if hookErr := hooks.run("post-push-hook", skipCount); hookErr != nil {
+				log.Errorf("post-push-hook failed for %s %s: %s", t.Instance, t.SchemaName, hookErr)
+			}
+		}()
+	}
+
+	// In interactive mode, display all statements up-front, and then prompt for
+	// confirmation before executing them
+	mode, err := interactiveModeForDir(t.Dir.Config)
+	if err != nil {
+		return len(stmts), 0, err
+	} else if mode != interactiveOff {
+		interactiveState.Lock()
+		defer interactiveState.Unlock()
+		for _, stmt := range stmts {
+			printer.Print(stmt)
+		}
+		if mode == interactiveTarget {
+			if ok, err := t.confirmTarget(stmts); err == ErrInteractiveQuit {
+				return 0, len(stmts), err
+			} else if err != nil {
+				return len(stmts), 0, err
+			} else if !ok {
+				log.Warnf("Skipping %s for %s %s at user request", countAndNoun(len(stmts), "statement"), t.Instance, t.SchemaName)
+				return 0, len(stmts), nil
+			}
+		}
+	}
+
+	var all bool // only used with interactiveStatement
 	for i, stmt := range stmts {
-		printer.Print(stmt)
+		if mode == interactiveOff {
+			printer.Print(stmt)
+		} else if mode == interactiveStatement {
+			if ok, err := t.confirmStatement(stmts, i, &all); err == ErrInteractiveQuit {
+				return skipCount, declinedCount + len(stmts) - i, err
+			} else if err != nil {
+				return skipCount + len(stmts) - i, declinedCount, err
+			} else if !ok {
+				log.Warnf("Skipping statement %d of %d for %s %s at user request", i+1, len(stmts), t.Instance, t.SchemaName)
+				declinedCount++
+				continue
+			}
+		}
+		if hooks != nil && !hooksStarted {
+			hooksStarted = true
+			if err := hooks.run("pre-push-hook", 0); err != nil {
+				hooksStarted = false
+				if _, ok := err.(ConfigError); ok {
+					return skipCount + len(stmts) - i, declinedCount, err
+				}
+				log.Errorf("Skipping %s %s: pre-push-hook failed: %s\n", t.Instance, t.SchemaName, err)
+				return skipCount + len(stmts) - i, declinedCount, nil
+			}
+		}
 		if !t.Dir.Config.GetBool("dry-run") {
-			if err := stmt.Execute(); err != nil {
+			var err error
+			if throttler != nil {
+				err = throttler.wait()
+			}
+			if err == nil {
+				err = stmt.Execute()
+			}
+			if err != nil {
 				log.Errorf("Error running SQL statement on %s %s: %s\nFull SQL statement: %s%s", t.Instance, t.SchemaName, err, stmt.Statement(), stmt.ClientState().Delimiter)
 				skipped := len(stmts) - i
 				skipCount += skipped
 				if skipped > 1 {
 					log.Warnf("Skipping %d remaining operations for %s %s due to previous error", skipped-1, t.Instance, t.SchemaName)
 				}
-				return
+				return skipCount, declinedCount, nil
 			}
 		}
 	}
-	return
+	return skipCount, declinedCount, nil
 }
 
 // TargetGroup represents a group of Targets that all have the same Instance.
@@ -157,7 +263,9 @@ func instancesForDir(dir *fs.Dir) (instances []*tengo.Instance, skipCount int) {
 		onlyInstance, err := dir.FirstInstance()
 		if onlyInstance == nil && err == nil {
 			log.Warnf("Skipping %s: directory maps to an empty list of instances", dir)
-			if dir.Config.Changed("host-wrapper") {
+			if dir.Config.Changed("host-inventory") {
+				log.Warnf("The host-inventory option controls which hosts this directory maps to, but the cluster %q has no hosts listed for this environment.\n", dir.Config.GetAllowEnvVar("host"))
+			} else if dir.Config.Changed("host-wrapper") {
 				log.Warnf("The host-wrapper option controls which hosts this directory maps to, but the executed script returned no hostnames.\n")
 			}
 			return nil, 0
@@ -176,7 +284,9 @@ func instancesForDir(dir *fs.Dir) (instances []*tengo.Instance, skipCount int) {
 		return nil, 1
 	} else if len(rawInstances) == 0 {
 		log.Warnf("Skipping %s: directory maps to an empty list of instances", dir)
-		if dir.Config.Changed("host-wrapper") {
+		if dir.Config.Changed("host-inventory") {
+			log.Warnf("The host-inventory option controls which hosts this directory maps to, but the cluster %q has no hosts listed for this environment.\n", dir.Config.GetAllowEnvVar("host"))
+		} else if dir.Config.Changed("host-wrapper") {
 			log.Warnf("The host-wrapper option controls which hosts this directory maps to, but the executed script returned no hostnames.\n")
 		}
 		return nil, 0
@@ -282,17 +392,20 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 
 // TargetGroupsForDir returns a slice of TargetGroups (Target values grouped by
 // Instance) for this dir and its subdirs, and count of directories that were
-// skipped due to non-fatal errors.
+// skipped due to non-fatal errors. Groups are ordered by each instance's first
+// appearance in the dir tree.
 func TargetGroupsForDir(dir *fs.Dir) ([]TargetGroup, int) {
 	targets, skipCount := TargetsForDir(dir, 5)
-	byInst := make(map[string]TargetGroup)
+	groupIndex := make(map[string]int)
+	groups := []TargetGroup{}
 	for _, t := range targets {
 		key := t.Instance.String()
-		byInst[key] = append(byInst[key], t)
-	}
-	groups := []TargetGroup{}
-	for _, tg := range byInst {
-		groups = append(groups, tg)
+		if n, ok := groupIndex[key]; ok {
+			groups[n] = append(groups[n], t)
+		} else {
+			groupIndex[key] = len(groups)
+			groups = append(groups, TargetGroup{t})
+		}
 	}
 	return groups, skipCount
 }
@@ -1,6 +1,7 @@
 package applier
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -237,6 +238,8 @@ func (s ApplierIntegrationSuite) TestTargetGroupsForDir(t *testing.T) {
 	}
 	if len(seen) != 2 {
 		t.Errorf("Expected to see 2 target groups, instead found %d", len(seen))
+	} else if groups[0][0].Instance.String() != s.d[0].Instance.String() {
+		t.Errorf("Expected first target group to use first instance %s, instead found %s", s.d[0].Instance, groups[0][0].Instance)
 	}
 
 	// SQL syntax error in testdata/applier/sqlerror/one/bad.sql should cause one/
@@ -272,15 +275,49 @@ func getBaseConfig(t *testing.T, cliFlags string) *mybase.Config {
 	cmd.AddOption(mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"))
 	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple instances or schemas, just run against the first per dir"))
 	cmd.AddOption(mybase.BoolOption("exact-match", 0, false, "Follow *.sql table definitions exactly, even for differences with no functional impact"))
+	cmd.AddOption(mybase.StringOption("only-table", 0, "", "Restrict DDL to tables matching regex"))
+	cmd.AddOption(mybase.StringOption("only-proc", 0, "", "Restrict DDL to stored procedures matching regex"))
+	cmd.AddOption(mybase.StringOption("only-func", 0, "", "Restrict DDL to functions matching regex"))
 	cmd.AddOption(mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"))
 	cmd.AddOption(mybase.BoolOption("brief", 'q', false, "<overridden by diff command>").Hidden())
+	cmd.AddOption(mybase.BoolOption("json", 0, false, "<overridden by diff command>").Hidden())
 	cmd.AddOption(mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE; see manual for template vars"))
 	cmd.AddOption(mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore --alter-wrapper for tables smaller than this size in bytes"))
+	cmd.AddOption(mybase.BoolOption("compare-metadata", 0, false, "For stored programs, detect changes to creation-time sql_mode or DB collation"))
+	cmd.AddOption(mybase.BoolOption("alter-validate-virtual", 0, false, "Apply a WITH VALIDATION clause to ALTER TABLEs affecting virtual columns"))
+	cmd.AddOption(mybase.StringOption("partitioning", 0, "keep", `Specify handling of partitioning status on the database side (valid values: "keep", "remove", "modify")`))
 	cmd.AddOption(mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`))
 	cmd.AddOption(mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant", "nocopy")`))
 	cmd.AddOption(mybase.StringOption("ddl-wrapper", 'X', "", "Like --alter-wrapper, but applies to all DDL types (CREATE, DROP, ALTER)"))
 	cmd.AddOption(mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"))
+	cmd.AddOption(mybase.StringOption("safe-below-rows", 0, "0", "Always permit destructive operations for tables with fewer than this many rows"))
+	cmd.AddOption(mybase.BoolOption("safe-narrowing-check", 0, false, "Permit narrowing column types if querying the table confirms all existing values fit"))
+	cmd.AddOption(mybase.StringOption("safe-narrowing-max-rows", 0, "1000000", "With --safe-narrowing-check, skip querying tables with more than this many rows"))
+	cmd.AddOption(mybase.StringOption("blocking-trx-min-time", 0, "0", "Before direct ALTER or DROP TABLE, check for transactions/queries on the table running this many seconds"))
+	cmd.AddOption(mybase.StringOption("blocking-trx-action", 0, "abort", `Handling of sessions found by --blocking-trx-min-time (valid values: "abort", "wait", "kill")`))
+	cmd.AddOption(mybase.StringOption("blocking-trx-max-wait", 0, "300", "With --blocking-trx-action=wait, max seconds to wait before aborting"))
+	cmd.AddOption(mybase.StringOption("replica-lag-threshold", 0, "0", "Before each DDL statement, pause while any replica is lagging more than this many seconds"))
+	cmd.AddOption(mybase.StringOption("replica-hosts", 0, "", "Comma-separated list of replicas to check for replica-lag-threshold"))
+	cmd.AddOption(mybase.StringOption("replica-lag-max-wait", 0, "3600", "With --replica-lag-threshold, max seconds to pause before aborting"))
+	cmd.AddOption(mybase.StringOption("ddl-max-attempts", 0, "1", "Max attempts for table DDL failing with a lock wait timeout or deadlock"))
+	cmd.AddOption(mybase.StringOption("ddl-retry-backoff", 0, "5", "With --ddl-max-attempts, seconds to wait before first retry, doubling for each subsequent retry"))
+	cmd.AddOption(mybase.StringOption("drop-mode", 0, "drop", "Handling of tables removed from the filesystem"))
+	cmd.AddOption(mybase.StringOption("trash-schema", 0, "_skeema_trash", "With --drop-mode=trash, schema to move removed tables into"))
+	cmd.AddOption(mybase.StringOption("deprecation-grace-period", 0, "7d", "Permit dropping columns deprecated by a previous push at least this long ago"))
+	cmd.AddOption(mybase.StringOption("backup-dir", 0, "", "Before destructive DDL on a non-empty table, export the affected data to a compressed file in this dir"))
+	cmd.AddOption(mybase.StringOption("backup-max-size", 0, "1G", "With --backup-dir, prevent destructive DDL if the data to export exceeds this size in bytes"))
+	cmd.AddOption(mybase.BoolOption("interactive", 0, false, "Display planned DDL and prompt for confirmation before running it"))
+	cmd.AddOption(mybase.StringOption("interactive-mode", 0, "target", "With --interactive, prompt once per target or per statement"))
+	cmd.AddOption(mybase.StringOption("alter-copy-max-size", 0, "0", "Prevent ALTERs expected to use ALGORITHM=COPY on tables of at least this size in bytes"))
+	cmd.AddOption(mybase.StringOption("pre-push-hook", 0, "", "External command to run before pushing changes to each instance and schema; see manual for template vars"))
+	cmd.AddOption(mybase.StringOption("post-push-hook", 0, "", "External command to run after pushing changes to each instance and schema; see manual for template vars"))
 	cmd.AddOption(mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of instances concurrently"))
+	cmd.AddOption(mybase.StringOption("concurrent-schemas", 0, "1", "Perform operations on this number of schemas concurrently per instance"))
+	cmd.AddOption(mybase.StringOption("rollout-canary", 0, "", "Push to this number (or percentage) of instances first, before any others"))
+	cmd.AddOption(mybase.StringOption("rollout-wave-size", 0, "", "With --rollout-canary, push to remaining instances in waves of this number or percentage"))
+	cmd.AddOption(mybase.StringOption("rollout-pause", 0, "0", "With --rollout-canary, seconds to pause between waves, or \"prompt\" to ask before continuing"))
+	cmd.AddOption(mybase.BoolOption("rollout-verify", 0, true, "With --rollout-canary, re-introspect each instance after pushing to confirm no differences remain"))
+	cmd.AddOption(mybase.StringOption("rollout-health-check", 0, "", "With --rollout-canary, external command to run after pushing to each instance"))
 	cmd.AddArg("environment", "production", false)
 	util.AddGlobalOptions(cmd)
 	workspace.AddCommandOptions(cmd)
@@ -309,3 +346,59 @@ func setupHostList(t *testing.T, instances ...*tengo.Instance) {
 		fs.RemoveTestDirectory(t, "testdata/.scratch")
 	})
 }
+
+func (s ApplierIntegrationSuite) TestApplyTargetGroupConcurrent(t *testing.T) {
+	setupHostList(t, s.d[0].Instance)
+	dir := getDir(t, "testdata/simple", "--concurrent-schemas=2")
+	groups, skipCount := TargetGroupsForDir(dir)
+	if len(groups) != 1 || len(groups[0]) != 2 || skipCount != 0 {
+		t.Fatalf("Unexpected result from TargetGroupsForDir: %+v, %d", groups, skipCount)
+	}
+	result, err := ApplyTargetGroup(context.Background(), groups[0], NewPrinter(dir.Config), 2)
+	if err != nil || result.SkipCount > 0 || !result.Differences {
+		t.Fatalf("Unexpected result from ApplyTargetGroup: %+v, %v", result, err)
+	}
+	for _, target := range groups[0] {
+		if err := verifyTargetApplied(target); err != nil {
+			t.Errorf("Unexpected error from verifyTargetApplied: %v", err)
+		}
+	}
+
+	// Each concurrent worker must have used a distinct workspace
+	slots := map[int]bool{groups[0][0].workspaceSlot: true, groups[0][1].workspaceSlot: true}
+	if len(slots) != 2 {
+		t.Errorf("Expected targets to use distinct workspace slots, instead found %v", slots)
+	}
+	vopts, err := VerifierOptionsForTarget(&Target{Instance: s.d[0].Instance, Dir: dir, workspaceSlot: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error from VerifierOptionsForTarget: %v", err)
+	} else if vopts.WorkspaceOptions.SchemaName != "_skeema_tmp_2" {
+		t.Errorf("Unexpected workspace schema name %q", vopts.WorkspaceOptions.SchemaName)
+	}
+}
+
+func (s ApplierIntegrationSuite) TestApplyTargetOnlyTable(t *testing.T) {
+	setupHostList(t, s.d[0].Instance)
+	dir := getDir(t, "testdata/simple", "--only-table=^bar$")
+	groups, skipCount := TargetGroupsForDir(dir)
+	if len(groups) != 1 || len(groups[0]) != 2 || skipCount != 0 {
+		t.Fatalf("Unexpected result from TargetGroupsForDir: %+v, %d", groups, skipCount)
+	}
+	result, err := ApplyTargetGroup(context.Background(), groups[0], NewPrinter(dir.Config), 1)
+	if err != nil || result.SkipCount > 0 {
+		t.Fatalf("Unexpected result from ApplyTargetGroup: %+v, %v", result, err)
+	}
+	for _, target := range groups[0] {
+		// Verification is scoped to the same only-* options, so should succeed
+		if err := verifyTargetApplied(target); err != nil {
+			t.Errorf("Unexpected error from verifyTargetApplied: %v", err)
+		}
+		tableName := map[string]string{"one": "foo", "two": "bar"}[target.SchemaName]
+		expectExists := (tableName == "bar")
+		if schema, err := s.d[0].Instance.Schema(target.SchemaName); err != nil {
+			t.Errorf("Unexpected error from Schema: %v", err)
+		} else if schema.HasTable(tableName) != expectExists {
+			t.Errorf("Expected table %s.%s exists=%t, but found otherwise", target.SchemaName, tableName, expectExists)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package applier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// replicaThrottler pauses execution between DDL statements whenever any
+// replica of the target instance is lagging too far behind. This prevents a
+// sequence of large ALTERs from compounding replication lag.
+type replicaThrottler struct {
+	replicas     []*tengo.Instance
+	maxLag       int64         // pause while any replica is lagging more than this many seconds
+	maxWait      time.Duration // return an error if still lagging after this long; 0 means no limit
+	pollInterval time.Duration
+}
+
+// replicaThrottlerForTarget returns a replicaThrottler based on the target's
+// directory configuration, or nil if throttling is not enabled. Replicas are
+// obtained from the replica-hosts option, or discovered from the target
+// instance if that option is not set.
+func replicaThrottlerForTarget(t *Target) (*replicaThrottler, error) {
+	rt, err := replicaThrottlerForDir(t.Dir.Config)
+	if rt == nil || err != nil {
+		return nil, err
+	}
+	hosts := t.Dir.Config.GetSliceAllowEnvVar("replica-hosts", ',', true)
+	if len(hosts) == 0 {
+		if hosts, err = t.Instance.ReplicaHosts(); err != nil {
+			return nil, fmt.Errorf("Unable to discover replicas of %s: %w", t.Instance, err)
+		} else if len(hosts) == 0 {
+			log.Warnf("Unable to discover any replicas of %s for replica-lag-threshold. Use replica-hosts to list replicas explicitly, or configure report_host on each replica.", t.Instance)
+			return nil, nil
+		}
+	}
+	for _, host := range hosts {
+		replica, err := replicaInstance(t.Instance, host)
+		if err != nil {
+			return nil, ConfigError(fmt.Sprintf("Invalid replica-hosts value %q: %s", host, err))
+		}
+		rt.replicas = append(rt.replicas, replica)
+	}
+	return rt, nil
+}
+
+// replicaThrottlerForDir returns a replicaThrottler without any replicas yet,
+// based solely on configuration. It returns nil if throttling is not enabled
+// or is not relevant for the command.
+func replicaThrottlerForDir(config *mybase.Config) (*replicaThrottler, error) {
+	if config.GetBool("dry-run") || !config.Changed("replica-lag-threshold") {
+		return nil, nil
+	}
+	maxLag, err := config.GetInt("replica-lag-threshold")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	} else if maxLag < 0 {
+		return nil, ConfigError("replica-lag-threshold cannot be negative")
+	}
+	maxWait, err := config.GetInt("replica-lag-max-wait")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	} else if maxWait < 0 {
+		return nil, ConfigError("replica-lag-max-wait cannot be negative")
+	}
+	return &replicaThrottler{
+		maxLag:       int64(maxLag),
+		maxWait:      time.Duration(maxWait) * time.Second,
+		pollInterval: 5 * time.Second,
+	}, nil
+}
+
+// replicaInstance returns an Instance for a replica of source, at the supplied
+// host, which may optionally include a port. The replica is accessed using the
+// same user, password, connection params, and network (e.g. SSH tunnel) as the
+// source.
+func replicaInstance(source *tengo.Instance, host string) (*tengo.Instance, error) {
+	host, port, err := tengo.SplitHostOptionalPort(host)
+	if err != nil {
+		return nil, err
+	} else if port == 0 {
+		port = source.Port
+	}
+	userAndPass := source.User
+	if source.Password != "" {
+		userAndPass += ":" + source.Password
+	}
+	network := "tcp"
+	if sourceConfig, err := mysql.ParseDSN(source.BaseDSN); err == nil && sourceConfig.Net != "unix" {
+		network = sourceConfig.Net
+	}
+	dsn := fmt.Sprintf("%s@%s(%s:%d)/?%s", userAndPass, network, host, port, source.BuildParamString(""))
+	return util.NewInstance("mysql", dsn)
+}
+
+// wait blocks until all replicas have replication lag at or below the
+// threshold. An error is returned if a replica cannot be checked, or if the
+// lag persists beyond the configured max wait.
+func (rt *replicaThrottler) wait() error {
+	start := time.Now()
+	var warned bool
+	for {
+		var lagging []string
+		for _, replica := range rt.replicas {
+			lag, running, err := replica.ReplicationLag()
+			if err != nil {
+				return fmt.Errorf("Unable to check replication lag on %s: %w", replica, err)
+			} else if !running {
+				lagging = append(lagging, fmt.Sprintf("%s (replication not running)", replica))
+			} else if lag > rt.maxLag {
+				lagging = append(lagging, fmt.Sprintf("%s (%d seconds behind)", replica, lag))
+			}
+		}
+		if len(lagging) == 0 {
+			if warned {
+				log.Infof("Replication lag is now below %d seconds after waiting %s; resuming", rt.maxLag, time.Since(start).Round(time.Second))
+			}
+			return nil
+		}
+		desc := fmt.Sprintf("Replication lag exceeds replica-lag-threshold on %s", strings.Join(lagging, ", "))
+		if rt.maxWait > 0 && time.Since(start) >= rt.maxWait {
+			return fmt.Errorf("%s\nGiving up after waiting %s (replica-lag-max-wait)", desc, rt.maxWait)
+		}
+		if !warned {
+			log.Warnf("%s\nPausing until lag is at most %d seconds", desc, rt.maxLag)
+			warned = true
+		} else {
+			log.Debug(desc)
+		}
+		time.Sleep(rt.pollInterval)
+	}
+}
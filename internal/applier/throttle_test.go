@@ -0,0 +1,60 @@
+package applier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestReplicaThrottlerForDir(t *testing.T) {
+	assertThrottler := func(cliFlags string, expectNil, expectErr bool) *replicaThrottler {
+		t.Helper()
+		cfg := getBaseConfig(t, cliFlags)
+		rt, err := replicaThrottlerForDir(cfg)
+		if expectErr != (err != nil) {
+			t.Errorf("With flags %q, expected error=%t, instead found err=%v", cliFlags, expectErr, err)
+		} else if expectNil != (rt == nil) {
+			t.Errorf("With flags %q, expected nil throttler=%t, instead found %+v", cliFlags, expectNil, rt)
+		}
+		return rt
+	}
+
+	assertThrottler("", true, false)
+	assertThrottler("--replica-hosts=foo", true, false)
+	assertThrottler("--replica-lag-threshold=0", true, false)
+	assertThrottler("--replica-lag-threshold=10 --dry-run", true, false)
+	assertThrottler("--replica-lag-threshold=banana", true, true)
+	assertThrottler("--replica-lag-threshold=-1", true, true)
+	assertThrottler("--replica-lag-threshold=10 --replica-lag-max-wait=-1", true, true)
+	if rt := assertThrottler("--replica-lag-threshold=10", false, false); rt != nil {
+		if rt.maxLag != 10 || rt.maxWait != time.Hour {
+			t.Errorf("Unexpected throttler values: %+v", rt)
+		}
+	}
+	if rt := assertThrottler("--replica-lag-threshold=1 --replica-lag-max-wait=0", false, false); rt != nil {
+		if rt.maxLag != 1 || rt.maxWait != 0 {
+			t.Errorf("Unexpected throttler values: %+v", rt)
+		}
+	}
+}
+
+func TestReplicaInstance(t *testing.T) {
+	source, err := tengo.NewInstance("mysql", "root:pw@tcp(1.2.3.4:3307)/?timeout=5s")
+	if err != nil {
+		t.Fatalf("Unexpected error from NewInstance: %v", err)
+	}
+	replica, err := replicaInstance(source, "5.6.7.8")
+	if err != nil {
+		t.Fatalf("Unexpected error from replicaInstance: %v", err)
+	}
+	if replica.Host != "5.6.7.8" || replica.Port != 3307 || replica.User != "root" || replica.Password != "pw" {
+		t.Errorf("Unexpected replica instance: %+v", replica)
+	}
+	if replica, err = replicaInstance(source, "5.6.7.8:3310"); err != nil || replica.Port != 3310 {
+		t.Errorf("Unexpected result from replicaInstance: %+v, %v", replica, err)
+	}
+	if _, err = replicaInstance(source, "5.6.7.8:banana"); err == nil {
+		t.Error("Expected error from invalid port, but err was nil")
+	}
+}
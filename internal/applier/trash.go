@@ -0,0 +1,151 @@
+package applier
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// trashTimestampFormat is the format of the suffix appended to the names of
+// tables moved into the trash schema by drop-mode=trash.
+const trashTimestampFormat = "20060102150405"
+
+// trashSchemaForDir returns the name of the schema that dropped tables should
+// be moved into, or an empty string if drop-mode is not set to "trash".
+func trashSchemaForDir(config *mybase.Config) (string, error) {
+	dropMode, err := config.GetEnum("drop-mode", "drop", "trash")
+	if err != nil {
+		return "", ConfigError(err.Error())
+	} else if dropMode != "trash" {
+		return "", nil
+	}
+	trashSchema := config.Get("trash-schema")
+	if trashSchema == "" {
+		return "", ConfigError("With drop-mode=trash, trash-schema cannot be empty")
+	}
+	return trashSchema, nil
+}
+
+// trashTableName returns the name to use for a table when moving it into the
+// trash schema at time t. The original schema name is included, since the
+// same trash schema is shared by all schemas on an instance. If n is greater
+// than 0, it is included before the timestamp, to disambiguate names that
+// would otherwise be identical. The result is truncated if necessary to fit
+// within the 64 character limit for table names, but the counter and timestamp
+// suffix are always retained.
+func trashTableName(schema, table string, t time.Time, n int) string {
+	suffix := "_" + t.UTC().Format(trashTimestampFormat)
+	if n > 0 {
+		suffix = "_" + strconv.Itoa(n) + suffix
+	}
+	prefix := []rune(schema + "_" + table)
+	if maxLen := 64 - len(suffix); len(prefix) > maxLen {
+		prefix = prefix[:maxLen]
+	}
+	return string(prefix) + suffix
+}
+
+// trashNames tracks table names which have already been used in trash
+// schemas by this process, keyed by instance, trash schema, and lowercased
+// table name.
+var trashNames struct {
+	sync.Mutex
+	used map[string]bool
+}
+
+// uniqueTrashTableName returns a name for moving table into trashSchema on
+// instance at time t, which has not already been returned by a previous call.
+// This prevents collisions between truncated names, or between names which
+// combine schema and table names differently, for tables trashed in the same
+// second.
+func uniqueTrashTableName(instance *tengo.Instance, trashSchema, schema, table string, t time.Time) string {
+	trashNames.Lock()
+	defer trashNames.Unlock()
+	if trashNames.used == nil {
+		trashNames.used = make(map[string]bool)
+	}
+	for n := 0; ; n++ {
+		name := trashTableName(schema, table, t, n)
+		key := fmt.Sprintf("%s\x00%s\x00%s", instance, trashSchema, strings.ToLower(name))
+		if !trashNames.used[key] {
+			trashNames.used[key] = true
+			return name
+		}
+	}
+}
+
+// trashRenameStatement returns a RENAME TABLE statement which moves table from
+// schema into trashSchema, using the supplied new name.
+func trashRenameStatement(schema, table, trashSchema, trashName string) string {
+	return fmt.Sprintf("RENAME TABLE %s.%s TO %s.%s",
+		tengo.EscapeIdentifier(schema),
+		tengo.EscapeIdentifier(table),
+		tengo.EscapeIdentifier(trashSchema),
+		tengo.EscapeIdentifier(trashName),
+	)
+}
+
+// trashConstraintConflicts returns the names of any constraints in table which
+// would retain their names when the table is moved into the trash schema.
+// Foreign key names must be unique within a schema in InnoDB, as must CHECK
+// constraint names in MySQL 8. RENAME TABLE only renames constraints which use
+// the server's auto-generated naming scheme, so a user-supplied name would
+// conflict if the same table from multiple sharded schemas were trashed.
+func trashConstraintConflicts(table *tengo.Table, flavor tengo.Flavor) (names []string) {
+	reAutoFK := regexp.MustCompile(`^` + regexp.QuoteMeta(table.Name) + `_ibfk_\d+$`)
+	for _, fk := range table.ForeignKeys {
+		if !reAutoFK.MatchString(fk.Name) {
+			names = append(names, fk.Name)
+		}
+	}
+	if flavor.IsMySQL() {
+		reAutoCheck := regexp.MustCompile(`^` + regexp.QuoteMeta(table.Name) + `_chk_\d+$`)
+		for _, cc := range table.Checks {
+			if !reAutoCheck.MatchString(cc.Name) {
+				names = append(names, cc.Name)
+			}
+		}
+	}
+	return names
+}
+
+// TrashTime returns the time that a table in the trash schema was moved there,
+// based on the suffix of its name. The returned bool is false if the name does
+// not have a valid suffix.
+func TrashTime(name string) (time.Time, bool) {
+	pos := strings.LastIndexByte(name, '_')
+	if pos < 0 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(trashTimestampFormat, name[pos+1:], time.UTC)
+	return t, err == nil
+}
+
+// TrashTablesBefore returns the names of tables in trashSchema on instance
+// which were moved there prior to cutoff, in sorted order. Tables without a
+// trash timestamp suffix are never returned. If trashSchema does not exist, a
+// nil slice is returned.
+func TrashTablesBefore(instance *tengo.Instance, trashSchema string, cutoff time.Time) ([]string, error) {
+	if exists, err := instance.HasSchema(trashSchema); !exists || err != nil {
+		return nil, err
+	}
+	schema, err := instance.Schema(trashSchema)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, table := range schema.Tables {
+		if t, ok := TrashTime(table.Name); ok && t.Before(cutoff) {
+			names = append(names, table.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
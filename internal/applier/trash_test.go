@@ -0,0 +1,131 @@
+package applier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestTrashTableName(t *testing.T) {
+	ts := time.Date(2026, 10, 16, 13, 4, 5, 0, time.UTC)
+	if actual := trashTableName("product", "users", ts, 0); actual != "product_users_20261016130405" {
+		t.Errorf("Unexpected result from trashTableName: %q", actual)
+	}
+	if actual, ok := TrashTime(trashTableName("product", "users", ts, 0)); !ok || !actual.Equal(ts) {
+		t.Errorf("Unexpected result from TrashTime: %s, %t", actual, ok)
+	}
+
+	// Long names are truncated, but retain the timestamp suffix
+	long := trashTableName("product", strings.Repeat("é", 64), ts, 0)
+	if len([]rune(long)) != 64 || !strings.HasSuffix(long, "_20261016130405") {
+		t.Errorf("Unexpected result from trashTableName: %q", long)
+	}
+	if actual, ok := TrashTime(long); !ok || !actual.Equal(ts) {
+		t.Errorf("Unexpected result from TrashTime: %s, %t", actual, ok)
+	}
+
+	for _, name := range []string{"users", "product_users", "product_users_2026", "product_users_20261316130405"} {
+		if _, ok := TrashTime(name); ok {
+			t.Errorf("Expected TrashTime(%q) to return false, but it returned true", name)
+		}
+	}
+
+	// Counter is placed before the timestamp, and is retained when truncating
+	if actual := trashTableName("product", "users", ts, 2); actual != "product_users_2_20261016130405" {
+		t.Errorf("Unexpected result from trashTableName: %q", actual)
+	}
+	long = trashTableName("product", strings.Repeat("x", 64), ts, 1)
+	if len(long) != 64 || !strings.HasSuffix(long, "x_1_20261016130405") {
+		t.Errorf("Unexpected result from trashTableName: %q", long)
+	}
+	if actual, ok := TrashTime(long); !ok || !actual.Equal(ts) {
+		t.Errorf("Unexpected result from TrashTime: %s, %t", actual, ok)
+	}
+
+	expected := "RENAME TABLE `product`.`users` TO `_skeema_trash`.`product_users_20261016130405`"
+	if actual := trashRenameStatement("product", "users", "_skeema_trash", "product_users_20261016130405"); actual != expected {
+		t.Errorf("Unexpected result from trashRenameStatement: %q", actual)
+	}
+}
+
+func TestUniqueTrashTableName(t *testing.T) {
+	ts := time.Date(2026, 10, 16, 13, 4, 5, 0, time.UTC)
+	inst1, _ := tengo.NewInstance("mysql", "root@tcp(db1:3306)/")
+	inst2, _ := tengo.NewInstance("mysql", "root@tcp(db2:3306)/")
+
+	// Two long table names which are identical after truncation
+	base := strings.Repeat("a", 60)
+	name1 := uniqueTrashTableName(inst1, "_skeema_trash", "product", base+"_one", ts)
+	name2 := uniqueTrashTableName(inst1, "_skeema_trash", "product", base+"_two", ts)
+	if name1 == name2 {
+		t.Errorf("Expected unique names, but both were %q", name1)
+	} else if !strings.HasSuffix(name2, "_1_20261016130405") {
+		t.Errorf("Unexpected result from uniqueTrashTableName: %q", name2)
+	}
+
+	// Different schema/table combinations which result in the same name
+	name1 = uniqueTrashTableName(inst1, "_skeema_trash", "a_b", "c", ts)
+	name2 = uniqueTrashTableName(inst1, "_skeema_trash", "a", "b_c", ts)
+	if name1 != "a_b_c_20261016130405" || name2 != "a_b_c_1_20261016130405" {
+		t.Errorf("Unexpected results from uniqueTrashTableName: %q, %q", name1, name2)
+	}
+
+	// Other instances or trash schemas are tracked separately
+	if actual := uniqueTrashTableName(inst2, "_skeema_trash", "a_b", "c", ts); actual != "a_b_c_20261016130405" {
+		t.Errorf("Unexpected result from uniqueTrashTableName: %q", actual)
+	}
+	if actual := uniqueTrashTableName(inst1, "other_trash", "a_b", "c", ts); actual != "a_b_c_20261016130405" {
+		t.Errorf("Unexpected result from uniqueTrashTableName: %q", actual)
+	}
+}
+
+func TestTrashConstraintConflicts(t *testing.T) {
+	table := &tengo.Table{
+		Name: "orders",
+		ForeignKeys: []*tengo.ForeignKey{
+			{Name: "orders_ibfk_1"},
+			{Name: "fk_owner"},
+		},
+		Checks: []*tengo.Check{
+			{Name: "orders_chk_1"},
+			{Name: "positive_total"},
+		},
+	}
+	if actual := trashConstraintConflicts(table, tengo.FlavorMySQL80); !reflect.DeepEqual(actual, []string{"fk_owner", "positive_total"}) {
+		t.Errorf("Unexpected result from trashConstraintConflicts: %v", actual)
+	}
+	// MariaDB check constraint names are only unique per table
+	if actual := trashConstraintConflicts(table, tengo.FlavorMariaDB105); !reflect.DeepEqual(actual, []string{"fk_owner"}) {
+		t.Errorf("Unexpected result from trashConstraintConflicts: %v", actual)
+	}
+	table.ForeignKeys, table.Checks = table.ForeignKeys[:1], table.Checks[:1]
+	if actual := trashConstraintConflicts(table, tengo.FlavorMySQL80); len(actual) > 0 {
+		t.Errorf("Expected no conflicts for server-generated constraint names, instead found %v", actual)
+	}
+}
+
+func TestTrashSchemaForDir(t *testing.T) {
+	cases := map[string]string{
+		"":                                   "",
+		"--drop-mode=drop":                   "",
+		"--drop-mode=trash":                  "_skeema_trash",
+		"--drop-mode=TRASH --trash-schema=x": "x",
+	}
+	for flags, expected := range cases {
+		dir := getDir(t, "testdata/simple", flags)
+		if actual, err := trashSchemaForDir(dir.Config); err != nil || actual != expected {
+			t.Errorf("With flags %q, unexpected result from trashSchemaForDir: %q, %v", flags, actual, err)
+		}
+	}
+	for _, flags := range []string{"--drop-mode=whatever", "--drop-mode=trash --trash-schema=''"} {
+		dir := getDir(t, "testdata/simple", flags)
+		if _, err := trashSchemaForDir(dir.Config); err == nil {
+			t.Errorf("With flags %q, expected error from trashSchemaForDir, but err was nil", flags)
+		} else if _, ok := err.(ConfigError); !ok {
+			t.Errorf("With flags %q, expected error to be a ConfigError, instead found %T", flags, err)
+		}
+	}
+}
@@ -31,6 +31,11 @@ func VerifierOptionsForTarget(t *Target) (opts VerifierOptions, err error) {
 		DefaultCollation:    t.Dir.Config.Get("default-collation"),
 	}
 	opts.WorkspaceOptions, err = workspace.OptionsForDir(t.Dir, t.Instance)
+	if t.workspaceSlot > 0 {
+		// Concurrent workers on the same instance each need their own workspace
+		// schema, since workspaces are exclusively locked by name
+		opts.WorkspaceOptions.SchemaName = fmt.Sprintf("%s_%d", opts.WorkspaceOptions.SchemaName, t.workspaceSlot+1)
+	}
 	return
 }
 
@@ -99,6 +99,13 @@ func updateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) err
 			}
 		}
 
+		// Column deprecation markers are not written to the filesystem; instead,
+		// deprecated columns are annotated with an inline comment, retaining any
+		// annotations from the filesystem create
+		if key.Type == tengo.ObjectTypeTable {
+			canonicalCreate = tengo.AnnotateDeprecatedColumns(canonicalCreate, fsCreate)
+		}
+
 		newStmt := tengo.ParseStatementInString(canonicalCreate)
 		if newStmt.Type != tengo.StatementTypeCreate || newStmt.ObjectKey() != key {
 			log.Errorf("%s is unexpectedly not able to be parsed by Skeema\nPlease file an issue report at https://github.com/skeema/skeema/issues with the problematic statement, redacting sensitive portions if necessary:\n%s", key, canonicalCreate)
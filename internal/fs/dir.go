@@ -179,9 +179,19 @@ func (dir *Dir) CreateOptionFile(optionFile *mybase.File) (err error) {
 
 // Hostnames returns 0 or more hosts that the directory maps to. This properly
 // handles the host option being set to a comma-separated list of multiple
-// hosts, or the host-wrapper option being used to shell out to an external
-// script to obtain hosts.
+// hosts, the host-wrapper option being used to shell out to an external
+// script to obtain hosts, or the host-inventory option being used to look up
+// hosts in a file.
 func (dir *Dir) Hostnames() ([]string, error) {
+	if cluster, err := dir.InventoryCluster(); err != nil {
+		return nil, err
+	} else if cluster != nil {
+		hosts := make([]string, len(cluster.Hosts))
+		for n, ih := range cluster.Hosts {
+			hosts[n] = ih.Address()
+		}
+		return hosts, nil
+	}
 	if dir.Config.Changed("host-wrapper") {
 		variables := map[string]string{
 			"HOST":        dir.Config.GetAllowEnvVar("host"),
@@ -210,6 +220,22 @@ func (dir *Dir) Port() (int, bool) {
 	return intValue, dir.Config.Supplied("port")
 }
 
+// PathOption returns the value of the supplied option, interpreted as a
+// filesystem path. If the value is a relative path which was set in an option
+// file, it is resolved relative to that option file's directory, rather than
+// the working directory. Relative paths supplied on the command-line are
+// returned as-is. An empty string is returned if the option has no value.
+func (dir *Dir) PathOption(name string) string {
+	value := dir.Config.Get(name)
+	if value == "" || filepath.IsAbs(value) {
+		return value
+	}
+	if file, ok := dir.Config.Source(name).(*mybase.File); ok {
+		return filepath.Join(file.Dir, value)
+	}
+	return value
+}
+
 // FileFor returns a SQLFile associated with the supplied keyer. If keyer is a
 // *tengo.Statement with non-empty File field, that path will be used as-is.
 // Otherwise, FileFor returns the default location for the supplied keyer based
@@ -279,32 +305,63 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 	}
 
 	// Before looping over hostnames, do a single lookup of user, password,
-	// connect-options, port, socket.
+	// connect-options, port, socket. (With password-command, the password is
+	// instead looked up separately for each host.)
 	user := dir.Config.GetAllowEnvVar("user")
-	password, err := dir.Password(hosts...)
-	if err != nil {
-		return nil, err // for example, need interactive password but STDIN isn't a TTY
-	}
-	var userAndPass string
-	if password == "" {
-		userAndPass = user
-	} else {
-		userAndPass = user + ":" + password
+	passwordPerHost := dir.Config.Get("password-command") != ""
+	var password string
+	if !passwordPerHost {
+		if password, err = dir.Password(hosts...); err != nil {
+			return nil, err // for example, need interactive password but STDIN isn't a TTY
+		}
 	}
 	params, err := dir.InstanceDefaultParams()
 	if err != nil {
 		return nil, ConfigErrorf("Invalid connection options: %w", err)
 	}
+	cluster, _ := dir.InventoryCluster() // error already checked by dir.Hostnames()
 	portValue, portWasSupplied := dir.Port()
 	socketValue := dir.Config.GetAllowEnvVar("socket")
 	socketWasSupplied := dir.Config.Supplied("socket")
 
+	// If an SSH bastion is configured, TCP connections are routed through it, and
+	// sockets cannot be used
+	tcpNet := "tcp"
+	if sshHost := dir.Config.GetAllowEnvVar("ssh-host"); sshHost != "" {
+		tcpNet, err = util.RegisterSSHTunnel(util.SSHTunnelConfig{
+			Host:           sshHost,
+			User:           dir.Config.GetAllowEnvVar("ssh-user"),
+			KeyFile:        dir.PathOption("ssh-key"),
+			KnownHostsFile: dir.PathOption("ssh-known-hosts"),
+			ForwardAgent:   dir.Config.GetBool("ssh-forward-agent"),
+		})
+		if err != nil {
+			return nil, ConfigErrorf("Invalid SSH tunnel configuration: %w", err)
+		}
+	}
+
 	// For each hostname, construct a DSN and use it to create an Instance
 	var instances []*tengo.Instance
 	for _, host := range hosts {
 		var net, addr string
 		thisPortValue := portValue
-		if host == "localhost" && (socketWasSupplied || !portWasSupplied) {
+		thisPassword := password
+		thisParams := params
+		if cluster != nil {
+			if thisParams, err = dir.inventoryParams(cluster.hostByAddress(host)); err != nil {
+				return nil, ConfigErrorf("Invalid connection options: %w", err)
+			}
+		}
+		if passwordPerHost {
+			if thisPassword, err = dir.Password(host); err != nil {
+				return nil, err
+			}
+		}
+		userAndPass := user
+		if thisPassword != "" {
+			userAndPass = user + ":" + thisPassword
+		}
+		if host == "localhost" && tcpNet == "tcp" && (socketWasSupplied || !portWasSupplied) {
 			net, addr = "unix", socketValue
 		} else {
 			splitHost, splitPort, err := tengo.SplitHostOptionalPort(host)
@@ -318,12 +375,12 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 				host = splitHost
 				thisPortValue = splitPort
 			}
-			net, addr = "tcp", fmt.Sprintf("%s:%d", host, thisPortValue)
+			net, addr = tcpNet, fmt.Sprintf("%s:%d", host, thisPortValue)
 		}
-		dsn := fmt.Sprintf("%s@%s(%s)/?%s", userAndPass, net, addr, params)
+		dsn := fmt.Sprintf("%s@%s(%s)/?%s", userAndPass, net, addr, thisParams)
 		instance, err := util.NewInstance("mysql", dsn)
 		if err != nil {
-			if password != "" {
+			if thisPassword != "" {
 				safeUserPass := user + ":*****"
 				dsn = strings.Replace(dsn, userAndPass, safeUserPass, 1)
 			}
@@ -415,8 +472,8 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 		variables := map[string]string{
 			"HOST":        instance.Host,
 			"PORT":        strconv.Itoa(instance.Port),
-			"USER":        dir.Config.GetAllowEnvVar("user"),
-			"PASSWORD":    dir.Config.GetAllowEnvVar("password"),
+			"USER":        instance.User,
+			"PASSWORD":    instance.Password,
 			"ENVIRONMENT": dir.Config.Get("environment"),
 			"DIRNAME":     dir.BaseName(),
 			"DIRPATH":     dir.Path,
@@ -429,8 +486,14 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 			return nil, err
 		}
 	} else if schemaValue == "*" || looksLikeRegex(schemaValue) {
-		// This automatically already filters out information_schema, performance_schema, sys, test, mysql
-		if names, err = instance.SchemaNames(); err != nil {
+		// If the host-inventory file lists schemas for this instance, use those;
+		// otherwise query the instance, which automatically already filters out
+		// information_schema, performance_schema, sys, test, mysql
+		if ih, err := dir.inventoryHost(instance.Host, instance.Port); err != nil {
+			return nil, err
+		} else if ih != nil && ih.Schemas != nil {
+			names = append([]string{}, ih.Schemas...)
+		} else if names, err = instance.SchemaNames(); err != nil {
 			return nil, err
 		}
 		// Schema name list must be sorted so that TargetsForDir with
@@ -439,18 +502,8 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 		// user in config.)
 		sort.Strings(names)
 		// Now handle regex filtering, if requested
-		if schemaValue != "*" {
-			re, err := regexp.Compile(schemaValue[1 : len(schemaValue)-1])
-			if err != nil {
-				return nil, ConfigError{err}
-			}
-			keepNames := []string{}
-			for _, name := range names {
-				if re.MatchString(name) {
-					keepNames = append(keepNames, name)
-				}
-			}
-			names = keepNames
+		if names, err = filterSchemaValue(names, schemaValue); err != nil {
+			return nil, err
 		}
 	} else {
 		names = dir.Config.GetSliceAllowEnvVar("schema", ',', true)
@@ -480,6 +533,25 @@ func looksLikeRegex(input string) bool {
 	return len(input) > 2 && input[0] == '/' && input[len(input)-1] == '/'
 }
 
+// filterSchemaValue returns the subset of names matching schemaValue, which
+// must be either "*" or a regular expression wrapped in forward slashes.
+func filterSchemaValue(names []string, schemaValue string) ([]string, error) {
+	if schemaValue == "*" {
+		return names, nil
+	}
+	re, err := regexp.Compile(schemaValue[1 : len(schemaValue)-1])
+	if err != nil {
+		return nil, ConfigError{err}
+	}
+	keepNames := []string{}
+	for _, name := range names {
+		if re.MatchString(name) {
+			keepNames = append(keepNames, name)
+		}
+	}
+	return keepNames, nil
+}
+
 func filterSchemaNames(names []string, ignoreSchema *regexp.Regexp) []string {
 	systemSchemas := map[string]bool{
 		"information_schema": true,
@@ -530,6 +602,13 @@ func (dir *Dir) HasSchema() bool {
 // will automatically manipulate a few params whenever querying
 // information_schema or running SHOW CREATE.
 func (dir *Dir) InstanceDefaultParams() (string, error) {
+	return dir.instanceParams(nil)
+}
+
+// instanceParams returns a param string in the same manner as
+// InstanceDefaultParams, but with any connect-options in overrides taking
+// precedence over the dir's connect-options.
+func (dir *Dir) instanceParams(overrides map[string]string) (string, error) {
 	banned := map[string]bool{
 		// go-sql-driver/mysql special params that should not be overridden
 		"allowallfiles":     true,
@@ -552,6 +631,9 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	if err != nil {
 		return "", ConfigError{err}
 	}
+	for name, value := range overrides {
+		options[name] = value
+	}
 
 	v := url.Values{}
 
@@ -561,27 +643,18 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	v.Set("writeTimeout", "5s")
 
 	// Prefer TLS, but not during integration testing
-	sslMode := "preferred"
-	if dir.Config.Supplied("ssl-mode") {
-		sslMode, err = dir.Config.GetEnum("ssl-mode", "disabled", "preferred", "required")
-		if err != nil {
-			return "", ConfigError{err}
-		} else if sslMode == "disabled" {
-			sslMode = "false" // driver uses "false" to mean mysql ssl-mode=disabled
-		} else if sslMode == "required" {
-			sslMode = "skip-verify" // driver uses "skip-verify" to mean mysql ssl-mode=required
-		}
-	} else if dir.Config.IsTest {
-		sslMode = "false"
+	tlsParam, err := dir.tlsParam()
+	if err != nil {
+		return "", err
 	}
-	v.Set("tls", sslMode)
+	v.Set("tls", tlsParam)
 
 	// Set values from connect-options
 	for name, value := range options {
 		if banned[strings.ToLower(name)] {
 			return "", ConfigErrorf("connect-options is not allowed to contain %s", name)
 		}
-		if name == "tls" && dir.Config.Supplied("ssl-mode") {
+		if name == "tls" && dir.usesSSLOptions() {
 			return "", ConfigErrorf("connect-options is not allowed to contain %s; use only the newer ssl-mode option instead", name)
 		}
 		v.Set(name, value)
@@ -594,6 +667,69 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	return v.Encode(), nil
 }
 
+// sslOptionNames lists options which configure TLS, other than ssl-mode.
+var sslOptionNames = []string{"ssl-ca", "ssl-cert", "ssl-key", "ssl-cipher"}
+
+// usesSSLOptions returns true if ssl-mode or any other TLS-related option has
+// been supplied.
+func (dir *Dir) usesSSLOptions() bool {
+	if dir.Config.Supplied("ssl-mode") {
+		return true
+	}
+	for _, name := range sslOptionNames {
+		if dir.Config.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsParam returns the value of the driver's tls param, based on the dir's
+// ssl-mode and related options. As with the MySQL client, supplying ssl-ca
+// without ssl-mode implies ssl-mode=verify_ca. If any certificate, key, or
+// cipher options are used, a custom TLS config is registered with the driver;
+// since the driver does not permit custom TLS configs to fall back to plaintext,
+// ssl-mode=preferred is treated as ssl-mode=required in this situation.
+func (dir *Dir) tlsParam() (string, error) {
+	opts := util.TLSOptions{
+		Mode:     "preferred",
+		CAFile:   dir.PathOption("ssl-ca"),
+		CertFile: dir.PathOption("ssl-cert"),
+		KeyFile:  dir.PathOption("ssl-key"),
+		Ciphers:  dir.Config.Get("ssl-cipher"),
+	}
+	if dir.Config.Supplied("ssl-mode") {
+		var err error
+		opts.Mode, err = dir.Config.GetEnum("ssl-mode", "disabled", "preferred", "required", "verify_ca", "verify_identity")
+		if err != nil {
+			return "", ConfigError{err}
+		}
+	} else if opts.CAFile != "" {
+		opts.Mode = "verify_ca"
+	} else if dir.Config.IsTest {
+		opts.Mode = "disabled"
+	}
+
+	custom := (opts.CAFile != "" || opts.CertFile != "" || opts.KeyFile != "" || opts.Ciphers != "")
+	switch {
+	case opts.Mode == "disabled":
+		return "false", nil // driver uses "false" to mean mysql ssl-mode=disabled
+	case opts.Mode == "preferred" && !custom:
+		return "preferred", nil
+	case opts.Mode == "verify_identity" && !custom:
+		return "true", nil // driver uses "true" to mean mysql ssl-mode=verify_identity
+	case opts.Mode == "required" && !custom:
+		return "skip-verify", nil // driver uses "skip-verify" to mean mysql ssl-mode=required
+	case opts.Mode == "preferred":
+		opts.Mode = "required"
+	}
+	name, err := util.RegisterTLSConfig(opts)
+	if err != nil {
+		return "", ConfigErrorf("Invalid TLS configuration: %w", err)
+	}
+	return name, nil
+}
+
 // Generator returns the version and edition of Skeema used to init or most
 // most recently pull this dir's contents. If this cannot be determined, all
 // results will be zero values.
@@ -631,6 +767,12 @@ func (dir *Dir) Password(hosts ...string) (string, error) {
 	// will expose this as "''" from GetRaw, since GetRaw doesn't remove the quotes
 	// like other Config getters. This allows us to differentiate between "prompt
 	// on STDIN" and "intentionally no/blank password" situations.
+	if dir.Config.Get("password-command") != "" {
+		if len(hosts) == 0 {
+			return "", nil // password-command is only run once hosts are known
+		}
+		return dir.commandPassword(hosts[0])
+	}
 	if dir.Config.GetRaw("password") != "" {
 		return dir.Config.GetAllowEnvVar("password"), nil
 	}
@@ -677,6 +819,36 @@ func (dir *Dir) Password(hosts ...string) (string, error) {
 	return val, nil
 }
 
+// Package-level cache of password-command results, used by Dir.commandPassword()
+var cachedCommandPasswords = make(map[string]string)
+
+// commandPassword returns the output of the password-command option for the
+// supplied host, with any trailing newline removed. Results are cached for the
+// lifetime of the process, keyed by the interpolated command string.
+func (dir *Dir) commandPassword(host string) (string, error) {
+	variables := map[string]string{
+		"HOST":        host,
+		"USER":        dir.Config.GetAllowEnvVar("user"),
+		"ENVIRONMENT": dir.Config.Get("environment"),
+		"DIRNAME":     dir.BaseName(),
+		"DIRPATH":     dir.Path,
+	}
+	shellOut, err := util.NewInterpolatedShellOut(dir.Config.Get("password-command"), variables)
+	if err != nil {
+		return "", ConfigErrorf("Invalid password-command: %w", err)
+	}
+	if cachedPassword, ok := cachedCommandPasswords[shellOut.Command]; ok {
+		return cachedPassword, nil
+	}
+	output, err := shellOut.RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("Unable to obtain password for %s via password-command: %w", host, err)
+	}
+	password := strings.TrimRight(output, "\r\n")
+	cachedCommandPasswords[shellOut.Command] = password
+	return password, nil
+}
+
 // ShouldIgnore returns true if the directory's configuration states that the
 // supplied object/key/statement should be ignored.
 func (dir *Dir) ShouldIgnore(object tengo.ObjectKeyer) bool {
@@ -747,7 +919,7 @@ func (dir *Dir) parseContents() {
 			// quote for example.
 			return
 		}
-		for _, stmt := range sf.Statements {
+		for n, stmt := range sf.Statements {
 			// Statements that are ignored due to ignore-table, ignore-proc, etc are
 			// simply not placed into a LogicalSchema, so that all other logic won't
 			// interact with them
@@ -762,6 +934,11 @@ func (dir *Dir) parseContents() {
 			if dir.ParseError != nil {
 				return
 			}
+			if prev := n - 1; stmt.Type == tengo.StatementTypeCreate && prev >= 0 && sf.Statements[prev].Type == tengo.StatementTypeNoop {
+				if comment := adjacentComment(sf.Statements[prev]); comment != "" {
+					logicalSchemasByName[stmt.Schema()].LeadingComments[stmt.ObjectKey()] = comment
+				}
+			}
 			if stmt.Type == tengo.StatementTypeUnknown {
 				// Statements which could not be parsed, meaning of an unsupported statement
 				// type (e.g. SELECTs), are simply ignored. This is not fatal, since it is
@@ -967,6 +1144,12 @@ func parseOptionFile(dirPath, repoBase string, baseConfig *mybase.Config) (*myba
 	if err := f.Parse(baseConfig); err != nil {
 		return nil, ConfigError{err}
 	}
+
+	// login-path is only read once, prior to crawling any dirs, so it cannot be
+	// overridden on a per-dir basis
+	if f.SomeSectionHasOption("login-path") {
+		return nil, ConfigErrorf("%s: login-path may only be set on the command-line or in a global option file such as ~/.my.cnf", f.Path())
+	}
 	_ = f.UseSection(baseConfig.Get("environment")) // we don't care if the section doesn't exist
 	return f, nil
 }
@@ -1,8 +1,15 @@
 package fs
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -10,6 +17,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/tengo"
@@ -82,6 +90,19 @@ func TestParseDirErrors(t *testing.T) {
 	if _, err := ParseDir("../../testdata/golden/init/mydb/product", cfg); err == nil {
 		t.Error("Expected error from ParseDir(), but instead err is nil")
 	}
+
+	// login-path cannot be set in a dir's option file, or in any parent dir's,
+	// even in an environment section
+	repoPath := t.TempDir()
+	WriteTestFile(t, filepath.Join(repoPath, ".skeema"), "[production]\nlogin-path=prod\n")
+	WriteTestFile(t, filepath.Join(repoPath, "mydb", ".skeema"), "schema=mydb\n")
+	for _, dirPath := range []string{repoPath, filepath.Join(repoPath, "mydb")} {
+		_, err := ParseDir(dirPath, getValidConfig(t))
+		var ce ConfigError
+		if !errors.As(err, &ce) || !strings.Contains(err.Error(), "login-path") {
+			t.Errorf("Expected ParseDir(%q) to return a ConfigError mentioning login-path, instead found %v", dirPath, err)
+		}
+	}
 }
 
 func TestParseDirSymlinks(t *testing.T) {
@@ -313,6 +334,28 @@ func TestParseDirRedundantDelimiter(t *testing.T) {
 	}
 }
 
+func TestParseDirLeadingComments(t *testing.T) {
+	dir := getDir(t, "testdata/leadingcomments")
+	if len(dir.LogicalSchemas) != 1 {
+		t.Fatalf("Expected 1 logical schema, instead found %d", len(dir.LogicalSchemas))
+	}
+	expected := map[string]string{
+		"one":   "-- skeema:lint-ignore pk\n# reason: legacy",
+		"four":  "/* same line */ ",
+		"seven": "-- skeema:lint-ignore",
+	}
+	comments := dir.LogicalSchemas[0].LeadingComments
+	if len(comments) != len(expected) {
+		t.Errorf("Expected %d LeadingComments, instead found %d: %v", len(expected), len(comments), comments)
+	}
+	for name, comment := range expected {
+		key := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: name}
+		if actual := comments[key]; actual != comment {
+			t.Errorf("Unexpected LeadingComments for %s: expected %q, found %q", key, comment, actual)
+		}
+	}
+}
+
 func TestParseDirCreateSelect(t *testing.T) {
 	// This dir contains a CREATE ... SELECT statement, which is explicitly not
 	// supported at this time.
@@ -626,6 +669,29 @@ func TestDirInstances(t *testing.T) {
 	assertInstances(map[string]string{"host": "@@@@@"}, true)
 	assertInstances(map[string]string{"host-wrapper": "`echo {INVALID_VAR}`", "host": "irrelevant"}, true)
 
+	// SSH tunnel: localhost no longer uses a socket, and DSN uses tunnel network
+	insts := assertInstances(map[string]string{"host": "localhost,some.db.host:3307", "ssh-host": "bastion", "ssh-user": "someone", "ssh-known-hosts": "/dev/null"}, false, "localhost:3306", "some.db.host:3307")
+	for _, inst := range insts {
+		if !strings.Contains(inst.BaseDSN, "@ssh-") {
+			t.Errorf("Expected instance DSN to use SSH tunnel network, instead found %s", inst.BaseDSN)
+		}
+	}
+	assertInstances(map[string]string{"host": "some.db.host", "ssh-host": "bastion:banana", "ssh-user": "someone"}, true)
+
+	// password-command is executed separately per host
+	if runtime.GOOS != "windows" {
+		insts = assertInstances(map[string]string{"host": "a.db.host,b.db.host", "password-command": "printf 'pw-{HOST}\n'"}, false, "a.db.host:3306", "b.db.host:3306")
+		for _, inst := range insts {
+			if expected := "pw-" + inst.Host; inst.Password != expected {
+				t.Errorf("Expected password %q, instead found %q", expected, inst.Password)
+			}
+			if strings.Contains(inst.String(), inst.Password) {
+				t.Errorf("Password unexpectedly present in instance string %s", inst)
+			}
+		}
+		assertInstances(map[string]string{"host": "a.db.host", "password-command": "false"}, true)
+	}
+
 	// dynamic hosts via host-wrapper command execution
 	if runtime.GOOS == "windows" {
 		assertInstances(map[string]string{"host-wrapper": "echo '{HOST}:3306'", "host": "some.db.host"}, false, "some.db.host:3306")
@@ -645,10 +711,15 @@ func TestDirInstances(t *testing.T) {
 }
 
 func TestDirInstanceDefaultParams(t *testing.T) {
+	getConfig := func(values map[string]string) *mybase.Config {
+		cmd := mybase.NewCommand("test", "1.0", "this is for testing", nil)
+		util.AddGlobalOptions(cmd)
+		return mybase.NewConfig(&mybase.CommandLine{Command: cmd}, mybase.SimpleSource(values))
+	}
 	getFakeDir := func(connectOptions string) *Dir {
 		return &Dir{
 			Path:   "/tmp/dummydir",
-			Config: mybase.SimpleConfig(map[string]string{"connect-options": connectOptions, "ssl-mode": "preferred"}),
+			Config: getConfig(map[string]string{"connect-options": connectOptions, "ssl-mode": "preferred"}),
 		}
 	}
 
@@ -694,13 +765,14 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 
 	// Test valid ssl-mode values, along with an invalid one and then an invalid combination with tls in connect-options
 	expectTLS := map[string]string{
-		"disabled":  strings.Replace(baseDefaults, "tls=preferred", "tls=false", 1),
-		"preferred": baseDefaults,
-		"required":  strings.Replace(baseDefaults, "tls=preferred", "tls=skip-verify", 1),
+		"disabled":        strings.Replace(baseDefaults, "tls=preferred", "tls=false", 1),
+		"preferred":       baseDefaults,
+		"required":        strings.Replace(baseDefaults, "tls=preferred", "tls=skip-verify", 1),
+		"verify_identity": strings.Replace(baseDefaults, "tls=preferred", "tls=true", 1),
 	}
 	dir := getFakeDir("")
 	for sslMode, expected := range expectTLS {
-		dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": sslMode})
+		dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": sslMode})
 		if parsed, err := url.ParseQuery(expected); err != nil {
 			t.Fatalf("Bad expected value %q: %s", expected, err)
 		} else {
@@ -713,14 +785,82 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 			t.Errorf("Expected ssl-mode=%q to yield default params %q, instead found %q", sslMode, expected, actual)
 		}
 	}
-	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "", "ssl-mode": "invalid-enum"})
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": "invalid-enum"})
 	if _, err := dir.InstanceDefaultParams(); err == nil {
 		t.Error("Expected an error from dir.InstanceDefaultParams() with invalid ssl-mode, but err was nil")
 	}
-	dir.Config = mybase.SimpleConfig(map[string]string{"connect-options": "tls=preferred", "ssl-mode": "required"})
+	dir.Config = getConfig(map[string]string{"connect-options": "tls=preferred", "ssl-mode": "required"})
 	if _, err := dir.InstanceDefaultParams(); err == nil {
 		t.Error("Expected an error from dir.InstanceDefaultParams() with tls in connect-options while also setting ssl-mode, but err was nil")
 	}
+	dir.Config = getConfig(map[string]string{"connect-options": "tls=preferred", "ssl-cipher": "AES128-SHA"})
+	if _, err := dir.InstanceDefaultParams(); err == nil {
+		t.Error("Expected an error from dir.InstanceDefaultParams() with tls in connect-options while also setting ssl-cipher, but err was nil")
+	}
+
+	// Custom TLS configs are registered under a name, which is stable for the
+	// same configuration
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": "verify_ca"})
+	params1, err := dir.InstanceDefaultParams()
+	if err != nil || !strings.Contains(params1, "tls=skeema-") {
+		t.Errorf("Unexpected result from ssl-mode=verify_ca: %q, %v", params1, err)
+	}
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": "verify_ca"})
+	if params2, err := dir.InstanceDefaultParams(); err != nil || params2 != params1 {
+		t.Errorf("Expected same TLS config name for same options, instead found %q vs %q", params1, params2)
+	}
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": "required", "ssl-cipher": "ECDHE-RSA-AES128-GCM-SHA256"})
+	if params3, err := dir.InstanceDefaultParams(); err != nil || params3 == params1 || !strings.Contains(params3, "tls=skeema-") {
+		t.Errorf("Unexpected result from ssl-cipher: %q, %v", params3, err)
+	}
+
+	// Invalid TLS file options
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-ca": "/does/not/exist.pem"})
+	if _, err := dir.InstanceDefaultParams(); err == nil {
+		t.Error("Expected an error from dir.InstanceDefaultParams() with nonexistent ssl-ca, but err was nil")
+	}
+	dir.Config = getConfig(map[string]string{"connect-options": "", "ssl-mode": "required", "ssl-cert": "/does/not/exist.pem"})
+	if _, err := dir.InstanceDefaultParams(); err == nil {
+		t.Error("Expected an error from dir.InstanceDefaultParams() with ssl-cert but no ssl-key, but err was nil")
+	}
+}
+
+func TestDirTLSRelativePaths(t *testing.T) {
+	// Generate a self-signed CA cert in the repo's base dir
+	repoPath := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %v", err)
+	}
+	WriteTestFile(t, filepath.Join(repoPath, "certs", "ca.pem"), string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+
+	// A relative ssl-ca in a parent dir's option file should be resolved relative
+	// to that dir, rather than the subdir or working directory
+	WriteTestFile(t, filepath.Join(repoPath, ".skeema"), "ssl-ca=certs/ca.pem\n")
+	WriteTestFile(t, filepath.Join(repoPath, "mydb", ".skeema"), "host=localhost\n")
+	dir := getDir(t, filepath.Join(repoPath, "mydb"))
+	if params, err := dir.InstanceDefaultParams(); err != nil || !strings.Contains(params, "tls=skeema-") {
+		t.Errorf("Unexpected result from InstanceDefaultParams with relative ssl-ca: %q, %v", params, err)
+	}
+
+	// Relative paths on the command-line are still relative to the working dir
+	dir = getDirWithCLI(t, filepath.Join(repoPath, "mydb"), "--ssl-ca=certs/ca.pem")
+	if _, err := dir.InstanceDefaultParams(); err == nil {
+		t.Error("Expected error from relative ssl-ca on command-line, but err was nil")
+	}
 }
 
 func TestHostDefaultDirName(t *testing.T) {
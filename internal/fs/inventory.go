@@ -0,0 +1,218 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory represents the contents of a host-inventory file, mapping
+// environment names to logical cluster names to clusters. The file may be
+// either YAML or JSON (which is a subset of YAML).
+type Inventory map[string]map[string]*InventoryCluster
+
+// InventoryCluster represents a logical cluster in a host-inventory file. Its
+// Port, ConnectOptions, and Schemas serve as defaults for any hosts which do
+// not specify their own.
+type InventoryCluster struct {
+	Hosts          []*InventoryHost `yaml:"hosts"`
+	Port           int              `yaml:"port"`
+	ConnectOptions string           `yaml:"connect-options"`
+	Schemas        []string         `yaml:"schemas"`
+}
+
+// InventoryHost represents a single database host in a host-inventory file. In
+// the file, a host may be expressed as either a mapping, or just a plain
+// string containing a hostname with optional port.
+type InventoryHost struct {
+	Host           string   `yaml:"host"`
+	Port           int      `yaml:"port"`
+	ConnectOptions string   `yaml:"connect-options"`
+	Schemas        []string `yaml:"schemas"`
+}
+
+// UnmarshalYAML permits an InventoryHost to be expressed as a plain string.
+func (ih *InventoryHost) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&ih.Host)
+	}
+	type plainHost InventoryHost // avoid infinite recursion
+	return node.Decode((*plainHost)(ih))
+}
+
+// Address returns the host's address in host:port format. If no port is
+// specified by the host or its cluster, the address is returned without a
+// port, so that the port option's value applies.
+func (ih *InventoryHost) Address() string {
+	if ih.Port == 0 {
+		return ih.Host
+	}
+	return fmt.Sprintf("%s:%d", ih.Host, ih.Port)
+}
+
+// Package-level cache of parsed inventory files, keyed by absolute path.
+var inventoryCache = struct {
+	sync.Mutex
+	files map[string]Inventory
+}{files: make(map[string]Inventory)}
+
+// ReadInventory parses the host-inventory file at path. Results are cached, so
+// each file is only read once per process. Cluster-level defaults are copied
+// into each host, so callers need not handle inheritance.
+func ReadInventory(path string) (Inventory, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	inventoryCache.Lock()
+	defer inventoryCache.Unlock()
+	if inv, ok := inventoryCache.files[absPath]; ok {
+		return inv, nil
+	}
+	contents, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+	var inv Inventory
+	if err := yaml.Unmarshal(contents, &inv); err != nil {
+		return nil, fmt.Errorf("Unable to parse host-inventory file %s: %w", path, err)
+	}
+	for environment, clusters := range inv {
+		for name, cluster := range clusters {
+			if cluster == nil {
+				clusters[name] = &InventoryCluster{}
+				continue
+			}
+			for _, ih := range cluster.Hosts {
+				if ih == nil || ih.Host == "" {
+					return nil, fmt.Errorf("Host-inventory file %s: cluster %q in environment %q has a host entry without a hostname", path, name, environment)
+				}
+				if splitHost, splitPort, err := tengo.SplitHostOptionalPort(ih.Host); err != nil {
+					return nil, fmt.Errorf("Host-inventory file %s: %w", path, err)
+				} else if splitPort > 0 {
+					if ih.Port > 0 && ih.Port != splitPort {
+						return nil, fmt.Errorf("Host-inventory file %s: port was supplied as %d inside hostname %s but as %d in port field", path, splitPort, ih.Host, ih.Port)
+					}
+					ih.Host, ih.Port = splitHost, splitPort
+				}
+				if ih.Port == 0 {
+					ih.Port = cluster.Port
+				}
+				if ih.ConnectOptions == "" {
+					ih.ConnectOptions = cluster.ConnectOptions
+				}
+				if ih.Schemas == nil {
+					ih.Schemas = cluster.Schemas
+				}
+			}
+		}
+	}
+	inventoryCache.files[absPath] = inv
+	return inv, nil
+}
+
+// InventoryCluster returns the host-inventory cluster for the dir, or nil if
+// the host-inventory option is not in use. The cluster is looked up using the
+// current environment name, and the value of the host option as the logical
+// cluster name. An error is returned if the cluster is not found.
+func (dir *Dir) InventoryCluster() (*InventoryCluster, error) {
+	path := dir.PathOption("host-inventory")
+	if path == "" {
+		return nil, nil
+	}
+	clusterName := dir.Config.GetAllowEnvVar("host")
+	if clusterName == "" {
+		return nil, nil
+	}
+	inv, err := ReadInventory(path)
+	if err != nil {
+		return nil, ConfigError{err}
+	}
+	environment := dir.Config.Get("environment")
+	if cluster, ok := inv[environment][clusterName]; ok {
+		return cluster, nil
+	}
+	return nil, ConfigErrorf("Cluster %q not found in environment %q of host-inventory file %s", clusterName, environment, path)
+}
+
+// inventoryHost returns the host-inventory entry for the supplied host and
+// port, or nil if the host-inventory option is not in use or no entry matches.
+// A port of 0 is interpreted as the dir's port option value.
+func (dir *Dir) inventoryHost(host string, port int) (*InventoryHost, error) {
+	cluster, err := dir.InventoryCluster()
+	if cluster == nil || err != nil {
+		return nil, err
+	}
+	defaultPort, _ := dir.Port()
+	if port == 0 {
+		port = defaultPort
+	}
+	for _, ih := range cluster.Hosts {
+		ihPort := ih.Port
+		if ihPort == 0 {
+			ihPort = defaultPort
+		}
+		if ih.Host == host && ihPort == port {
+			return ih, nil
+		}
+	}
+	return nil, nil
+}
+
+// InventorySchemaNames returns the shard schema names listed for the supplied
+// host and port in the host-inventory file, filtered using the dir's schema
+// option value: "*" returns all listed schemas, and a regular expression
+// returns only matching ones. The ignore-schema option is also applied. A nil
+// slice is returned if the host-inventory option is not in use, the host's
+// entry does not list any schemas, or the schema option is not "*" or a
+// regular expression. A port of 0 is interpreted as the dir's port option
+// value. This method does not connect to the host.
+func (dir *Dir) InventorySchemaNames(host string, port int) ([]string, error) {
+	schemaValue := dir.Config.GetAllowEnvVar("schema")
+	if schemaValue != "*" && !looksLikeRegex(schemaValue) {
+		return nil, nil
+	}
+	ih, err := dir.inventoryHost(host, port)
+	if ih == nil || ih.Schemas == nil || err != nil {
+		return nil, err
+	}
+	names, err := filterSchemaValue(ih.Schemas, schemaValue)
+	if err != nil {
+		return nil, err
+	}
+	ignoreSchema, err := dir.Config.GetRegexp("ignore-schema")
+	if err != nil {
+		return nil, ConfigError{err}
+	}
+	return filterSchemaNames(names, ignoreSchema), nil
+}
+
+// inventoryParams returns the DSN param string for a host. Any connect-options
+// from the host's inventory entry take precedence over the dir's
+// connect-options.
+func (dir *Dir) inventoryParams(ih *InventoryHost) (string, error) {
+	if ih == nil || ih.ConnectOptions == "" {
+		return dir.InstanceDefaultParams()
+	}
+	hostOptions, err := util.SplitConnectOptions(ih.ConnectOptions)
+	if err != nil {
+		return "", ConfigErrorf("Invalid connect-options for %s in host-inventory file: %w", ih.Host, err)
+	}
+	return dir.instanceParams(hostOptions)
+}
+
+// hostByAddress returns the host in cluster matching addr, which should be a
+// value previously returned by InventoryHost.Address.
+func (cluster *InventoryCluster) hostByAddress(addr string) *InventoryHost {
+	for _, ih := range cluster.Hosts {
+		if ih.Address() == addr {
+			return ih
+		}
+	}
+	return nil
+}
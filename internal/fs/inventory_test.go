@@ -0,0 +1,215 @@
+package fs
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+)
+
+const testInventoryYAML = `
+production:
+  users:
+    port: 3307
+    connect-options: "wait_timeout=60"
+    schemas: [users_001, users_002, users_archive]
+    hosts:
+      - users1.db.host
+      - host: users2.db.host
+        port: 3308
+        connect-options: "wait_timeout=30,lock_wait_timeout=5"
+        schemas: [users_003]
+  orders:
+    hosts: ["orders1.db.host:3310", "orders2.db.host"]
+  empty:
+staging:
+  users:
+    hosts: [users.staging.host]
+`
+
+func TestReadInventory(t *testing.T) {
+	dirPath := t.TempDir()
+	yamlPath := filepath.Join(dirPath, "inventory.yaml")
+	WriteTestFile(t, yamlPath, testInventoryYAML)
+	inv, err := ReadInventory(yamlPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadInventory: %v", err)
+	}
+	users := inv["production"]["users"]
+	if len(users.Hosts) != 2 {
+		t.Fatalf("Expected 2 hosts in users cluster, instead found %d", len(users.Hosts))
+	}
+	expected := []InventoryHost{
+		{Host: "users1.db.host", Port: 3307, ConnectOptions: "wait_timeout=60", Schemas: []string{"users_001", "users_002", "users_archive"}},
+		{Host: "users2.db.host", Port: 3308, ConnectOptions: "wait_timeout=30,lock_wait_timeout=5", Schemas: []string{"users_003"}},
+	}
+	for n, ih := range users.Hosts {
+		if !reflect.DeepEqual(*ih, expected[n]) {
+			t.Errorf("Unexpected host entry: expected %+v, found %+v", expected[n], *ih)
+		}
+	}
+	orders := inv["production"]["orders"]
+	if addr0, addr1 := orders.Hosts[0].Address(), orders.Hosts[1].Address(); addr0 != "orders1.db.host:3310" || addr1 != "orders2.db.host" {
+		t.Errorf("Unexpected addresses %q, %q", addr0, addr1)
+	}
+	if empty := inv["production"]["empty"]; empty == nil || len(empty.Hosts) != 0 {
+		t.Errorf("Unexpected value for empty cluster: %+v", empty)
+	}
+
+	// Cached result should be returned, even if file changes
+	WriteTestFile(t, yamlPath, "not: [valid")
+	if inv2, err := ReadInventory(yamlPath); err != nil || inv2["staging"]["users"] == nil {
+		t.Errorf("Expected cached inventory to be returned, instead found %+v, %v", inv2, err)
+	}
+
+	// JSON is also supported
+	jsonPath := filepath.Join(dirPath, "inventory.json")
+	WriteTestFile(t, jsonPath, `{"production": {"main": {"hosts": ["a.db.host", {"host": "b.db.host", "port": 3307}]}}}`)
+	if inv, err := ReadInventory(jsonPath); err != nil {
+		t.Errorf("Unexpected error from ReadInventory: %v", err)
+	} else if hosts := inv["production"]["main"].Hosts; len(hosts) != 2 || hosts[1].Address() != "b.db.host:3307" {
+		t.Errorf("Unexpected hosts from JSON inventory: %+v", hosts)
+	}
+
+	// Invalid files
+	invalidContents := []string{
+		"not: [valid",
+		"production:\n  main:\n    hosts:\n      - port: 3306\n",
+		"production:\n  main:\n    hosts:\n      - host: a.db.host:3307\n        port: 3308\n",
+		"production:\n  main:\n    hosts: [a.db.host:banana]\n",
+	}
+	for n, contents := range invalidContents {
+		path := filepath.Join(dirPath, "invalid"+string(rune('a'+n))+".yaml")
+		WriteTestFile(t, path, contents)
+		if _, err := ReadInventory(path); err == nil {
+			t.Errorf("Expected error from ReadInventory with contents %q, but err was nil", contents)
+		}
+	}
+	if _, err := ReadInventory(filepath.Join(dirPath, "doesnt-exist.yaml")); err == nil {
+		t.Error("Expected error from ReadInventory with nonexistent file, but err was nil")
+	}
+}
+
+func TestDirInventory(t *testing.T) {
+	inventoryPath := filepath.Join(t.TempDir(), "inventory.yaml")
+	WriteTestFile(t, inventoryPath, testInventoryYAML)
+	getInventoryDir := func(environment string, optionValues map[string]string) *Dir {
+		t.Helper()
+		cmd := mybase.NewCommand("test", "1.0", "this is for testing", nil)
+		cmd.AddArg("environment", "production", false)
+		util.AddGlobalOptions(cmd)
+		optionValues["host-inventory"] = inventoryPath
+		cli := &mybase.CommandLine{
+			Command:   cmd,
+			ArgValues: []string{environment},
+		}
+		return &Dir{
+			Path:   "/tmp/dummydir",
+			Config: mybase.NewConfig(cli, mybase.SimpleSource(optionValues)),
+		}
+	}
+
+	dir := getInventoryDir("production", map[string]string{"host": "users", "schema": "*", "connect-options": "sql_mode='STRICT_ALL_TABLES',wait_timeout=10"})
+	hosts, err := dir.Hostnames()
+	if expected := []string{"users1.db.host:3307", "users2.db.host:3308"}; err != nil || !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("Expected Hostnames to return %v, nil; instead found %v, %v", expected, hosts, err)
+	}
+	instances, err := dir.Instances()
+	if err != nil || len(instances) != 2 {
+		t.Fatalf("Unexpected return from Instances: %v, %v", instances, err)
+	}
+	expectDSNParts := [][]string{
+		{"wait_timeout=60", "sql_mode=%27STRICT_ALL_TABLES%27"},
+		{"wait_timeout=30", "lock_wait_timeout=5", "sql_mode=%27STRICT_ALL_TABLES%27"},
+	}
+	for n, inst := range instances {
+		for _, part := range expectDSNParts[n] {
+			if params := inst.BuildParamString(""); !strings.Contains(params, part) {
+				t.Errorf("Expected params for %s to contain %q, but it did not: %s", inst, part, params)
+			}
+		}
+	}
+	expectSchemas := [][]string{{"users_001", "users_002", "users_archive"}, {"users_003"}}
+	for n, inst := range instances {
+		if names, err := dir.InventorySchemaNames(inst.Host, inst.Port); err != nil || !reflect.DeepEqual(names, expectSchemas[n]) {
+			t.Errorf("Expected InventorySchemaNames for %s to return %v, nil; instead found %v, %v", inst, expectSchemas[n], names, err)
+		}
+	}
+
+	// Regex and ignore-schema filtering
+	dir = getInventoryDir("production", map[string]string{"host": "users", "schema": "/^users_00/", "ignore-schema": "2$"})
+	if names, err := dir.InventorySchemaNames(instances[0].Host, instances[0].Port); err != nil || !reflect.DeepEqual(names, []string{"users_001"}) {
+		t.Errorf("Unexpected return from InventorySchemaNames: %v, %v", names, err)
+	}
+
+	// Literal schema names are not overridden by inventory
+	dir = getInventoryDir("production", map[string]string{"host": "users", "schema": "foo"})
+	if names, err := dir.InventorySchemaNames(instances[0].Host, instances[0].Port); err != nil || names != nil {
+		t.Errorf("Unexpected return from InventorySchemaNames: %v, %v", names, err)
+	}
+
+	// Hosts that aren't in the inventory, or clusters without schemas, return nil
+	other, _ := tengo.NewInstance("mysql", "root@tcp(other.db.host:3306)/")
+	if names, err := dir.InventorySchemaNames(other.Host, other.Port); err != nil || names != nil {
+		t.Errorf("Unexpected return from InventorySchemaNames: %v, %v", names, err)
+	}
+	dir = getInventoryDir("production", map[string]string{"host": "orders", "schema": "*"})
+	if instances, err := dir.Instances(); err != nil || len(instances) != 2 {
+		t.Errorf("Unexpected return from Instances: %v, %v", instances, err)
+	} else if names, err := dir.InventorySchemaNames(instances[0].Host, instances[0].Port); err != nil || names != nil {
+		t.Errorf("Unexpected return from InventorySchemaNames: %v, %v", names, err)
+	}
+
+	// Environment affects lookup
+	dir = getInventoryDir("staging", map[string]string{"host": "users"})
+	if hosts, err := dir.Hostnames(); err != nil || !reflect.DeepEqual(hosts, []string{"users.staging.host"}) {
+		t.Errorf("Unexpected return from Hostnames: %v, %v", hosts, err)
+	}
+
+	// Empty cluster returns no hosts; missing cluster or environment is an error
+	dir = getInventoryDir("production", map[string]string{"host": "empty"})
+	if hosts, err := dir.Hostnames(); err != nil || len(hosts) != 0 {
+		t.Errorf("Unexpected return from Hostnames: %v, %v", hosts, err)
+	}
+	dir = getInventoryDir("production", map[string]string{"host": "doesnt-exist"})
+	if _, err := dir.Instances(); err == nil {
+		t.Error("Expected error from missing cluster, but err was nil")
+	}
+	dir = getInventoryDir("development", map[string]string{"host": "users"})
+	if _, err := dir.Instances(); err == nil {
+		t.Error("Expected error from missing environment, but err was nil")
+	}
+
+	// No host means no lookup
+	dir = getInventoryDir("production", map[string]string{})
+	if hosts, err := dir.Hostnames(); err != nil || len(hosts) != 0 {
+		t.Errorf("Unexpected return from Hostnames: %v, %v", hosts, err)
+	}
+}
+
+func TestDirInventoryRelativePath(t *testing.T) {
+	// A relative host-inventory path in an option file should be resolved
+	// relative to that option file's directory, regardless of working dir or
+	// which subdir is being parsed
+	repoPath := t.TempDir()
+	WriteTestFile(t, filepath.Join(repoPath, "inventory.yaml"), testInventoryYAML)
+	WriteTestFile(t, filepath.Join(repoPath, ".skeema"), "host-inventory=inventory.yaml\n")
+	WriteTestFile(t, filepath.Join(repoPath, "users", ".skeema"), "[production]\nhost=users\n")
+	dir := getDir(t, filepath.Join(repoPath, "users"))
+	if path, expected := dir.PathOption("host-inventory"), filepath.Join(repoPath, "inventory.yaml"); path != expected {
+		t.Errorf("Expected PathOption to return %q, instead found %q", expected, path)
+	}
+	if hosts, err := dir.Hostnames(); err != nil || len(hosts) != 2 {
+		t.Errorf("Unexpected return from Hostnames: %v, %v", hosts, err)
+	}
+
+	// Relative paths on the command-line are left as-is
+	dir = getDirWithCLI(t, filepath.Join(repoPath, "users"), "--host-inventory=other.yaml")
+	if path := dir.PathOption("host-inventory"); path != "other.yaml" {
+		t.Errorf("Expected PathOption to return %q, instead found %q", "other.yaml", path)
+	}
+}
@@ -18,6 +18,10 @@ type LogicalSchema struct {
 	Collation string
 	Creates   map[tengo.ObjectKey]*tengo.Statement
 	Alters    []*tengo.Statement // Alterations that are run after the Creates
+
+	// LeadingComments maps object keys to the comment lines immediately
+	// preceding the corresponding CREATE, for CREATEs that have any.
+	LeadingComments map[tengo.ObjectKey]string
 }
 
 // NewLogicalSchema returns a pointer to an empty, nameless LogicalSchema. Any
@@ -25,7 +29,8 @@ type LogicalSchema struct {
 // zero values.
 func NewLogicalSchema() *LogicalSchema {
 	return &LogicalSchema{
-		Creates: make(map[tengo.ObjectKey]*tengo.Statement),
+		Creates:         make(map[tengo.ObjectKey]*tengo.Statement),
+		LeadingComments: make(map[tengo.ObjectKey]string),
 	}
 }
 
@@ -53,6 +58,28 @@ func (logicalSchema *LogicalSchema) AddStatement(stmt *tengo.Statement) error {
 	return nil
 }
 
+// adjacentComment returns the lines at the end of noop's text which are not
+// separated from the following statement by a blank line. noop should be a
+// StatementTypeNoop statement, consisting only of whitespace and comments. If
+// noop begins mid-line, its text up to the first newline is a trailing comment
+// on the same line as the previous statement, and is excluded.
+func adjacentComment(noop *tengo.Statement) string {
+	noopText := noop.Text
+	if noop.CharNo > 1 {
+		_, noopText, _ = strings.Cut(noopText, "\n")
+	}
+	lines := strings.Split(noopText, "\n")
+	end := len(lines)
+	if strings.TrimSpace(lines[end-1]) == "" {
+		end-- // following statement begins at start of line
+	}
+	start := end
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
 // Empty returns true if the LogicalSchema contains no statements.
 func (logicalSchema *LogicalSchema) Empty() bool {
 	return len(logicalSchema.Creates)+len(logicalSchema.Alters) == 0
@@ -68,7 +95,9 @@ func (logicalSchema *LogicalSchema) LowerCaseNames(mode tengo.NameCaseMode) erro
 		// Schema names and table names are forced lowercase in this mode
 		logicalSchema.Name = strings.ToLower(logicalSchema.Name)
 		newCreates := make(map[tengo.ObjectKey]*tengo.Statement, len(logicalSchema.Creates))
+		newComments := make(map[tengo.ObjectKey]string, len(logicalSchema.LeadingComments))
 		for k, stmt := range logicalSchema.Creates {
+			comment, hasComment := logicalSchema.LeadingComments[k]
 			if k.Type == tengo.ObjectTypeTable {
 				k.Name = strings.ToLower(k.Name)
 				stmt.ObjectName = strings.ToLower(stmt.ObjectName)
@@ -83,8 +112,12 @@ func (logicalSchema *LogicalSchema) LowerCaseNames(mode tengo.NameCaseMode) erro
 				}
 			}
 			newCreates[k] = stmt
+			if hasComment {
+				newComments[k] = comment
+			}
 		}
 		logicalSchema.Creates = newCreates
+		logicalSchema.LeadingComments = newComments
 
 	case tengo.NameCaseInsensitive: // lower_case_table_names=2
 		// Only view names are forced to lowercase in this mode, but Community Edition
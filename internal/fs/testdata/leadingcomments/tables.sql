@@ -0,0 +1,34 @@
+-- This comment is separated from the CREATE by a blank line
+
+-- skeema:lint-ignore pk
+# reason: legacy
+CREATE TABLE one (
+	id int unsigned NOT NULL
+);
+CREATE TABLE two (
+	id int unsigned NOT NULL,
+	PRIMARY KEY (id)
+);
+
+/* not adjacent */
+
+CREATE TABLE three (
+	id int unsigned NOT NULL,
+	PRIMARY KEY (id)
+);
+/* same line */ CREATE TABLE four (
+	id int unsigned NOT NULL,
+	PRIMARY KEY (id)
+);
+CREATE TABLE five (
+	id int unsigned NOT NULL,
+	PRIMARY KEY (id)
+); -- trailing comment on five
+CREATE TABLE six (
+	id int unsigned NOT NULL,
+	PRIMARY KEY (id)
+); /* trailing comment on six */
+-- skeema:lint-ignore
+CREATE TABLE seven (
+	id int unsigned NOT NULL
+);
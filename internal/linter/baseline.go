@@ -0,0 +1,198 @@
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// A baseline file records a set of pre-existing annotations, so that only new
+// problems are reported by subsequent lint operations. Each annotation is
+// identified by its file, object, rule, and a fingerprint of its message. Line
+// numbers are deliberately excluded, so that unrelated edits to a file do not
+// invalidate its baseline entries.
+
+// BaselineEntry identifies a single annotation in a baseline file.
+type BaselineEntry struct {
+	File        string           `json:"file"`
+	ObjectType  tengo.ObjectType `json:"type,omitempty"`
+	ObjectName  string           `json:"name,omitempty"`
+	Rule        string           `json:"rule,omitempty"`
+	Fingerprint string           `json:"fingerprint"`
+}
+
+// String returns a human-readable description of the entry.
+func (entry BaselineEntry) String() string {
+	var object string
+	if entry.ObjectType != "" {
+		object = " " + tengo.ObjectKey{Type: entry.ObjectType, Name: entry.ObjectName}.String()
+	}
+	rule := entry.Rule
+	if rule == "" {
+		rule = "statement error"
+	} else {
+		rule = "lint-" + rule
+	}
+	return fmt.Sprintf("%s:%s (%s)", entry.File, object, rule)
+}
+
+// Baseline represents the contents of a baseline file.
+type Baseline struct {
+	Entries   []BaselineEntry `json:"entries"`
+	baseDir   string          // file paths in entries are relative to this dir
+	remaining map[BaselineEntry]int
+}
+
+// NewBaseline returns an empty baseline, for use in tracking annotations that
+// will be written to a baseline file in baseDir.
+func NewBaseline(baseDir string) *Baseline {
+	return &Baseline{
+		Entries:   []BaselineEntry{},
+		baseDir:   baseDir,
+		remaining: make(map[BaselineEntry]int),
+	}
+}
+
+// ReadBaseline reads and parses the baseline file at filePath.
+func ReadBaseline(filePath string) (*Baseline, error) {
+	filePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read baseline file: %w", err)
+	}
+	b := NewBaseline(filepath.Dir(filePath))
+	if err := json.Unmarshal(contents, b); err != nil {
+		return nil, fmt.Errorf("Unable to parse baseline file %s: %w", filePath, err)
+	}
+	for _, entry := range b.Entries {
+		b.remaining[entry]++
+	}
+	return b, nil
+}
+
+// WriteFile writes the baseline's entries to filePath, in a deterministic
+// order.
+func (b *Baseline) WriteFile(filePath string) error {
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		} else if ei.ObjectType != ej.ObjectType {
+			return ei.ObjectType < ej.ObjectType
+		} else if ei.ObjectName != ej.ObjectName {
+			return ei.ObjectName < ej.ObjectName
+		} else if ei.Rule != ej.Rule {
+			return ei.Rule < ej.Rule
+		}
+		return ei.Fingerprint < ej.Fingerprint
+	})
+	contents, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(contents, '\n'), 0666)
+}
+
+// Entry returns the BaselineEntry corresponding to annotation a.
+func (b *Baseline) Entry(a *Annotation) BaselineEntry {
+	entry := BaselineEntry{
+		Rule:        a.RuleName,
+		Fingerprint: fingerprintMessage(a.Message),
+	}
+	if key := a.Statement.ObjectKey(); key.Type != "" {
+		entry.ObjectType, entry.ObjectName = key.Type, key.Name
+	}
+	if a.Statement.File != "" {
+		entry.File = a.Statement.File
+		if rel, err := filepath.Rel(b.baseDir, a.Statement.File); err == nil {
+			entry.File = filepath.ToSlash(rel)
+		}
+	}
+	return entry
+}
+
+// AddResult adds entries for all annotations in r to the baseline.
+func (b *Baseline) AddResult(r *Result) {
+	for _, a := range r.Annotations {
+		entry := b.Entry(a)
+		b.Entries = append(b.Entries, entry)
+		b.remaining[entry]++
+	}
+}
+
+// Filter removes any annotations from r which are present in the baseline,
+// adjusting r's error and warning counts accordingly. Each baseline entry can
+// only match one annotation; if a problem occurs more times than it was
+// recorded in the baseline, the additional occurrences are retained in r.
+func (b *Baseline) Filter(r *Result) {
+	kept := r.Annotations[:0]
+	for _, a := range r.Annotations {
+		entry := b.Entry(a)
+		if b.remaining[entry] <= 0 {
+			kept = append(kept, a)
+			continue
+		}
+		b.remaining[entry]--
+		switch a.Severity {
+		case SeverityError:
+			r.ErrorCount--
+		case SeverityWarning:
+			r.WarningCount--
+		}
+		r.BaselinedCount++
+		r.Debug("Ignoring %s annotation at %s due to baseline file: %s", a.Severity, a.Location(), a.Summary)
+	}
+	r.Annotations = kept
+}
+
+// Fixed returns baseline entries for files within dirPath which have not
+// matched any annotation passed to Filter. These typically represent problems
+// which have since been fixed, and can be removed from the baseline.
+func (b *Baseline) Fixed(dirPath string) (fixed []BaselineEntry) {
+	prefix, err := filepath.Rel(b.baseDir, dirPath)
+	if err != nil {
+		return nil
+	}
+	prefix = filepath.ToSlash(prefix)
+	remaining := make(map[BaselineEntry]int, len(b.remaining))
+	for entry, count := range b.remaining {
+		remaining[entry] = count
+	}
+	for _, entry := range b.Entries {
+		if prefix != "." && !strings.HasPrefix(entry.File, prefix+"/") {
+			continue
+		}
+		if remaining[entry] > 0 {
+			remaining[entry]--
+			fixed = append(fixed, entry)
+		}
+	}
+	return fixed
+}
+
+var reFingerprintNormalize = regexp.MustCompile(`\d+|\s+`)
+
+// fingerprintMessage returns a short hash of an annotation message. Numbers
+// and whitespace are normalized first, since messages may refer to line
+// numbers or sizes which can change without the underlying problem changing.
+func fingerprintMessage(message string) string {
+	normalized := reFingerprintNormalize.ReplaceAllStringFunc(strings.ToLower(message), func(s string) string {
+		if strings.TrimSpace(s) == "" {
+			return " "
+		}
+		return "0"
+	})
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,80 @@
+package linter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestBaseline(t *testing.T) {
+	baseDir := t.TempDir()
+	stmt := func(subdir, name string) *tengo.Statement {
+		return &tengo.Statement{
+			File:       filepath.Join(baseDir, subdir, name+".sql"),
+			LineNo:     1,
+			Type:       tengo.StatementTypeCreate,
+			ObjectType: tengo.ObjectTypeTable,
+			ObjectName: name,
+		}
+	}
+	fooStmt, barStmt := stmt("one", "foo"), stmt("two", "bar")
+	original := &Result{}
+	original.Annotate(fooStmt, SeverityWarning, "pk", Note{Summary: "No primary key", Message: "Table foo does not define a PRIMARY KEY"})
+	original.Annotate(fooStmt, SeverityError, "row-size", Note{LineOffset: 2, Summary: "Row size too large", Message: "Table foo has a maximum row size of 70000 bytes"})
+	original.Annotate(barStmt, SeverityWarning, "pk", Note{Summary: "No primary key", Message: "Table bar does not define a PRIMARY KEY"})
+
+	b := NewBaseline(baseDir)
+	b.AddResult(original)
+	filePath := filepath.Join(baseDir, "baseline.json")
+	if err := b.WriteFile(filePath); err != nil {
+		t.Fatalf("Unexpected error from WriteFile: %v", err)
+	}
+	b, err := ReadBaseline(filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadBaseline: %v", err)
+	} else if len(b.Entries) != 3 {
+		t.Fatalf("Expected baseline to have 3 entries, instead found %d", len(b.Entries))
+	} else if b.Entries[0].File != "one/foo.sql" || b.Entries[0].ObjectName != "foo" || b.Entries[2].File != "two/bar.sql" {
+		t.Errorf("Unexpected baseline entries: %+v", b.Entries)
+	}
+
+	// Changes in line offset or numbers in the message should not affect
+	// matching, but new problems should be retained. The problem in bar has been
+	// fixed.
+	current := &Result{}
+	current.Annotate(fooStmt, SeverityWarning, "pk", Note{Summary: "No primary key", Message: "Table foo does not define a PRIMARY KEY"})
+	current.Annotate(fooStmt, SeverityError, "row-size", Note{LineOffset: 5, Summary: "Row size too large", Message: "Table foo has a maximum row size of 80000 bytes"})
+	current.Annotate(fooStmt, SeverityWarning, "has-float", Note{Summary: "Column using FLOAT", Message: "Column x of table foo is using type float"})
+	b.Filter(current)
+	if len(current.Annotations) != 1 || current.Annotations[0].RuleName != "has-float" {
+		t.Errorf("Unexpected annotations remaining after Filter: %+v", current.Annotations)
+	}
+	if current.ErrorCount != 0 || current.WarningCount != 1 || current.BaselinedCount != 2 {
+		t.Errorf("Unexpected counts after Filter: errors=%d warnings=%d baselined=%d", current.ErrorCount, current.WarningCount, current.BaselinedCount)
+	}
+	if len(current.DebugLogs) != 2 {
+		t.Errorf("Expected 2 debug logs, instead found %d", len(current.DebugLogs))
+	}
+
+	// A baseline entry only matches a single annotation
+	again := &Result{}
+	again.Annotate(fooStmt, SeverityWarning, "pk", Note{Summary: "No primary key", Message: "Table foo does not define a PRIMARY KEY"})
+	b.Filter(again)
+	if len(again.Annotations) != 1 || again.WarningCount != 1 {
+		t.Errorf("Expected annotation to be retained after baseline entry already matched; instead found %d annotations", len(again.Annotations))
+	}
+
+	if fixed := b.Fixed(baseDir); len(fixed) != 1 || fixed[0].ObjectName != "bar" {
+		t.Errorf("Unexpected result from Fixed: %+v", fixed)
+	} else if expected := "two/bar.sql: table `bar` (lint-pk)"; fixed[0].String() != expected {
+		t.Errorf("Expected String() to return %q, instead found %q", expected, fixed[0].String())
+	}
+	if fixed := b.Fixed(filepath.Join(baseDir, "one")); len(fixed) != 0 {
+		t.Errorf("Expected no fixed entries within subdir one, instead found %+v", fixed)
+	}
+
+	if _, err := ReadBaseline(filepath.Join(baseDir, "doesnt-exist.json")); err == nil {
+		t.Error("Expected error from ReadBaseline on nonexistent file, but err was nil")
+	}
+}
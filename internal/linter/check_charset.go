@@ -14,6 +14,7 @@ func init() {
 		Name:            "charset",
 		Description:     "Only allow character sets listed in --allow-charset",
 		DefaultSeverity: SeverityWarning,
+		FixerFunc:       charsetFixer,
 	}
 	rule.RelatedListOption(
 		"allow-charset",
@@ -91,3 +92,31 @@ func makeCharsetMessage(table *tengo.Table, column *tengo.Column, opts Options)
 	}
 	return fmt.Sprintf("%s is using %s %s, which is not configured to be permitted.%s%s", subject, using, charSet, allowedList, moreInfo)
 }
+
+// charsetFixer converts the table and its columns from the legacy utf8mb3
+// character set (or its alias utf8) to utf8mb4, if utf8mb3 is not permitted but
+// utf8mb4 is. Other disallowed character sets have no unambiguous replacement,
+// so they are left as-is. Collations are converted to the utf8mb4 equivalent,
+// e.g. utf8_unicode_ci becomes utf8mb4_unicode_ci.
+func charsetFixer(table *tengo.Table, _ *tengo.Schema, opts Options) (fixed bool) {
+	if !opts.IsAllowed("charset", "utf8mb4") {
+		return false
+	}
+	convert := func(charSet, collation *string, collationIsDefault *bool) {
+		if (*charSet != "utf8" && *charSet != "utf8mb3") || opts.IsAllowed("charset", *charSet) {
+			return
+		}
+		*charSet = "utf8mb4"
+		if _, suffix, ok := strings.Cut(*collation, "_"); ok {
+			*collation = "utf8mb4_" + suffix
+		}
+		// The default collation for utf8mb4 varies by flavor, so always specify it
+		*collationIsDefault = false
+		fixed = true
+	}
+	convert(&table.CharSet, &table.Collation, &table.CollationIsDefault)
+	for _, col := range table.Columns {
+		convert(&col.CharSet, &col.Collation, &col.CollationIsDefault)
+	}
+	return fixed
+}
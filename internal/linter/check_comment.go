@@ -0,0 +1,64 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	tableRule := Rule{
+		CheckerFunc:     TableChecker(tableCommentChecker),
+		Name:            "table-comment",
+		Description:     "Flag tables lacking a COMMENT, unless listed in --table-comment-exempt",
+		DefaultSeverity: SeverityIgnore,
+		Broad:           true,
+	}
+	tableRule.RelatedListOption(
+		"table-comment-exempt",
+		"",
+		"List of table names which do not require a COMMENT for --lint-table-comment",
+		false,
+	)
+	RegisterRule(tableRule)
+
+	columnRule := Rule{
+		CheckerFunc:     TableChecker(columnCommentChecker),
+		Name:            "column-comment",
+		Description:     "Flag columns lacking a COMMENT, unless listed in --column-comment-exempt",
+		DefaultSeverity: SeverityIgnore,
+		Broad:           true,
+	}
+	columnRule.RelatedListOption(
+		"column-comment-exempt",
+		"",
+		"List of column names which do not require a COMMENT for --lint-column-comment",
+		false,
+	)
+	RegisterRule(columnRule)
+}
+
+func tableCommentChecker(table *tengo.Table, _ string, _ *tengo.Schema, opts Options) []Note {
+	if table.Comment != "" || opts.IsAllowed("table-comment", table.Name) {
+		return nil
+	}
+	return []Note{{
+		Summary: "Table lacks a comment",
+		Message: fmt.Sprintf("Table %s does not have a COMMENT. Please describe the table's purpose by adding a COMMENT clause to its table options.", table.Name),
+	}}
+}
+
+func columnCommentChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	var results []Note
+	for _, col := range table.Columns {
+		if col.Comment != "" || opts.IsAllowed("column-comment", col.Name) {
+			continue
+		}
+		results = append(results, Note{
+			LineOffset: FindColumnLineOffset(col, createStatement),
+			Summary:    "Column lacks a comment",
+			Message:    fmt.Sprintf("Column %s of table %s does not have a COMMENT. Please describe the column's purpose by adding a COMMENT clause to its definition.", col.Name, table.Name),
+		})
+	}
+	return results
+}
@@ -15,6 +15,7 @@ func init() {
 		Name:            "display-width",
 		Description:     "Only allow default display width for int types",
 		DefaultSeverity: SeverityWarning,
+		FixerFunc:       displayWidthFixer,
 	})
 }
 
@@ -49,11 +50,7 @@ func displayWidthChecker(table *tengo.Table, createStatement string, _ *tengo.Sc
 		if rawType == "tinyint" && displayWidth == "1" {
 			continue // allow tinyint(1) since bool is an alias for this
 		}
-		defaultWidthInt := signedDefaultWidths[rawType]
-		if unsigned && rawType != "bigint" {
-			defaultWidthInt--
-		}
-		defaultWidth := strconv.Itoa(defaultWidthInt)
+		defaultWidth := defaultDisplayWidth(rawType, unsigned)
 		if displayWidth != defaultWidth {
 			message := fmt.Sprintf(
 				"Column %s of table %s is using display width %s, but the default for %s%s is %s.\nInteger display widths do not control what range of values may be stored in a column. Typically they have no effect whatsoever. If in doubt, omit the width entirely, or use the default of %s(%s)%s.",
@@ -70,3 +67,29 @@ func displayWidthChecker(table *tengo.Table, createStatement string, _ *tengo.Sc
 	}
 	return results
 }
+
+// defaultDisplayWidth returns the default display width for rawType, as a
+// string.
+func defaultDisplayWidth(rawType string, unsigned bool) string {
+	width := signedDefaultWidths[rawType]
+	if unsigned && rawType != "bigint" {
+		width--
+	}
+	return strconv.Itoa(width)
+}
+
+// displayWidthFixer changes any non-default int display widths to the default,
+// using the same exceptions as displayWidthChecker.
+func displayWidthFixer(table *tengo.Table, _ *tengo.Schema, _ Options) (fixed bool) {
+	for _, col := range table.Columns {
+		matches := reDisplayWidth.FindStringSubmatch(col.TypeInDB)
+		if matches == nil || matches[4] != "" || (matches[1] == "tinyint" && matches[2] == "1") {
+			continue
+		}
+		if defaultWidth := defaultDisplayWidth(matches[1], matches[3] != ""); matches[2] != defaultWidth {
+			col.TypeInDB = fmt.Sprintf("%s(%s)%s", matches[1], defaultWidth, matches[3]) + col.TypeInDB[len(matches[0]):]
+			fixed = true
+		}
+	}
+	return fixed
+}
@@ -14,6 +14,7 @@ func init() {
 		Name:            "engine",
 		Description:     "Only allow storage engines listed in --allow-engine",
 		DefaultSeverity: SeverityWarning,
+		FixerFunc:       engineFixer,
 	}
 	rule.RelatedListOption(
 		"allow-engine",
@@ -42,3 +43,13 @@ func engineChecker(table *tengo.Table, createStatement string, _ *tengo.Schema,
 		Message:    message,
 	}
 }
+
+// engineFixer converts tables using a disallowed storage engine to InnoDB, if
+// InnoDB is permitted.
+func engineFixer(table *tengo.Table, _ *tengo.Schema, opts Options) bool {
+	if opts.IsAllowed("engine", table.Engine) || !opts.IsAllowed("engine", "InnoDB") {
+		return false
+	}
+	table.Engine = "InnoDB"
+	return true
+}
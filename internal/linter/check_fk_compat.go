@@ -0,0 +1,131 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(fkCompatChecker),
+		Name:            "fk-compat",
+		Description:     "Flag foreign keys whose columns are incompatible with the referenced columns, or lack a unique index in the referenced table",
+		DefaultSeverity: SeverityWarning,
+	})
+}
+
+func fkCompatChecker(table *tengo.Table, createStatement string, schema *tengo.Schema, _ Options) []Note {
+	var results []Note
+	cols := table.ColumnsByName()
+	for _, fk := range table.ForeignKeys {
+		// Cross-schema foreign keys can't be checked, since only one schema is
+		// available. Referenced tables which don't exist are also skipped, since
+		// they may be excluded by ignore-table.
+		if fk.ReferencedSchemaName != "" {
+			continue
+		}
+		parent := schema.Table(fk.ReferencedTableName)
+		if parent == nil {
+			continue
+		}
+		parentCols := parent.ColumnsByName()
+		var problems []string
+		for n, colName := range fk.ColumnNames {
+			parentColName := fk.ReferencedColumnNames[n]
+			if parentCol := parentCols[parentColName]; parentCol == nil {
+				problems = append(problems, fmt.Sprintf("referenced column %s.%s does not exist", parent.Name, parentColName))
+			} else if col := cols[colName]; col != nil {
+				if problem := fkColumnProblem(col, parentCol, parent.Name); problem != "" {
+					problems = append(problems, problem)
+				}
+			}
+		}
+		if !hasUniqueIndexOn(parent, fk.ReferencedColumnNames) {
+			problems = append(problems, fmt.Sprintf("table %s has no PRIMARY KEY or UNIQUE index on exactly (%s)", parent.Name, strings.Join(fk.ReferencedColumnNames, ", ")))
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		message := fmt.Sprintf(
+			"Foreign key %s of table %s is not compatible with referenced table %s:\n%s.\nThe database server may reject this foreign key with a cryptic error (such as errno 150 or error 3780), or it may behave unexpectedly.",
+			fk.Name, table.Name, parent.Name, strings.Join(problems, ";\n"),
+		)
+		results = append(results, Note{
+			LineOffset: FindFirstLineOffset(foreignKeyRegexp(fk), createStatement),
+			Summary:    "Foreign key incompatible with referenced table",
+			Message:    message,
+		})
+	}
+	return results
+}
+
+// foreignKeyRegexp returns a regular expression matching the FOREIGN KEY clause
+// of fk. The constraint name is not used, since it may have been generated
+// automatically by the server.
+func foreignKeyRegexp(fk *tengo.ForeignKey) *regexp.Regexp {
+	quotedCols := make([]string, len(fk.ColumnNames))
+	for n, colName := range fk.ColumnNames {
+		quotedCols[n] = "`?" + regexp.QuoteMeta(colName) + "`?"
+	}
+	return regexp.MustCompile(`(?i)foreign\s+key\s*[^(]*\(\s*` + strings.Join(quotedCols, `\s*,\s*`) + `\s*\)`)
+}
+
+var reIntDisplayWidth = regexp.MustCompile(`^((?:tiny|small|medium|big)?int)\(\d+\)`)
+
+// fkColumnProblem returns a description of why col is incompatible with the
+// column parentCol that it references, or an empty string if the two are
+// compatible.
+func fkColumnProblem(col, parentCol *tengo.Column, parentTableName string) string {
+	normalize := func(typ string) string {
+		typ = strings.ToLower(typ)
+		typ = reIntDisplayWidth.ReplaceAllString(typ, "$1")
+		return strings.TrimSpace(strings.Replace(typ, " zerofill", "", 1))
+	}
+	colType, parentType := normalize(col.TypeInDB), normalize(parentCol.TypeInDB)
+	if colType != parentType {
+		var reason string
+		if baseColType(colType) != baseColType(parentType) {
+			reason = "type"
+		} else if strings.Contains(colType, "unsigned") != strings.Contains(parentType, "unsigned") {
+			reason = "signedness"
+		} else {
+			reason = "length"
+		}
+		return fmt.Sprintf("column %s is %s, but referenced column %s.%s is %s (%s differs)", col.Name, colType, parentTableName, parentCol.Name, parentType, reason)
+	}
+	if col.CharSet != parentCol.CharSet {
+		return fmt.Sprintf("column %s uses character set %s, but referenced column %s.%s uses %s", col.Name, col.CharSet, parentTableName, parentCol.Name, parentCol.CharSet)
+	} else if col.Collation != parentCol.Collation {
+		return fmt.Sprintf("column %s uses collation %s, but referenced column %s.%s uses %s", col.Name, col.Collation, parentTableName, parentCol.Name, parentCol.Collation)
+	}
+	return ""
+}
+
+// hasUniqueIndexOn returns true if table's primary key, or one of its unique
+// secondary indexes, consists of exactly the supplied columns in order,
+// without any prefix lengths.
+func hasUniqueIndexOn(table *tengo.Table, colNames []string) bool {
+	indexes := table.SecondaryIndexes
+	if table.PrimaryKey != nil {
+		indexes = append([]*tengo.Index{table.PrimaryKey}, indexes...)
+	}
+	for _, idx := range indexes {
+		if !idx.Unique || len(idx.Parts) != len(colNames) {
+			continue
+		}
+		match := true
+		for n, part := range idx.Parts {
+			if part.ColumnName != colNames[n] || part.PrefixLength > 0 {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// namingKinds lists the kinds of names checked by the naming-convention rule,
+// in the order they are processed. Each kind has a corresponding option
+// "naming-<kind>" containing a regular expression, or an empty string if that
+// kind of name should not be checked.
+var namingKinds = []struct {
+	kind        string
+	description string
+}{
+	{"table", "table names"},
+	{"column", "column names"},
+	{"index", "non-unique secondary index names"},
+	{"unique-index", "unique secondary index names"},
+	{"fulltext-index", "fulltext index names"},
+	{"foreign-key", "foreign key names"},
+	{"check", "check constraint names"},
+	{"proc", "stored procedure names"},
+	{"func", "stored function names"},
+}
+
+func init() {
+	// This rule uses RelatedOptions and a custom ConfigFunc, since it has a
+	// separate supplemental option for each kind of name.
+	rule := Rule{
+		CheckerFunc:     GenericChecker(namingConventionChecker),
+		Name:            "naming-convention",
+		Description:     "Flag names of tables, columns, indexes, foreign keys, check constraints, or routines not matching the regular expressions in naming-* options",
+		DefaultSeverity: SeverityIgnore,
+		ConfigFunc:      RuleConfigFunc(namingConventionConfiger),
+	}
+	for _, nk := range namingKinds {
+		desc := fmt.Sprintf("Regular expression which %s must match, for --lint-naming-convention", nk.description)
+		rule.RelatedOptions = append(rule.RelatedOptions, mybase.StringOption("naming-"+nk.kind, 0, "", desc))
+	}
+	RegisterRule(rule)
+}
+
+// namingConfig maps each kind of name to its configured pattern. Patterns may
+// contain placeholders {table} and {refTable}, which are replaced with the
+// quoted names of the relevant table and referenced table, respectively, so
+// they can only be compiled upon checking a specific object.
+type namingConfig map[string]string
+
+// namingConventionConfiger returns the configured patterns. Patterns are
+// compiled once here, with placeholders replaced, purely for validation
+// purposes.
+func namingConventionConfiger(config *mybase.Config) interface{} {
+	nc := make(namingConfig)
+	placeholders := strings.NewReplacer("{table}", "x", "{refTable}", "x")
+	for _, nk := range namingKinds {
+		optionName := "naming-" + nk.kind
+		pattern := config.Get(optionName)
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(placeholders.Replace(pattern)); err != nil {
+			return fmt.Errorf("Option %s has invalid regular expression %q: %w", optionName, pattern, err)
+		}
+		nc[nk.kind] = pattern
+	}
+	return nc
+}
+
+func namingConventionChecker(object tengo.DefKeyer, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	nc := opts.RuleConfig["naming-convention"].(namingConfig)
+	if len(nc) == 0 {
+		return nil
+	}
+	var results []Note
+	check := func(kind, typ, name string, replacer *strings.Replacer, lineOffset int) {
+		pattern, ok := nc[kind]
+		if !ok {
+			return
+		}
+		if replacer != nil {
+			pattern = replacer.Replace(pattern)
+		}
+		if regexp.MustCompile(pattern).MatchString(name) {
+			return
+		}
+		subject := strings.ToUpper(typ[:1]) + typ[1:]
+		results = append(results, Note{
+			LineOffset: lineOffset,
+			Summary:    subject + " name does not match naming convention",
+			Message:    fmt.Sprintf("%s name %s does not match the pattern %s configured in option naming-%s.", subject, name, pattern, kind),
+		})
+	}
+
+	switch object := object.(type) {
+	case *tengo.Routine:
+		kind := "func"
+		if object.Type == tengo.ObjectTypeProc {
+			kind = "proc"
+		}
+		check(kind, string(object.Type), object.Name, nil, 0)
+	case *tengo.Table:
+		replacer := strings.NewReplacer("{table}", regexp.QuoteMeta(object.Name))
+		check("table", "table", object.Name, replacer, 0)
+		for _, col := range object.Columns {
+			check("column", "column", col.Name, replacer, FindColumnLineOffset(col, createStatement))
+		}
+		for _, idx := range object.SecondaryIndexes {
+			kind := "index"
+			if idx.Type == "FULLTEXT" {
+				kind = "fulltext-index"
+			} else if idx.Unique {
+				kind = "unique-index"
+			}
+			check(kind, "index", idx.Name, replacer, FindIndexLineOffset(idx, createStatement))
+		}
+		for _, fk := range object.ForeignKeys {
+			fkReplacer := strings.NewReplacer("{table}", regexp.QuoteMeta(object.Name), "{refTable}", regexp.QuoteMeta(fk.ReferencedTableName))
+			check("foreign-key", "foreign key", fk.Name, fkReplacer, FindFirstLineOffset(constraintRegexp(fk.Name), createStatement))
+		}
+		for _, cc := range object.Checks {
+			check("check", "check constraint", cc.Name, replacer, FindFirstLineOffset(constraintRegexp(cc.Name), createStatement))
+		}
+	}
+	return results
+}
+
+// constraintRegexp returns a regular expression matching the start of a named
+// constraint clause in a CREATE TABLE statement.
+func constraintRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)constraint\\s+`?" + regexp.QuoteMeta(name) + "(?:`|\\s)")
+}
@@ -12,6 +12,7 @@ func init() {
 		Name:            "pk",
 		Description:     "Flag tables that lack a primary key",
 		DefaultSeverity: SeverityWarning,
+		FixerFunc:       pkFixer,
 	})
 }
 
@@ -36,3 +37,42 @@ func pkChecker(table *tengo.Table, _ string, _ *tengo.Schema, _ Options) *Note {
 		Message:    message,
 	}
 }
+
+// pkFixer converts a unique index into the table's primary key, but only if
+// the table has exactly one unique index consisting entirely of NOT NULL
+// columns, without any prefixes or expressions. For InnoDB tables, such an
+// index is already the clustered index, so this does not change the table's
+// physical layout.
+func pkFixer(table *tengo.Table, _ *tengo.Schema, _ Options) bool {
+	if table.PrimaryKey != nil {
+		return false
+	}
+	cols := table.ColumnsByName()
+	candidate := -1
+	for n, idx := range table.SecondaryIndexes {
+		if !idx.Unique || idx.Invisible || idx.Functional() {
+			continue
+		}
+		eligible := true
+		for _, part := range idx.Parts {
+			if col := cols[part.ColumnName]; col == nil || col.Nullable || part.PrefixLength > 0 {
+				eligible = false
+			}
+		}
+		if !eligible {
+			continue
+		} else if candidate >= 0 {
+			return false // multiple candidates, so no obvious choice
+		}
+		candidate = n
+	}
+	if candidate < 0 {
+		return false
+	}
+	pk := table.SecondaryIndexes[candidate]
+	pk.Name = "PRIMARY"
+	pk.PrimaryKey = true
+	table.PrimaryKey = pk
+	table.SecondaryIndexes = append(table.SecondaryIndexes[:candidate], table.SecondaryIndexes[candidate+1:]...)
+	return true
+}
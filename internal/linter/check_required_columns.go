@@ -0,0 +1,90 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	// This rule uses a customized RelatedOption and ConfigFunc, rather than using
+	// Rule.RelatedListOption, because each list entry may include a regular
+	// expression which should only be compiled once.
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(requiredColumnsChecker),
+		Name:            "required-columns",
+		Description:     "Flag tables lacking any of the columns listed in --required-columns, or having them with a non-matching definition",
+		DefaultSeverity: SeverityIgnore,
+		RelatedOption:   mybase.StringOption("required-columns", 0, "", `List of columns required in every table for --lint-required-columns, each optionally followed by "=" and a regular expression (without commas) which the column's type and modifiers must match`),
+		ConfigFunc:      RuleConfigFunc(requiredColumnsConfiger),
+	})
+}
+
+// requiredColumn represents a single entry of the required-columns option.
+type requiredColumn struct {
+	name    string
+	pattern *regexp.Regexp // nil if any column definition is permitted
+}
+
+// requiredColumnsConfiger parses the required-columns option. Each entry is a
+// column name, optionally followed by "=" and a case-insensitive regular
+// expression, for example "created_at=^(timestamp|datetime)". Since entries
+// are comma-separated, the regular expressions cannot contain commas; a
+// pattern such as "{1,3}" would be split into two entries. The resulting
+// fragment is reported as an error, rather than silently treated as the name
+// of a required column.
+func requiredColumnsConfiger(config *mybase.Config) interface{} {
+	var required []requiredColumn
+	for _, value := range config.GetSlice("required-columns", ',', true) {
+		name, pattern, hasPattern := strings.Cut(value, "=")
+		rc := requiredColumn{name: strings.TrimSpace(name)}
+		if strings.ContainsAny(rc.name, `{}()[]^$|*+?\`) {
+			return fmt.Errorf("Option required-columns has invalid column name %q. Regular expressions in this option cannot contain commas, since commas separate its entries", rc.name)
+		}
+		if hasPattern {
+			re, err := regexp.Compile("(?i)" + strings.TrimSpace(pattern))
+			if err != nil {
+				return fmt.Errorf("Option required-columns has invalid regular expression for column %s: %w", rc.name, err)
+			}
+			rc.pattern = re
+		}
+		required = append(required, rc)
+	}
+	return required
+}
+
+func requiredColumnsChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	required, _ := opts.RuleConfig["required-columns"].([]requiredColumn)
+	var results []Note
+	cols := table.ColumnsByName()
+	for _, rc := range required {
+		col := cols[rc.name]
+		if col == nil {
+			results = append(results, Note{
+				Summary: "Required column missing",
+				Message: fmt.Sprintf("Table %s does not have column %s, which is listed in option required-columns.", table.Name, rc.name),
+			})
+			continue
+		}
+		if rc.pattern == nil {
+			continue
+		}
+		// Compare against the column definition, excluding its name
+		def := col.Definition(opts.Flavor, table)
+		def = strings.TrimPrefix(def, tengo.EscapeIdentifier(col.Name)+" ")
+		if !rc.pattern.MatchString(def) {
+			results = append(results, Note{
+				LineOffset: FindColumnLineOffset(col, createStatement),
+				Summary:    "Required column has wrong definition",
+				Message: fmt.Sprintf(
+					"Column %s of table %s has definition %q, which does not match the pattern %s configured in option required-columns.",
+					col.Name, table.Name, def, strings.TrimPrefix(rc.pattern.String(), "(?i)"),
+				),
+			})
+		}
+	}
+	return results
+}
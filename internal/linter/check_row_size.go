@@ -0,0 +1,77 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(rowSizeChecker),
+		Name:            "row-size",
+		Description:     "Flag tables whose maximum row size or index key length exceeds MySQL or InnoDB limits",
+		DefaultSeverity: SeverityIgnore,
+	})
+}
+
+func rowSizeChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	var results []Note
+	if size := table.MaxRowSize(); size > tengo.MaxRowSize {
+		results = append(results, Note{
+			Summary: "Row size too large",
+			Message: fmt.Sprintf(
+				"Table %s has a maximum row size of %d bytes, which exceeds the limit of %d bytes. The database server will reject this table definition. Consider changing some large VARCHAR or VARBINARY columns to TEXT or BLOB, which only count towards this limit by 9 to 12 bytes each.",
+				table.Name, size, tengo.MaxRowSize,
+			),
+		})
+	} else if size := table.MaxInPageRecordSize(opts.Flavor); size > tengo.MaxInnoDBInPageRecord {
+		results = append(results, Note{
+			Summary: "InnoDB record size too large",
+			Message: fmt.Sprintf(
+				"Table %s has a maximum InnoDB record size of approximately %d bytes, which exceeds the in-page limit of %d bytes for its row format. Depending on innodb_strict_mode, the database server will either reject this table definition, or INSERT and UPDATE statements will fail for rows that are too large. Consider using ROW_FORMAT=DYNAMIC, or reducing the number or length of columns.",
+				table.Name, size, tengo.MaxInnoDBInPageRecord,
+			),
+		})
+	}
+
+	// Index key length limits only apply to InnoDB. Fulltext and spatial indexes
+	// have no such limits.
+	if table.Engine != "InnoDB" {
+		return results
+	}
+	partLimit := table.IndexPartLimit(opts.Flavor)
+	indexes := table.SecondaryIndexes
+	if table.PrimaryKey != nil {
+		indexes = append([]*tengo.Index{table.PrimaryKey}, indexes...)
+	}
+	for _, idx := range indexes {
+		if idx.Type == "FULLTEXT" || idx.Type == "SPATIAL" {
+			continue
+		}
+		var total int
+		var problem string
+		for _, part := range idx.Parts {
+			partBytes := table.IndexPartBytes(part)
+			total += partBytes
+			if partBytes > partLimit && problem == "" {
+				problem = fmt.Sprintf("column %s uses up to %d bytes in this index, which exceeds the per-column limit of %d bytes for the table's row format", part.ColumnName, partBytes, partLimit)
+			}
+		}
+		if problem == "" && total > tengo.MaxIndexKeyLarge {
+			problem = fmt.Sprintf("its key length is up to %d bytes, which exceeds the limit of %d bytes", total, tengo.MaxIndexKeyLarge)
+		}
+		if problem == "" {
+			continue
+		}
+		results = append(results, Note{
+			LineOffset: FindIndexLineOffset(idx, createStatement),
+			Summary:    "Index key too long",
+			Message: fmt.Sprintf(
+				"In table %s, index %s is too long: %s. The database server will reject this index. Consider indexing only a prefix of long string columns instead, using the col_name(length) syntax.",
+				table.Name, idx.Name, problem,
+			),
+		})
+	}
+	return results
+}
@@ -13,6 +13,7 @@ func init() {
 		Name:            "zero-date",
 		Description:     "Flag DATE, DATETIME, and TIMESTAMP columns that have zero-date default values",
 		DefaultSeverity: SeverityWarning,
+		FixerFunc:       zeroDateFixer,
 	})
 }
 
@@ -24,7 +25,7 @@ func zeroDateChecker(table *tengo.Table, createStatement string, _ *tengo.Schema
 			if strings.HasPrefix(col.Default, "'0000-00-00") {
 				summary = "Default value is zero date"
 				subject = "Zero dates"
-			} else if strings.HasPrefix(col.Default, "'0000-") || strings.Contains(col.Default, "-00") {
+			} else if hasZeroDateDefault(col) {
 				summary = "Default value contains zero in date"
 				subject = "Dates with zero year, month, or day"
 			}
@@ -45,3 +46,25 @@ func zeroDateChecker(table *tengo.Table, createStatement string, _ *tengo.Schema
 	}
 	return results
 }
+
+// hasZeroDateDefault returns true if col is a date, datetime, or timestamp
+// column with a default value containing a zero year, month, or day.
+func hasZeroDateDefault(col *tengo.Column) bool {
+	if !strings.HasPrefix(col.TypeInDB, "timestamp") && !strings.HasPrefix(col.TypeInDB, "date") {
+		return false
+	}
+	return strings.HasPrefix(col.Default, "'0000-") || strings.Contains(col.Default, "-00")
+}
+
+// zeroDateFixer changes zero-date defaults to NULL, as recommended by
+// zeroDateChecker. Only columns which are already nullable are fixed, since
+// making a NOT NULL column nullable would change more than just its default.
+func zeroDateFixer(table *tengo.Table, _ *tengo.Schema, _ Options) (fixed bool) {
+	for _, col := range table.Columns {
+		if col.Nullable && hasZeroDateDefault(col) {
+			col.Default = "NULL"
+			fixed = true
+		}
+	}
+	return fixed
+}
@@ -34,6 +34,7 @@ func AddCommandOptions(cmd *mybase.Command) {
 		if r.RelatedOption != nil {
 			cmd.AddOptions("linter rule", r.RelatedOption)
 		}
+		cmd.AddOptions("linter rule", r.RelatedOptions...)
 	}
 }
 
@@ -63,6 +63,9 @@ func TestOptionsForDir(t *testing.T) {
 		"--allow-engine=''",
 		"--lint-engine=gentle-nudge",
 		"--allow-definer=''",
+		"--lint-naming-convention=warning --naming-index='^idx_(foo'",
+		"--lint-required-columns=warning --required-columns='created_at=(timestamp'",
+		"--lint-required-columns=warning --required-columns='id,code=^char[(][0-9]{1,3}[)]'",
 	}
 	confirmError := func(cliArgs string) {
 		t.Helper()
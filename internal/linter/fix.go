@@ -0,0 +1,113 @@
+package linter
+
+import (
+	"regexp"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+// TableFixer is a function that automatically corrects problems in a table
+// which were found by the corresponding rule's checker. It is supplied a copy
+// of the table, which it should modify in-place, returning true if any changes
+// were made. Fixers should only make mechanical changes which preserve the
+// intent of the original definition, and must leave the table unchanged (and
+// return false) if there is no unambiguous correction.
+type TableFixer func(table *tengo.Table, schema *tengo.Schema, opts Options) bool
+
+// reInlineAnnotation matches special comments which may appear within a CREATE
+// TABLE, and which would be lost if the statement were regenerated.
+var reInlineAnnotation = regexp.MustCompile(`skeema:(?:deprecated|lint-ignore)\b`)
+
+// Fix represents an automatic correction to a single CREATE TABLE statement.
+type Fix struct {
+	Statement *tengo.Statement
+	RuleNames []string
+	Text      string // corrected CREATE TABLE, without delimiter or trailing newline
+}
+
+// FixSchema runs the fixers of all rules which are not configured to be
+// ignored, on each table in wsSchema which has problems found by those rules.
+// A Fix is returned for each table which was modified, in order by file
+// location. The fixes are not applied; callers must edit the corresponding
+// statements, and should verify the new statements in a workspace.
+// Fixers are not run for rules whose annotations are all suppressed by
+// skeema:lint-ignore comments on the table. Since fixed statements are
+// regenerated without any comments, statements containing inline
+// skeema:deprecated or skeema:lint-ignore comments are never fixed; a warning
+// is logged instead.
+func FixSchema(wsSchema *workspace.Schema, opts Options) []Fix {
+	ruleNames := make([]string, 0, len(opts.RuleSeverity))
+	for ruleName, severity := range opts.RuleSeverity {
+		if severity != SeverityIgnore && rulesByName[ruleName].FixerFunc != nil {
+			ruleNames = append(ruleNames, ruleName)
+		}
+	}
+	sort.Strings(ruleNames)
+
+	var fixes []Fix
+	tables := wsSchema.TablesByName()
+	for key, stmt := range wsSchema.LogicalSchema.Creates {
+		table := tables[key.Name]
+		if key.Type != tengo.ObjectTypeTable || table == nil || table.UnsupportedDDL || opts.shouldIgnore(table) {
+			continue
+		}
+		suppressions := parseSuppressions(wsSchema.LogicalSchema.LeadingComments[key], stmt.Text)
+		fixed := cloneTable(table)
+		fix := Fix{Statement: stmt}
+		for _, ruleName := range ruleNames {
+			r := rulesByName[ruleName]
+			var found bool
+			for _, note := range r.CheckerFunc.CheckObject(table, stmt.Text, wsSchema.Schema, opts) {
+				if findSuppression(suppressions, ruleName, note.LineOffset) == nil {
+					found = true
+					break
+				}
+			}
+			if found && r.FixerFunc(fixed, wsSchema.Schema, opts) {
+				fix.RuleNames = append(fix.RuleNames, ruleName)
+			}
+		}
+		if len(fix.RuleNames) > 0 && reInlineAnnotation.MatchString(stmt.Text) {
+			log.Warnf("Skipping automatic fix of %s at %s: statement contains skeema:deprecated or skeema:lint-ignore comments, which would be removed. Please fix this statement manually.", key, stmt.Location())
+		} else if len(fix.RuleNames) > 0 {
+			fix.Text = fixed.GeneratedCreateStatement(opts.Flavor)
+			fixes = append(fixes, fix)
+		}
+	}
+	sort.Slice(fixes, func(i, j int) bool {
+		if fixes[i].Statement.File != fixes[j].Statement.File {
+			return fixes[i].Statement.File < fixes[j].Statement.File
+		}
+		return fixes[i].Statement.LineNo < fixes[j].Statement.LineNo
+	})
+	return fixes
+}
+
+// cloneTable returns a copy of table which may be safely modified by a
+// TableFixer. Columns and indexes are deep-copied; other fields which fixers do
+// not modify, such as foreign keys and partitioning, are shared with the
+// original.
+func cloneTable(table *tengo.Table) *tengo.Table {
+	clone := *table
+	clone.Columns = make([]*tengo.Column, len(table.Columns))
+	for n, col := range table.Columns {
+		colCopy := *col
+		clone.Columns[n] = &colCopy
+	}
+	cloneIndex := func(idx *tengo.Index) *tengo.Index {
+		idxCopy := *idx
+		idxCopy.Parts = append([]tengo.IndexPart(nil), idx.Parts...)
+		return &idxCopy
+	}
+	if table.PrimaryKey != nil {
+		clone.PrimaryKey = cloneIndex(table.PrimaryKey)
+	}
+	clone.SecondaryIndexes = make([]*tengo.Index, len(table.SecondaryIndexes))
+	for n, idx := range table.SecondaryIndexes {
+		clone.SecondaryIndexes[n] = cloneIndex(idx)
+	}
+	return &clone
+}
@@ -0,0 +1,137 @@
+package linter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+// fixTestSchema returns a workspace.Schema containing a single MyISAM table
+// which has problems that can be fixed by several rules.
+func fixTestSchema() *workspace.Schema {
+	table := &tengo.Table{
+		Name:               "legacy",
+		Engine:             "MyISAM",
+		CharSet:            "utf8",
+		Collation:          "utf8_general_ci",
+		CollationIsDefault: true,
+		Columns: []*tengo.Column{
+			{Name: "id", TypeInDB: "int(5) unsigned"},
+			{Name: "code", TypeInDB: "varchar(20)", CharSet: "utf8", Collation: "utf8_general_ci", CollationIsDefault: true},
+			{Name: "created", TypeInDB: "date", Nullable: true, Default: "'0000-00-00'"},
+			{Name: "updated", TypeInDB: "date", Default: "'0000-00-00'"},
+			{Name: "flag", TypeInDB: "tinyint(1)", Nullable: true, Default: "NULL"},
+		},
+		SecondaryIndexes: []*tengo.Index{
+			{Name: "code", Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "code"}}},
+			{Name: "flag", Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "flag"}}},
+		},
+	}
+	table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL57)
+	stmt := &tengo.Statement{
+		File:       "legacy.sql",
+		LineNo:     1,
+		Text:       table.CreateStatement + ";\n",
+		Type:       tengo.StatementTypeCreate,
+		ObjectType: tengo.ObjectTypeTable,
+		ObjectName: table.Name,
+		Delimiter:  ";",
+	}
+	logicalSchema := fs.NewLogicalSchema()
+	logicalSchema.AddStatement(stmt)
+	return &workspace.Schema{
+		Schema:        &tengo.Schema{Name: "fixtest", Tables: []*tengo.Table{table}},
+		LogicalSchema: logicalSchema,
+	}
+}
+
+func fixTestOptions() Options {
+	opts := Options{
+		RuleSeverity: make(map[string]Severity),
+		RuleConfig: map[string]interface{}{
+			"charset": []string{"latin1", "utf8mb4"},
+			"engine":  []string{"innodb"},
+		},
+		Flavor: tengo.FlavorMySQL57,
+	}
+	for _, ruleName := range []string{"charset", "display-width", "engine", "pk", "zero-date"} {
+		opts.RuleSeverity[ruleName] = SeverityWarning
+	}
+	return opts
+}
+
+func TestFixSchema(t *testing.T) {
+	wsSchema := fixTestSchema()
+	origCreate := wsSchema.Tables[0].CreateStatement
+	fixes := FixSchema(wsSchema, fixTestOptions())
+	if len(fixes) != 1 {
+		t.Fatalf("Expected 1 fix, instead found %d", len(fixes))
+	}
+	expectRules := []string{"charset", "display-width", "engine", "pk", "zero-date"}
+	if !reflect.DeepEqual(fixes[0].RuleNames, expectRules) {
+		t.Errorf("Expected fix rule names %v, instead found %v", expectRules, fixes[0].RuleNames)
+	}
+	for _, expect := range []string{
+		"`id` int(10) unsigned NOT NULL,",
+		"`code` varchar(20) COLLATE utf8mb4_general_ci NOT NULL,",
+		"`created` date DEFAULT NULL,",
+		"`updated` date NOT NULL DEFAULT '0000-00-00',",
+		"`flag` tinyint(1) DEFAULT NULL,",
+		"PRIMARY KEY (`code`),",
+		"UNIQUE KEY `flag` (`flag`)",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci",
+	} {
+		if !strings.Contains(fixes[0].Text, expect) {
+			t.Errorf("Expected fixed statement to contain %q, but it did not. Full statement:\n%s", expect, fixes[0].Text)
+		}
+	}
+	if wsSchema.Tables[0].GeneratedCreateStatement(tengo.FlavorMySQL57) != origCreate {
+		t.Error("FixSchema unexpectedly modified the original table")
+	}
+
+	// Rules which are ignored or suppressed should not be fixed. The pk fixer
+	// should not act if there are multiple eligible unique keys.
+	wsSchema = fixTestSchema()
+	wsSchema.LogicalSchema.LeadingComments[wsSchema.Tables[0].ObjectKey()] = "-- skeema:lint-ignore engine"
+	wsSchema.Tables[0].Columns[4].Nullable = false
+	opts := fixTestOptions()
+	opts.RuleSeverity["charset"] = SeverityIgnore
+	fixes = FixSchema(wsSchema, opts)
+	expectRules = []string{"display-width", "zero-date"}
+	if len(fixes) != 1 {
+		t.Fatalf("Expected 1 fix, instead found %d", len(fixes))
+	} else if !reflect.DeepEqual(fixes[0].RuleNames, expectRules) {
+		t.Errorf("Expected fix rule names %v, instead found %v", expectRules, fixes[0].RuleNames)
+	}
+	if strings.Contains(fixes[0].Text, "utf8mb4") || strings.Contains(fixes[0].Text, "InnoDB") || strings.Contains(fixes[0].Text, "PRIMARY KEY") {
+		t.Errorf("Fixed statement contains unexpected changes:\n%s", fixes[0].Text)
+	}
+
+	// Statements with inline skeema:deprecated or skeema:lint-ignore comments
+	// must not be regenerated, since the comments would be lost
+	for _, comment := range []string{"-- skeema:deprecated", "/* skeema:lint-ignore pk */"} {
+		wsSchema = fixTestSchema()
+		stmt := wsSchema.LogicalSchema.Creates[wsSchema.Tables[0].ObjectKey()]
+		stmt.Text = strings.Replace(stmt.Text, "`flag` tinyint(1) DEFAULT NULL,", "`flag` tinyint(1) DEFAULT NULL, "+comment, 1)
+		if !strings.Contains(stmt.Text, comment) {
+			t.Fatalf("Test setup failed to insert comment into statement:\n%s", stmt.Text)
+		}
+		if fixes = FixSchema(wsSchema, fixTestOptions()); len(fixes) != 0 {
+			t.Errorf("Expected no fixes for statement containing %q, instead found %d", comment, len(fixes))
+		}
+	}
+
+	// No fixes for a table without problems
+	wsSchema = fixTestSchema()
+	opts = fixTestOptions()
+	for ruleName := range opts.RuleSeverity {
+		opts.RuleSeverity[ruleName] = SeverityIgnore
+	}
+	if fixes = FixSchema(wsSchema, opts); len(fixes) != 0 {
+		t.Errorf("Expected no fixes with all rules ignored, instead found %d", len(fixes))
+	}
+}
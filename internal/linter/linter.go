@@ -25,6 +25,7 @@ func CheckSchema(wsSchema *workspace.Schema, opts Options) *Result {
 		if !ok || opts.shouldIgnore(object) {
 			continue
 		}
+		suppressions := parseSuppressions(wsSchema.LogicalSchema.LeadingComments[key], stmt.Text)
 		for ruleName, severity := range opts.RuleSeverity {
 			if severity == SeverityIgnore {
 				continue
@@ -35,7 +36,11 @@ func CheckSchema(wsSchema *workspace.Schema, opts Options) *Result {
 				if opts.StripAnnotationNewlines {
 					lo.Message = strings.ReplaceAll(lo.Message, "\n", " ")
 				}
-				result.Annotate(stmt, severity, ruleName, lo)
+				if s := findSuppression(suppressions, ruleName, lo.LineOffset); s != nil {
+					result.Suppress(stmt, ruleName, lo, s.reason)
+				} else {
+					result.Annotate(stmt, severity, ruleName, lo)
+				}
 			}
 		}
 	}
@@ -114,8 +119,11 @@ type Rule struct {
 	Name            string
 	Description     string
 	DefaultSeverity Severity
-	RelatedOption   *mybase.Option // for rules that have supplemental options, e.g. list of allowed values
+	RelatedOption   *mybase.Option   // for rules that have supplemental options, e.g. list of allowed values
+	RelatedOptions  []*mybase.Option // for rules that have several supplemental options
 	ConfigFunc      RuleConfigFunc
+	FixerFunc       TableFixer // optional; used by `skeema lint --fix`
+	Broad           bool       // true if rule flags most objects in a typical schema, e.g. requiring a comment on every column
 }
 
 // RelatedListOption populates RelatedOption and ConfigFunc by creating a
@@ -76,7 +76,7 @@ func (s IntegrationSuite) TestCheckSchema(t *testing.T) {
 		// don't otherwise annotate failures here; testing of that logic is handled
 		// in TestResultAnnotateStatementErrors() in result_test.go.
 		for _, err := range wsSchema.Failures {
-			t.Errorf(err.Error())
+			t.Error(err.Error())
 		}
 		t.Fatalf("Expected 2 creation failures from %s/*.sql, instead found %d (see above errors)", dir, len(wsSchema.Failures))
 	}
@@ -94,34 +94,20 @@ func (s IntegrationSuite) TestCheckSchema(t *testing.T) {
 // and would generate too many annotations on the table definitions used
 // by TestCheckSchema.
 func (s IntegrationSuite) TestCheckSchemaHidden(t *testing.T) {
-	dir := getDir(t, "testdata/hidden")
-	// Set specific hidden rules to warning level
-	forceOnlyRulesWarning(dir.Config, "nullable", "ids")
-	opts, err := OptionsForDir(dir)
-	if err != nil {
-		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
-	}
-
-	// There's intentionally no hardcoded flavor value in testdata/hidden/.skeema
-	// so that we can force the value corresponding to the current Dockerized
-	// test db here
-	opts.Flavor = s.d.Flavor()
+	s.checkDirWithRules(t, "testdata/hidden", "nullable", "ids")
+}
 
-	logicalSchema := dir.LogicalSchemas[0]
-	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
-	if err != nil {
-		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
-	}
-	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
-	if err != nil {
-		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
-	} else if len(wsSchema.Failures) > 0 {
-		t.Fatalf("Unexpectedly found %d failing CREATE statements in %s/*.sql", len(wsSchema.Failures), dir)
-	}
+// TestCheckSchemaNamingConvention runs the naming-convention checker against
+// the dir ./testdata/naming, which configures patterns in its .skeema file.
+func (s IntegrationSuite) TestCheckSchemaNamingConvention(t *testing.T) {
+	s.checkDirWithRules(t, "testdata/naming", "naming-convention")
+}
 
-	result := CheckSchema(wsSchema, opts)
-	expected := expectedAnnotations(logicalSchema, s.d.Flavor())
-	compareAnnotations(t, expected, result)
+// TestCheckSchemaPolicy runs the required-columns, table-comment, and
+// column-comment checkers against the dir ./testdata/policy, which configures
+// their supplemental options in its .skeema file.
+func (s IntegrationSuite) TestCheckSchemaPolicy(t *testing.T) {
+	s.checkDirWithRules(t, "testdata/policy", "required-columns", "table-comment", "column-comment")
 }
 
 // TestCheckSchemaCompression provides additional coverage for code paths and
@@ -396,6 +382,41 @@ func getDir(t *testing.T, dirPath string, cliArgs ...string) *fs.Dir {
 	return dir
 }
 
+// checkDirWithRules runs only the named linter rules against the dir at
+// dirPath, and compares the result to the annotations expected by special
+// inline comments in its CREATE statements. See expectedAnnotations() for more
+// information.
+func (s IntegrationSuite) checkDirWithRules(t *testing.T, dirPath string, ruleNames ...string) {
+	t.Helper()
+	dir := getDir(t, dirPath)
+	forceOnlyRulesWarning(dir.Config, ruleNames...)
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+
+	// There's intentionally no hardcoded flavor value in the test dirs' .skeema
+	// files so that we can force the value corresponding to the current
+	// Dockerized test db here
+	opts.Flavor = s.d.Flavor()
+
+	logicalSchema := dir.LogicalSchemas[0]
+	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+	} else if len(wsSchema.Failures) > 0 {
+		t.Fatalf("Unexpectedly found %d failing CREATE statements in %s/*.sql", len(wsSchema.Failures), dir)
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	expected := expectedAnnotations(logicalSchema, s.d.Flavor())
+	compareAnnotations(t, expected, result)
+}
+
 // expectedAnnotations looks for comments in the supplied LogicalSchema's
 // CREATE statements of the form "/* annotations:rulename,rulename,... */".
 // These comments indicate annotations that are expected on this line. The
@@ -467,13 +488,14 @@ func compareAnnotations(t *testing.T, expected []*Annotation, actualResult *Resu
 // that aren't enabled by default.
 // Hidden rules are excluded because they may be overly broad / affect too many
 // "normal" tables when enabled. Such rules must be tested separately (outside
-// of IntegrationSuite.TestCheckSchema for example).
+// of IntegrationSuite.TestCheckSchema for example). Broad rules are excluded
+// for the same reason.
 // This must be called *prior* to OptionsForDir or any other logic that converts
 // a mybase.Config into a linter.Options. Otherwise, supplemental options via
 // Rule.RelatedOption may not be configured properly.
 func forceRulesWarning(cfg *mybase.Config) {
 	for _, rule := range rulesByName {
-		if !rule.hidden() {
+		if !rule.hidden() && !rule.Broad {
 			cfg.SetRuntimeOverride(rule.optionName(), string(SeverityWarning))
 		}
 	}
@@ -111,15 +111,32 @@ func FindColumnLineOffset(col *tengo.Column, createStatement string) int {
 	return FindFirstLineOffset(re, createStatement)
 }
 
+// FindIndexLineOffset returns the line offset (i.e. line number starting at 0)
+// for the definition of the supplied Index within createStatement. If no
+// match occurs, 0 is returned.
+// This is useful for ObjectCheckers when populating Note.LineOffset.
+func FindIndexLineOffset(idx *tengo.Index, createStatement string) int {
+	var re *regexp.Regexp
+	if idx.PrimaryKey {
+		re = regexp.MustCompile(`(?i)primary\s+key`)
+	} else {
+		re = regexp.MustCompile("(?i)(?:key|index)\\s+`?" + regexp.QuoteMeta(idx.Name) + "(?:`|\\s|\\()")
+	}
+	return FindFirstLineOffset(re, createStatement)
+}
+
 // Result is a combined set of linter annotations and/or Golang errors found
 // when linting a directory and its subdirs.
 type Result struct {
-	Annotations   []*Annotation
-	DebugLogs     []string
-	Exceptions    []error
-	ErrorCount    int
-	WarningCount  int
-	ReformatCount int
+	Annotations     []*Annotation
+	DebugLogs       []string
+	Exceptions      []error
+	ErrorCount      int
+	WarningCount    int
+	ReformatCount   int
+	SuppressedCount int // annotations suppressed by skeema:lint-ignore comments
+	BaselinedCount  int // annotations removed due to presence in a baseline file
+	FixCount        int // statements automatically fixed
 }
 
 // Annotate constructs an annotation on the supplied statement, and stores it
@@ -140,6 +157,18 @@ func (r *Result) Annotate(stmt *tengo.Statement, sev Severity, ruleName string,
 	r.Annotations = append(r.Annotations, annotation)
 }
 
+// Suppress tracks an annotation which was suppressed by a skeema:lint-ignore
+// comment. The annotation is not stored in the result, but it is counted, and
+// a debug message is logged.
+func (r *Result) Suppress(stmt *tengo.Statement, ruleName string, note Note, reason string) {
+	r.SuppressedCount++
+	a := &Annotation{RuleName: ruleName, Statement: stmt, Note: note}
+	if reason != "" {
+		reason = " (reason: " + reason + ")"
+	}
+	r.Debug("Suppressed lint-%s annotation at %s due to skeema:lint-ignore comment%s: %s", ruleName, a.Location(), reason, note.Summary)
+}
+
 var reSyntaxErrorLine = regexp.MustCompile(`(?s) the right syntax to use near '.*' at line (\d+)`)
 
 // AnnotateStatementErrors converts any supplied workspace.StatementError values
@@ -239,6 +268,9 @@ func (r *Result) Merge(other *Result) {
 	r.ErrorCount += other.ErrorCount
 	r.WarningCount += other.WarningCount
 	r.ReformatCount += other.ReformatCount
+	r.SuppressedCount += other.SuppressedCount
+	r.BaselinedCount += other.BaselinedCount
+	r.FixCount += other.FixCount
 }
 
 // SortByFile sorts the error, warning and format notice messages according
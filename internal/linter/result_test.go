@@ -47,14 +47,15 @@ func TestResultMerge(t *testing.T) {
 	r2.Annotate(nil, SeverityWarning, "", Note{})
 	r1.Annotate(nil, SeverityError, "", Note{})
 	r2.Debug("something unimportant")
+	r2.Suppress(&tengo.Statement{File: "foo.sql", LineNo: 3}, "pk", Note{Summary: "No primary key"}, "legacy")
 	r2.Fatal(fmt.Errorf("goodbye"))
 
 	r1.Merge(nil) // should be a no-op
 	r1.Merge(r2)
-	if len(r1.Annotations) != 5 || len(r1.DebugLogs) != 3 || len(r1.Exceptions) != 1 {
+	if len(r1.Annotations) != 5 || len(r1.DebugLogs) != 4 || len(r1.Exceptions) != 1 {
 		t.Errorf("Unexpected slice counts in %+v", *r1)
 	}
-	if r1.ErrorCount != 3 || r1.WarningCount != 2 || r1.ReformatCount != 3 {
+	if r1.ErrorCount != 3 || r1.WarningCount != 2 || r1.ReformatCount != 3 || r1.SuppressedCount != 1 {
 		t.Errorf("Unexpected count fields in %+v", *r1)
 	}
 }
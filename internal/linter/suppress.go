@@ -0,0 +1,80 @@
+package linter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Annotations may be suppressed using inline comments of the form
+// "skeema:lint-ignore rule1,rule2 reason="explanation"". If the comment
+// appears in the comment lines immediately preceding a CREATE, it applies to
+// the entire statement. If it appears within the CREATE, it applies to the line
+// containing the comment, or to the next line if the comment is on a line by
+// itself. Omitting the list of rules suppresses all rules.
+// Reformatting a CREATE to its canonical form (e.g. via `skeema format`, or
+// `skeema lint` without --skip-format) removes comments within the CREATE, so
+// comments preceding the CREATE are the more durable form.
+
+var (
+	reLintIgnore       = regexp.MustCompile(`skeema:lint-ignore\b([^\n]*)`)
+	reLintIgnoreReason = regexp.MustCompile(`reason\s*=\s*"([^"]*)"`)
+)
+
+// suppression represents a single skeema:lint-ignore comment.
+type suppression struct {
+	ruleNames  map[string]bool // nil if all rules are suppressed
+	reason     string
+	lineOffset int // -1 if the entire statement is affected
+}
+
+// parseSuppression parses the portion of a skeema:lint-ignore comment after
+// the "skeema:lint-ignore" keyword.
+func parseSuppression(args string, lineOffset int) suppression {
+	s := suppression{lineOffset: lineOffset}
+	if pos := strings.Index(args, "*/"); pos >= 0 {
+		args = args[:pos]
+	}
+	if matches := reLintIgnoreReason.FindStringSubmatch(args); matches != nil {
+		s.reason = matches[1]
+		args = strings.Replace(args, matches[0], "", 1)
+	}
+	for _, name := range strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\r' }) {
+		if s.ruleNames == nil {
+			s.ruleNames = make(map[string]bool)
+		}
+		s.ruleNames[strings.TrimPrefix(strings.ToLower(name), "lint-")] = true
+	}
+	return s
+}
+
+// parseSuppressions returns all skeema:lint-ignore comments in the supplied
+// leading comment and text of a CREATE statement.
+func parseSuppressions(leadingComment, createStatement string) (result []suppression) {
+	for _, matches := range reLintIgnore.FindAllStringSubmatch(leadingComment, -1) {
+		result = append(result, parseSuppression(matches[1], -1))
+	}
+	for offset, line := range strings.Split(createStatement, "\n") {
+		loc := reLintIgnore.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		s := parseSuppression(line[loc[2]:loc[3]], offset)
+		switch strings.TrimSpace(line[:loc[0]]) {
+		case "--", "#", "/*":
+			s.lineOffset++ // comment on its own line applies to the next line
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// findSuppression returns the first suppression which applies to an
+// annotation for ruleName at lineOffset, or nil if there is none.
+func findSuppression(suppressions []suppression, ruleName string, lineOffset int) *suppression {
+	for n, s := range suppressions {
+		if (s.lineOffset < 0 || s.lineOffset == lineOffset) && (s.ruleNames == nil || s.ruleNames[ruleName]) {
+			return &suppressions[n]
+		}
+	}
+	return nil
+}
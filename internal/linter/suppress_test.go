@@ -0,0 +1,50 @@
+package linter
+
+import (
+	"testing"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	leading := "-- unrelated comment\n-- skeema:lint-ignore pk, lint-has-float reason=\"legacy table\""
+	create := "CREATE TABLE foo ( /* skeema:lint-ignore engine */\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  -- skeema:lint-ignore\n" +
+		"  amount float,\n" +
+		"  # skeema:lint-ignore charset,has-enum\n" +
+		"  name varchar(30) CHARACTER SET latin1\n" +
+		") ENGINE=MyISAM;\n"
+	suppressions := parseSuppressions(leading, create)
+	if len(suppressions) != 4 {
+		t.Fatalf("Expected 4 suppressions, instead found %d: %+v", len(suppressions), suppressions)
+	}
+	if s := suppressions[0]; s.lineOffset != -1 || s.reason != "legacy table" || len(s.ruleNames) != 2 || !s.ruleNames["pk"] || !s.ruleNames["has-float"] {
+		t.Errorf("Unexpected suppression from leading comment: %+v", s)
+	}
+
+	cases := []struct {
+		ruleName   string
+		lineOffset int
+		expected   bool
+	}{
+		{"pk", 0, true},
+		{"pk", 5, true},
+		{"has-float", 3, true},
+		{"engine", 0, true},
+		{"engine", 6, false},
+		{"nullable", 3, true},
+		{"nullable", 2, false},
+		{"charset", 5, true},
+		{"charset", 4, false},
+		{"has-enum", 5, true},
+		{"dupe-index", 5, false},
+	}
+	for _, c := range cases {
+		if actual := findSuppression(suppressions, c.ruleName, c.lineOffset) != nil; actual != c.expected {
+			t.Errorf("Expected findSuppression(%q, %d) to return suppression=%t, instead found %t", c.ruleName, c.lineOffset, c.expected, actual)
+		}
+	}
+
+	if suppressions := parseSuppressions("", "CREATE TABLE foo (id int);\n"); len(suppressions) != 0 {
+		t.Errorf("Expected no suppressions, instead found %+v", suppressions)
+	}
+}
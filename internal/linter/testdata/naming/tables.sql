@@ -0,0 +1,45 @@
+# Tables and routines testing behavior of the naming-convention rule, using
+# the naming-* options in this dir's .skeema file.
+
+CREATE TABLE authors (
+	id int unsigned NOT NULL,
+	name varchar(60) NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uk_authors_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE Books ( /* annotations: naming-convention */
+	id int unsigned NOT NULL,
+	author_id int unsigned NOT NULL,
+	editor_id int unsigned NOT NULL,
+	Title varchar(100) NOT NULL, /* annotations: naming-convention */
+	summary text,
+	PRIMARY KEY (id),
+	KEY idx_Books_author (author_id),
+	KEY idx_authors_editor (editor_id), /* annotations: naming-convention */
+	UNIQUE KEY title (Title), /* annotations: naming-convention */
+	FULLTEXT KEY ft_summary (summary),
+	CONSTRAINT fk_Books_authors_author FOREIGN KEY (author_id) REFERENCES authors (id),
+	CONSTRAINT editor_fk FOREIGN KEY (editor_id) REFERENCES authors (id) /* annotations: naming-convention */
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+# Stored functions are not checked, since naming-func is not set in .skeema
+DELIMITER //
+
+CREATE PROCEDURE sp_ok(a int)
+BEGIN
+	SELECT a;
+END//
+
+CREATE FUNCTION anyname(a int) RETURNS int
+    DETERMINISTIC
+BEGIN
+	return a;
+END//
+
+CREATE PROCEDURE badproc(a int) /* annotations: naming-convention */
+BEGIN
+	SELECT a;
+END//
+
+DELIMITER ;
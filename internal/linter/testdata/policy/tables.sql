@@ -0,0 +1,24 @@
+# Tables testing behavior of the required-columns, table-comment, and
+# column-comment rules, using the options in this dir's .skeema file.
+
+CREATE TABLE good_table (
+	id int unsigned NOT NULL,
+	name varchar(30) NOT NULL COMMENT 'Display name',
+	created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Table with everything required';
+
+CREATE TABLE bad_table ( /* annotations: required-columns, table-comment */
+	id int unsigned NOT NULL,
+	name varchar(30) NOT NULL, /* annotations: column-comment */
+	created_at date NOT NULL, /* annotations: required-columns */
+	PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE legacy_log (
+	id int unsigned NOT NULL,
+	created_at datetime NOT NULL,
+	updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
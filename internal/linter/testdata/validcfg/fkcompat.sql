@@ -0,0 +1,28 @@
+CREATE TABLE fk_parent (
+  id int unsigned NOT NULL,
+  pcode varchar(20) NOT NULL,
+  pname varchar(40) DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY pcode (pcode)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE fk_child_ok (
+  id int unsigned NOT NULL,
+  parent_id int(10) unsigned DEFAULT NULL,
+  parent_code varchar(20) DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY parent_id (parent_id),
+  KEY parent_code (parent_code),
+  CONSTRAINT ok_id FOREIGN KEY (parent_id) REFERENCES fk_parent (id), /* annotations: has-fk */
+  CONSTRAINT ok_code FOREIGN KEY (parent_code) REFERENCES fk_parent (pcode)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+# The server permits string columns of differing lengths, but this is still
+# flagged, since values may not fit in the referenced column
+CREATE TABLE fk_child_len (
+  id int unsigned NOT NULL,
+  parent_code varchar(30) DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY parent_code (parent_code),
+  CONSTRAINT bad_len FOREIGN KEY (parent_code) REFERENCES fk_parent (pcode) /* annotations: has-fk, fk-compat */
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
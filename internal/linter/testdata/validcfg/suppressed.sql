@@ -0,0 +1,9 @@
+-- skeema:lint-ignore pk reason="legacy table"
+CREATE TABLE suppressed_nopk (
+  id int unsigned NOT NULL,
+  amount float DEFAULT NULL, -- skeema:lint-ignore has-float
+  price double DEFAULT NULL, /* annotations: has-float */
+  -- skeema:lint-ignore
+  weight float DEFAULT NULL,
+  KEY id (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
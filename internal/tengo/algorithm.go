@@ -0,0 +1,329 @@
+package tengo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AlterAlgorithm represents the least disruptive ALGORITHM that a database
+// server is expected to accept for an ALTER TABLE. The constants are ordered
+// from least to most expensive, allowing predictions for individual clauses to
+// be combined by taking the maximum value.
+type AlterAlgorithm int
+
+// Constants enumerating ALTER TABLE algorithms
+const (
+	AlterAlgorithmUnknown AlterAlgorithm = iota // prediction not possible, e.g. unknown flavor
+	AlterAlgorithmInstant                       // metadata-only change
+	AlterAlgorithmInplace                       // no table copy, but may still rebuild the table in-place
+	AlterAlgorithmCopy                          // full copy of the table
+)
+
+// String returns the ALGORITHM clause value corresponding to the receiver, or
+// an empty string for AlterAlgorithmUnknown.
+func (algo AlterAlgorithm) String() string {
+	switch algo {
+	case AlterAlgorithmInstant:
+		return "INSTANT"
+	case AlterAlgorithmInplace:
+		return "INPLACE"
+	case AlterAlgorithmCopy:
+		return "COPY"
+	default:
+		return ""
+	}
+}
+
+// PredictAlgorithm returns the least expensive ALTER TABLE algorithm that the
+// database server is expected to accept for the receiver, based on the
+// clauses present and the Flavor in mods. This is only a prediction based on
+// documented server behavior; the server may still choose a more expensive
+// algorithm due to factors not visible in the table definition.
+// AlterAlgorithmUnknown is returned for non-ALTER diffs, diffs which generate
+// no DDL, or flavors which are not known.
+// Foreign key additions are predicted as in-place, since this package's
+// callers run DDL with foreign_key_checks=0 by default.
+func (td *TableDiff) PredictAlgorithm(mods StatementModifiers) AlterAlgorithm {
+	if td == nil || td.Type != DiffTypeAlter || !mods.Flavor.Known() {
+		return AlterAlgorithmUnknown
+	}
+	if strings.EqualFold(mods.AlgorithmClause, "copy") {
+		return AlterAlgorithmCopy
+	}
+
+	// Dropping and re-adding the primary key in the same ALTER can be done in-
+	// place, whereas dropping it alone requires a copy
+	var addingPK bool
+	for _, clause := range td.alterClauses {
+		if ai, ok := clause.(AddIndex); ok && ai.Index.PrimaryKey {
+			addingPK = true
+		}
+	}
+
+	result := AlterAlgorithmUnknown
+	for _, clause := range td.alterClauses {
+		if clause.Clause(mods) == "" {
+			continue
+		}
+		algo := predictClauseAlgorithm(clause, td.From, mods.Flavor)
+		if dropIndex, ok := clause.(DropIndex); ok && dropIndex.Index.PrimaryKey && addingPK {
+			algo = AlterAlgorithmInplace
+		}
+		if algo > result {
+			result = algo
+		}
+	}
+	return result
+}
+
+// predictClauseAlgorithm returns the least expensive algorithm permitted for a
+// single clause of an ALTER TABLE on table, for the supplied flavor.
+func predictClauseAlgorithm(clause TableAlterClause, table *Table, flavor Flavor) AlterAlgorithm {
+	// Storage engines other than InnoDB generally don't support online DDL
+	if table.Engine != "InnoDB" {
+		return AlterAlgorithmCopy
+	}
+
+	// MySQL 5.5 predates the ALGORITHM clause, but InnoDB can still add and drop
+	// secondary indexes without a copy ("fast index creation")
+	if flavor.IsMySQL() && !flavor.Min(FlavorMySQL56) {
+		switch clause := clause.(type) {
+		case AddIndex:
+			if !clause.Index.PrimaryKey {
+				return AlterAlgorithmInplace
+			}
+		case DropIndex:
+			if !clause.Index.PrimaryKey {
+				return AlterAlgorithmInplace
+			}
+		}
+		return AlterAlgorithmCopy
+	}
+
+	switch clause := clause.(type) {
+	case AddColumn:
+		if clause.Column.GenerationExpr != "" {
+			if !clause.Column.Virtual {
+				return AlterAlgorithmCopy
+			} else if flavor.Min(FlavorMySQL80) {
+				return AlterAlgorithmInstant
+			}
+			return AlterAlgorithmInplace
+		}
+		if clause.Column.AutoIncrement {
+			return AlterAlgorithmInplace
+		}
+		positioned := clause.PositionFirst || clause.PositionAfter != nil
+		if instantColumnChanges(table, flavor, positioned) {
+			return AlterAlgorithmInstant
+		}
+		return AlterAlgorithmInplace
+	case DropColumn:
+		if clause.Column.Virtual {
+			if flavor.Min(FlavorMySQL80) {
+				return AlterAlgorithmInstant
+			}
+			return AlterAlgorithmInplace
+		}
+		if clause.Column.GenerationExpr == "" && instantColumnChanges(table, flavor, true) {
+			return AlterAlgorithmInstant
+		}
+		return AlterAlgorithmInplace
+	case ModifyColumn:
+		return predictModifyColumnAlgorithm(clause, table, flavor)
+	case AddIndex:
+		if clause.Index.Type == "SPATIAL" && !flavor.Min(FlavorMySQL57) && !flavor.IsMariaDB() {
+			return AlterAlgorithmCopy
+		}
+		return AlterAlgorithmInplace
+	case DropIndex:
+		if clause.Index.PrimaryKey {
+			return AlterAlgorithmCopy
+		}
+		return AlterAlgorithmInplace
+	case AddCheck, PartitionBy, RemovePartitioning, ChangeStorageEngine, ChangeTablespace:
+		return AlterAlgorithmCopy
+	case AlterCheck:
+		if clause.NewEnforcement {
+			return AlterAlgorithmCopy
+		}
+		return AlterAlgorithmInplace
+	default:
+		// AlterIndex, AddForeignKey, DropForeignKey, DropCheck, ChangeAutoIncrement,
+		// ChangeCharSet, ChangeCreateOptions, ChangeComment, ModifyPartitions, and
+		// RenameColumn are all permitted in-place
+		return AlterAlgorithmInplace
+	}
+}
+
+// instantColumnChanges returns true if the flavor permits instant addition of
+// columns to table. If positioned is true, the column addition is anywhere
+// other than the end of the table, or is a column drop.
+func instantColumnChanges(table *Table, flavor Flavor, positioned bool) bool {
+	if table.RowFormatClause() == "COMPRESSED" {
+		return false
+	}
+	for _, idx := range table.SecondaryIndexes {
+		if idx.Type == "FULLTEXT" {
+			return false
+		}
+	}
+	if positioned {
+		return flavor.Min(FlavorMySQL80.Dot(29)) || flavor.Min(FlavorMariaDB104)
+	}
+	return flavor.Min(FlavorMySQL80.Dot(12)) || flavor.Min(FlavorMariaDB103.Dot(2))
+}
+
+// predictModifyColumnAlgorithm handles algorithm prediction for ModifyColumn
+// clauses, which vary widely based on the nature of the column change.
+func predictModifyColumnAlgorithm(mc ModifyColumn, table *Table, flavor Flavor) AlterAlgorithm {
+	oldCol, newCol := *mc.OldColumn, *mc.NewColumn
+	if oldCol.GenerationExpr != newCol.GenerationExpr || oldCol.Virtual != newCol.Virtual || oldCol.CharSet != newCol.CharSet || oldCol.AutoIncrement != newCol.AutoIncrement {
+		return AlterAlgorithmCopy
+	}
+	positioned := mc.PositionFirst || mc.PositionAfter != nil
+	if positioned && !flavor.Min(FlavorMariaDB104) {
+		// Column reordering always rebuilds the table in MySQL, so there's no
+		// benefit in examining the rest of the column definition more closely
+		if oldCol.TypeInDB != newCol.TypeInDB && predictColumnTypeAlgorithm(oldCol, newCol, flavor) == AlterAlgorithmCopy {
+			return AlterAlgorithmCopy
+		}
+		return AlterAlgorithmInplace
+	}
+
+	// Changes purely to metadata (default value, comment, and in MySQL the column
+	// visibility) can be made instantly in modern flavors
+	metadataOnly := func(a, b Column) bool {
+		a.Default, a.Comment = b.Default, b.Comment
+		if flavor.IsMySQL() {
+			a.Invisible = b.Invisible
+		}
+		return a.Equivalent(&b)
+	}
+	var algo AlterAlgorithm
+	if metadataOnly(oldCol, newCol) {
+		if flavor.Min(FlavorMySQL80) || flavor.Min(FlavorMariaDB103) {
+			algo = AlterAlgorithmInstant
+		} else {
+			algo = AlterAlgorithmInplace
+		}
+	} else if oldCol.Nullable != newCol.Nullable {
+		algo = AlterAlgorithmInplace
+		if strings.EqualFold(oldCol.TypeInDB, newCol.TypeInDB) {
+			return algo
+		}
+		if typeAlgo := predictColumnTypeAlgorithm(oldCol, newCol, flavor); typeAlgo > algo {
+			algo = typeAlgo
+		}
+	} else {
+		algo = predictColumnTypeAlgorithm(oldCol, newCol, flavor)
+	}
+
+	// Reordering is instant in MariaDB 10.4+, but only if the rest of the change
+	// is also instant and the table is eligible
+	if positioned && algo == AlterAlgorithmInstant && !instantColumnChanges(table, flavor, true) {
+		algo = AlterAlgorithmInplace
+	}
+	return algo
+}
+
+var reVarcharLength = regexp.MustCompile(`^varchar\((\d+)\)$`)
+
+// predictColumnTypeAlgorithm handles algorithm prediction for column type
+// changes. Only a few specific type changes avoid a full table copy: extending
+// the value list of an enum or set, and increasing the length of a varchar
+// without changing the number of bytes used to store its length.
+func predictColumnTypeAlgorithm(oldCol, newCol Column, flavor Flavor) AlterAlgorithm {
+	oldType, newType := strings.ToLower(oldCol.TypeInDB), strings.ToLower(newCol.TypeInDB)
+	if oldType == newType {
+		return AlterAlgorithmInplace
+	}
+
+	bothPrefix := func(prefix string) bool {
+		return strings.HasPrefix(oldType, prefix) && strings.HasPrefix(newType, prefix)
+	}
+	if (bothPrefix("enum(") || bothPrefix("set(")) && strings.HasPrefix(newType, oldType[0:len(oldType)-1]) {
+		// Appending values is only metadata, as long as the storage size doesn't change
+		oldCount, newCount := len(parseEnumSetValues(oldType)), len(parseEnumSetValues(newType))
+		var sameStorage bool
+		if strings.HasPrefix(oldType, "enum") {
+			sameStorage = (oldCount < 256) == (newCount < 256)
+		} else {
+			sameStorage = (oldCount+7)/8 == (newCount+7)/8
+		}
+		if !sameStorage {
+			return AlterAlgorithmCopy
+		} else if flavor.Min(FlavorMySQL80) || flavor.Min(FlavorMariaDB103) {
+			return AlterAlgorithmInstant
+		}
+		return AlterAlgorithmInplace
+	}
+
+	oldMatches := reVarcharLength.FindStringSubmatch(oldType)
+	newMatches := reVarcharLength.FindStringSubmatch(newType)
+	if oldMatches != nil && newMatches != nil && (flavor.Min(FlavorMySQL57) || flavor.Min(FlavorMariaDB102)) {
+		oldLen, _ := strconv.Atoi(oldMatches[1])
+		newLen, _ := strconv.Atoi(newMatches[1])
+		maxBytes := charSetMaxBytes(oldCol.CharSet)
+		if newLen >= oldLen && (oldLen*maxBytes < 256) == (newLen*maxBytes < 256) {
+			if flavor.Min(FlavorMariaDB104) {
+				return AlterAlgorithmInstant
+			}
+			return AlterAlgorithmInplace
+		}
+	}
+	return AlterAlgorithmCopy
+}
+
+// parseEnumSetValues returns the individual quoted values from an enum or set
+// column type.
+func parseEnumSetValues(colType string) (values []string) {
+	start := strings.IndexByte(colType, '(')
+	if start < 0 {
+		return nil
+	}
+	var inQuote bool
+	var cur strings.Builder
+	body := colType[start+1:]
+	for n := 0; n < len(body); n++ {
+		c := body[n]
+		if !inQuote {
+			if c == '\'' {
+				inQuote = true
+				cur.Reset()
+			} else if c == ')' {
+				break
+			}
+			continue
+		}
+		if c == '\'' {
+			if n+1 < len(body) && body[n+1] == '\'' { // doubled quote is an escaped quote
+				cur.WriteByte(c)
+				n++
+				continue
+			}
+			inQuote = false
+			values = append(values, cur.String())
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	return values
+}
+
+// charSetMaxBytes returns the maximum number of bytes per character for the
+// supplied character set. Unknown character sets are conservatively assumed to
+// use up to 4 bytes per character.
+func charSetMaxBytes(charSet string) int {
+	switch charSet {
+	case "", "latin1", "latin2", "latin5", "latin7", "ascii", "binary", "cp1250", "cp1251", "cp1256", "cp1257", "cp850", "cp852", "cp866", "dec8", "greek", "hebrew", "hp8", "keybcs2", "koi8r", "koi8u", "macce", "macroman", "swe7", "tis620", "armscii8", "geostd8":
+		return 1
+	case "big5", "cp932", "gbk", "sjis", "euckr", "ucs2":
+		return 2
+	case "utf8", "utf8mb3", "ujis", "eucjpms":
+		return 3
+	default: // utf8mb4, utf16, utf16le, utf32, gb18030, anything unknown
+		return 4
+	}
+}
@@ -0,0 +1,134 @@
+package tengo
+
+import (
+	"testing"
+)
+
+func TestTableDiffPredictAlgorithm(t *testing.T) {
+	from := aTable(1)
+	newCol := &Column{
+		Name:     "age",
+		TypeInDB: "int unsigned",
+		Nullable: true,
+		Default:  "NULL",
+	}
+	addLast := AddColumn{Table: &from, Column: newCol}
+	addFirst := AddColumn{Table: &from, Column: newCol, PositionFirst: true}
+	dropCol := DropColumn{Column: from.Columns[1]}
+	addIndex := AddIndex{Index: &Index{Name: "idx_age", Parts: []IndexPart{{ColumnName: "age"}}, Type: "BTREE"}}
+	dropPK := DropIndex{Index: from.PrimaryKey}
+	addPK := AddIndex{Index: &Index{Name: "PRIMARY", Parts: []IndexPart{{ColumnName: "actor_id"}}, PrimaryKey: true, Unique: true, Type: "BTREE"}}
+	engine := ChangeStorageEngine{NewStorageEngine: "MyISAM"}
+
+	cases := []struct {
+		flavor   Flavor
+		clauses  []TableAlterClause
+		expected AlterAlgorithm
+	}{
+		{FlavorUnknown, []TableAlterClause{addLast}, AlterAlgorithmUnknown},
+		{FlavorMySQL55, []TableAlterClause{addLast}, AlterAlgorithmCopy},
+		{FlavorMySQL55, []TableAlterClause{addIndex}, AlterAlgorithmInplace},
+		{FlavorMySQL57, []TableAlterClause{addLast}, AlterAlgorithmInplace},
+		{FlavorMySQL80.Dot(12), []TableAlterClause{addLast}, AlterAlgorithmInstant},
+		{FlavorMySQL80.Dot(12), []TableAlterClause{addFirst}, AlterAlgorithmInplace},
+		{FlavorMySQL80.Dot(29), []TableAlterClause{addFirst}, AlterAlgorithmInstant},
+		{FlavorMySQL80.Dot(28), []TableAlterClause{dropCol}, AlterAlgorithmInplace},
+		{FlavorMySQL80.Dot(29), []TableAlterClause{dropCol}, AlterAlgorithmInstant},
+		{FlavorMySQL80.Dot(29), []TableAlterClause{addLast, addIndex}, AlterAlgorithmInplace},
+		{FlavorMariaDB103.Dot(2), []TableAlterClause{addLast}, AlterAlgorithmInstant},
+		{FlavorMariaDB103.Dot(2), []TableAlterClause{addFirst}, AlterAlgorithmInplace},
+		{FlavorMariaDB104, []TableAlterClause{addFirst, dropCol}, AlterAlgorithmInstant},
+		{FlavorMySQL80, []TableAlterClause{dropPK}, AlterAlgorithmCopy},
+		{FlavorMySQL80, []TableAlterClause{dropPK, addPK}, AlterAlgorithmInplace},
+		{FlavorMySQL80, []TableAlterClause{addLast, engine}, AlterAlgorithmCopy},
+	}
+	for n, c := range cases {
+		td := &TableDiff{Type: DiffTypeAlter, From: &from, To: &from, alterClauses: c.clauses, supported: true}
+		if actual := td.PredictAlgorithm(StatementModifiers{Flavor: c.flavor}); actual != c.expected {
+			t.Errorf("cases[%d]: expected %q, instead found %q", n, c.expected, actual)
+		}
+	}
+
+	// Explicit ALGORITHM=COPY always yields a copy; non-ALTER diffs are unknown
+	td := &TableDiff{Type: DiffTypeAlter, From: &from, To: &from, alterClauses: []TableAlterClause{addIndex}, supported: true}
+	if actual := td.PredictAlgorithm(StatementModifiers{Flavor: FlavorMySQL80, AlgorithmClause: "copy"}); actual != AlterAlgorithmCopy {
+		t.Errorf("Expected explicit algorithm clause to be respected, instead found %q", actual)
+	}
+	if actual := NewCreateTable(&from).PredictAlgorithm(StatementModifiers{Flavor: FlavorMySQL80}); actual != AlterAlgorithmUnknown {
+		t.Errorf("Expected unknown algorithm for CREATE TABLE, instead found %q", actual)
+	}
+
+	// Instant ADD COLUMN isn't possible for tables with a FULLTEXT index
+	ftTable := aTable(1)
+	ftTable.SecondaryIndexes = append(ftTable.SecondaryIndexes, &Index{Name: "ft", Parts: []IndexPart{{ColumnName: "first_name"}}, Type: "FULLTEXT"})
+	td = &TableDiff{Type: DiffTypeAlter, From: &ftTable, To: &ftTable, alterClauses: []TableAlterClause{addLast}, supported: true}
+	if actual := td.PredictAlgorithm(StatementModifiers{Flavor: FlavorMySQL80.Dot(30)}); actual != AlterAlgorithmInplace {
+		t.Errorf("Expected %q for table with FULLTEXT index, instead found %q", AlterAlgorithmInplace, actual)
+	}
+}
+
+func TestPredictModifyColumnAlgorithm(t *testing.T) {
+	table := aTable(1)
+	assertAlgorithm := func(oldCol, newCol *Column, flavor Flavor, expected AlterAlgorithm) {
+		t.Helper()
+		mc := ModifyColumn{Table: &table, OldColumn: oldCol, NewColumn: newCol}
+		if actual := predictModifyColumnAlgorithm(mc, &table, flavor); actual != expected {
+			t.Errorf("For %s -> %s on %s, expected %q, instead found %q", oldCol.Definition(flavor, nil), newCol.Definition(flavor, nil), flavor, expected, actual)
+		}
+	}
+	col := func(typ string, modifier func(*Column)) *Column {
+		c := &Column{Name: "c", TypeInDB: typ, Nullable: true, Default: "NULL"}
+		if typ[0] == 'v' || typ[0] == 'c' {
+			c.CharSet, c.Collation, c.CollationIsDefault = "utf8mb4", "utf8mb4_general_ci", true
+		}
+		if modifier != nil {
+			modifier(c)
+		}
+		return c
+	}
+	newDefault := func(c *Column) { c.Default = "'x'" }
+	notNull := func(c *Column) { c.Nullable, c.Default = false, "" }
+	latin1 := func(c *Column) { c.CharSet, c.Collation = "latin1", "latin1_swedish_ci" }
+
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(20)", newDefault), FlavorMySQL80, AlterAlgorithmInstant)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(20)", newDefault), FlavorMySQL57, AlterAlgorithmInplace)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(20)", notNull), FlavorMySQL80, AlterAlgorithmInplace)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(60)", nil), FlavorMySQL80, AlterAlgorithmInplace)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(70)", nil), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("varchar(20)", latin1), col("varchar(70)", latin1), FlavorMySQL80, AlterAlgorithmInplace)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(60)", nil), FlavorMariaDB105, AlterAlgorithmInstant)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(10)", nil), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("varchar(20)", nil), col("varchar(20)", latin1), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("enum('a','b')", nil), col("enum('a','b','c')", nil), FlavorMySQL80, AlterAlgorithmInstant)
+	assertAlgorithm(col("enum('a','b')", nil), col("enum('a','b','c')", nil), FlavorMySQL57, AlterAlgorithmInplace)
+	assertAlgorithm(col("enum('a','b')", nil), col("enum('b','a')", nil), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("set('a','b','c','d','e','f','g','h')", nil), col("set('a','b','c','d','e','f','g','h','i')", nil), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("int", nil), col("bigint", nil), FlavorMySQL80, AlterAlgorithmCopy)
+	assertAlgorithm(col("int", nil), col("int", func(c *Column) { c.AutoIncrement = true }), FlavorMySQL80, AlterAlgorithmCopy)
+
+	// Reordering is only instant in MariaDB 10.4+
+	mc := ModifyColumn{Table: &table, OldColumn: col("int", nil), NewColumn: col("int", nil), PositionFirst: true}
+	if actual := predictModifyColumnAlgorithm(mc, &table, FlavorMySQL80); actual != AlterAlgorithmInplace {
+		t.Errorf("Expected reorder in MySQL 8 to be %q, instead found %q", AlterAlgorithmInplace, actual)
+	}
+	if actual := predictModifyColumnAlgorithm(mc, &table, FlavorMariaDB104); actual != AlterAlgorithmInstant {
+		t.Errorf("Expected reorder in MariaDB 10.4 to be %q, instead found %q", AlterAlgorithmInstant, actual)
+	}
+}
+
+func TestParseEnumSetValues(t *testing.T) {
+	cases := map[string]int{
+		"enum('a','b','c')":       3,
+		"set('a,b','c')":          2,
+		"enum('it''s','b')":       2,
+		"enum('a)','b')":          2,
+		"int":                     0,
+		"set('')":                 1,
+		"enum('x') character set": 1,
+	}
+	for input, expected := range cases {
+		if actual := len(parseEnumSetValues(input)); actual != expected {
+			t.Errorf("Expected parseEnumSetValues(%q) to return %d values, instead found %d", input, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tengo
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Columns may be deprecated by annotating their line in a CREATE TABLE file
+// with an inline "-- skeema:deprecated" comment. When such a column is pushed,
+// the time of deprecation is stored in the database by appending a marker to
+// the column's comment. The marker is not intended to appear in CREATE TABLE
+// files though: when dumping a CREATE TABLE, AnnotateDeprecatedColumns strips
+// markers and converts them back into inline annotations.
+
+// DeprecationMarkerPrefix is the prefix of a deprecation marker in a column
+// comment. It is followed by a UTC timestamp in YYYYMMDDHHMMSS format.
+const DeprecationMarkerPrefix = "skeema:deprecated@"
+
+var (
+	reDeprecatedAnnotation = regexp.MustCompile("(?m)^\\s*(?:`((?:[^`]|``)+)`|(\\w+))\\s[^\\n]*?(--[ \\t]*skeema:deprecated\\b[^\\n]*)")
+	reDeprecationMarker    = regexp.MustCompile(DeprecationMarkerPrefix + `\d{14}`)
+	reCreateColumnName     = regexp.MustCompile("^  `((?:[^`]|``)+)` ")
+)
+
+// DeprecatedColumnAnnotations parses a CREATE TABLE statement, and returns a
+// map of column name to inline deprecation comment, for each column that is
+// annotated as deprecated.
+func DeprecatedColumnAnnotations(createStmt string) map[string]string {
+	annotations := make(map[string]string)
+	for _, match := range reDeprecatedAnnotation.FindAllStringSubmatch(createStmt, -1) {
+		if match[1] != "" {
+			annotations[strings.ReplaceAll(match[1], "``", "`")] = match[3]
+		} else {
+			annotations[match[2]] = match[3]
+		}
+	}
+	return annotations
+}
+
+// ParseDeprecationMarker splits a column comment into the portion without any
+// deprecation marker, and the marker itself (or an empty string if there is
+// no marker).
+func ParseDeprecationMarker(comment string) (base, marker string) {
+	loc := reDeprecationMarker.FindStringIndex(comment)
+	if loc == nil {
+		return comment, ""
+	}
+	base = strings.TrimSpace(comment[:loc[0]] + comment[loc[1]:])
+	return base, comment[loc[0]:loc[1]]
+}
+
+// AnnotateDeprecatedColumns parses a CREATE TABLE statement, formatted in the
+// same manner as SHOW CREATE TABLE, and returns a modified version in which
+// deprecated columns have an inline deprecation annotation at the end of their
+// line. Deprecated columns are those with a deprecation marker in their
+// comment, which is removed from the returned statement; as well as those
+// which were annotated in prevCreateStmt, which is typically the same table's
+// previous definition from the filesystem.
+func AnnotateDeprecatedColumns(createStmt, prevCreateStmt string) string {
+	annotations := DeprecatedColumnAnnotations(prevCreateStmt)
+	if len(annotations) == 0 && !reDeprecationMarker.MatchString(createStmt) {
+		return createStmt
+	}
+	lines := strings.Split(createStmt, "\n")
+	for n, line := range lines {
+		match := reCreateColumnName.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		annotation, annotated := annotations[strings.ReplaceAll(match[1], "``", "`")]
+		if loc := reDeprecationMarker.FindStringIndex(line); loc != nil {
+			if before, after := line[:loc[0]], line[loc[1]:]; strings.HasSuffix(before, " COMMENT '") && strings.HasPrefix(after, "'") {
+				line = strings.TrimSuffix(before, " COMMENT '") + after[1:]
+			} else {
+				line = strings.TrimSuffix(before, " ") + after
+			}
+			if !annotated {
+				annotation, annotated = "-- skeema:deprecated", true
+			}
+		}
+		if annotated {
+			lines[n] = line + " " + annotation
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package tengo
+
+import (
+	"testing"
+)
+
+func TestDeprecatedColumnAnnotations(t *testing.T) {
+	createText := "CREATE TABLE foo (\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  `old_name` varchar(30) DEFAULT NULL, -- skeema:deprecated\n" +
+		"  `weird``col` int, --skeema:deprecated since last week\n" +
+		"  other int -- some other comment\n" +
+		"  , legacy int -- skeema:deprecated\n" +
+		"  notdeprecated int -- skeema:deprecatedness\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n"
+	expected := map[string]string{
+		"old_name":  "-- skeema:deprecated",
+		"weird`col": "--skeema:deprecated since last week",
+	}
+	actual := DeprecatedColumnAnnotations(createText)
+	if len(actual) != len(expected) {
+		t.Errorf("Expected %d annotations, instead found %d: %v", len(expected), len(actual), actual)
+	}
+	for name, annotation := range expected {
+		if actual[name] != annotation {
+			t.Errorf("Expected column %s to have annotation %q, instead found %q", name, annotation, actual[name])
+		}
+	}
+}
+
+func TestParseDeprecationMarker(t *testing.T) {
+	cases := []struct {
+		comment, base, marker string
+	}{
+		{"", "", ""},
+		{"the name", "the name", ""},
+		{"skeema:deprecated@20261016130405", "", "skeema:deprecated@20261016130405"},
+		{"the name skeema:deprecated@20261016130405", "the name", "skeema:deprecated@20261016130405"},
+		{"skeema:deprecated@2026", "skeema:deprecated@2026", ""},
+	}
+	for _, c := range cases {
+		if base, marker := ParseDeprecationMarker(c.comment); base != c.base || marker != c.marker {
+			t.Errorf("Unexpected result from ParseDeprecationMarker(%q): expected %q, %q; found %q, %q", c.comment, c.base, c.marker, base, marker)
+		}
+	}
+}
+
+func TestAnnotateDeprecatedColumns(t *testing.T) {
+	createStmt := "CREATE TABLE `users` (\n" +
+		"  `id` int unsigned NOT NULL,\n" +
+		"  `name` varchar(30) DEFAULT NULL /*!80023 INVISIBLE */ COMMENT 'skeema:deprecated@20261016130405',\n" +
+		"  `email` varchar(80) DEFAULT NULL COMMENT 'the email skeema:deprecated@20261016130405',\n" +
+		"  `legacy``col` int DEFAULT NULL,\n" +
+		"  `age` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	prevCreateStmt := "CREATE TABLE users (\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  `legacy``col` int, -- skeema:deprecated remove in Q3\n" +
+		"  age int,\n" +
+		"  PRIMARY KEY (id)\n" +
+		")"
+	expected := "CREATE TABLE `users` (\n" +
+		"  `id` int unsigned NOT NULL,\n" +
+		"  `name` varchar(30) DEFAULT NULL /*!80023 INVISIBLE */, -- skeema:deprecated\n" +
+		"  `email` varchar(80) DEFAULT NULL COMMENT 'the email', -- skeema:deprecated\n" +
+		"  `legacy``col` int DEFAULT NULL, -- skeema:deprecated remove in Q3\n" +
+		"  `age` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	if actual := AnnotateDeprecatedColumns(createStmt, prevCreateStmt); actual != expected {
+		t.Errorf("Unexpected result from AnnotateDeprecatedColumns:\n%s\nexpected:\n%s", actual, expected)
+	}
+
+	// Statements without markers or previous annotations are returned as-is
+	if actual := AnnotateDeprecatedColumns(prevCreateStmt, ""); actual != prevCreateStmt {
+		t.Errorf("Unexpected result from AnnotateDeprecatedColumns:\n%s\nexpected:\n%s", actual, prevCreateStmt)
+	}
+}
@@ -242,6 +242,26 @@ func (sd *SchemaDiff) FilteredTableDiffs(onlyTypes ...DiffType) []*TableDiff {
 	return result
 }
 
+// RetainMatches removes table diffs and routine diffs from sd, unless they
+// match at least one of the supplied patterns. Database-level differences are
+// not affected, since these are computed directly from sd's schemas.
+func (sd *SchemaDiff) RetainMatches(patterns []ObjectPattern) {
+	sd.TableDiffs = retainMatchingObjects(sd.TableDiffs, patterns)
+	sd.RoutineDiffs = retainMatchingObjects(sd.RoutineDiffs, patterns)
+}
+
+func retainMatchingObjects[T ObjectKeyer](s []T, patterns []ObjectPattern) (result []T) {
+	for _, obj := range s {
+		for _, pattern := range patterns {
+			if pattern.Match(obj) {
+				result = append(result, obj)
+				break
+			}
+		}
+	}
+	return
+}
+
 ///// DatabaseDiff /////////////////////////////////////////////////////////////
 
 // DatabaseDiff represents differences of schema characteristics (default
@@ -505,6 +525,38 @@ func (td *TableDiff) SplitConflicts() (result []*TableDiff) {
 	return result
 }
 
+// UnsafeClauses returns the clauses of td which are potentially destructive,
+// i.e. those which would cause Statement to return an error unless
+// StatementModifiers.AllowUnsafe is enabled. A nil slice is returned for diffs
+// other than ALTER TABLE.
+func (td *TableDiff) UnsafeClauses() (clauses []TableAlterClause) {
+	if td == nil || td.Type != DiffTypeAlter || !td.supported {
+		return nil
+	}
+	for _, clause := range td.alterClauses {
+		if unsafer, ok := clause.(Unsafer); ok && unsafer.Unsafe() {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// LossyColumns returns the columns of td.From whose existing data may be lost
+// by td: columns which are dropped, or modified in an unsafe manner such as
+// narrowing their type. Virtual columns are never included, since their data
+// is not stored. A nil slice is returned for diffs other than ALTER TABLE.
+func (td *TableDiff) LossyColumns() (cols []*Column) {
+	for _, clause := range td.UnsafeClauses() {
+		switch clause := clause.(type) {
+		case DropColumn:
+			cols = append(cols, clause.Column)
+		case ModifyColumn:
+			cols = append(cols, clause.OldColumn)
+		}
+	}
+	return cols
+}
+
 // Statement returns the full DDL statement corresponding to the TableDiff. A
 // blank string may be returned if the mods indicate the statement should be
 // skipped. If the mods indicate the statement should be disallowed, it will
@@ -2,6 +2,7 @@ package tengo
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -672,6 +673,37 @@ func TestSchemaDiffFilteredTableDiffs(t *testing.T) {
 	assertFiltered(sd, 2, DiffTypeDrop, DiffTypeAlter)
 }
 
+func TestSchemaDiffRetainMatches(t *testing.T) {
+	s1t1 := anotherTable()
+	s1t2 := aTable(1)
+	s1 := aSchema("s1", &s1t1, &s1t2)
+	s2t2 := aTable(5)
+	s2 := aSchema("s2", &s2t2)
+	s2r1 := aProc("latin1_swedish_ci", "")
+	s2.Routines = append(s2.Routines, &s2r1)
+
+	sd := NewSchemaDiff(&s1, &s2)
+	if len(sd.TableDiffs) != 2 || len(sd.RoutineDiffs) != 1 {
+		t.Fatalf("Unexpected diff counts before RetainMatches: %d tables, %d routines", len(sd.TableDiffs), len(sd.RoutineDiffs))
+	}
+	sd.RetainMatches([]ObjectPattern{{Type: ObjectTypeTable, Pattern: regexp.MustCompile("^" + s2t2.Name + "$")}})
+	if len(sd.TableDiffs) != 1 || sd.TableDiffs[0].Type != DiffTypeAlter || len(sd.RoutineDiffs) != 0 {
+		t.Errorf("Unexpected result from RetainMatches: %d tables, %d routines", len(sd.TableDiffs), len(sd.RoutineDiffs))
+	}
+
+	sd = NewSchemaDiff(&s1, &s2)
+	sd.RetainMatches([]ObjectPattern{{Type: ObjectTypeProc, Pattern: regexp.MustCompile(".")}})
+	if len(sd.TableDiffs) != 0 || len(sd.RoutineDiffs) != 1 {
+		t.Errorf("Unexpected result from RetainMatches: %d tables, %d routines", len(sd.TableDiffs), len(sd.RoutineDiffs))
+	}
+
+	sd = NewSchemaDiff(&s1, &s2)
+	sd.RetainMatches(nil)
+	if len(sd.TableDiffs) != 0 || len(sd.RoutineDiffs) != 0 {
+		t.Errorf("Unexpected result from RetainMatches with no patterns: %d tables, %d routines", len(sd.TableDiffs), len(sd.RoutineDiffs))
+	}
+}
+
 func TestTableDiffUnsupportedAlter(t *testing.T) {
 	t1 := supportedTable()
 	t2 := unsupportedTable()
@@ -787,6 +819,34 @@ func TestTableDiffClauses(t *testing.T) {
 	}
 }
 
+func TestTableDiffLossyColumns(t *testing.T) {
+	table := &Table{Name: "tbl"}
+	narrow := ModifyColumn{
+		Table:     table,
+		OldColumn: &Column{Name: "a", TypeInDB: "varchar(20)"},
+		NewColumn: &Column{Name: "a", TypeInDB: "varchar(10)"},
+	}
+	widen := ModifyColumn{
+		Table:     table,
+		OldColumn: &Column{Name: "b", TypeInDB: "int"},
+		NewColumn: &Column{Name: "b", TypeInDB: "bigint"},
+	}
+	drop := DropColumn{Column: &Column{Name: "c", TypeInDB: "int"}}
+	dropVirtual := DropColumn{Column: &Column{Name: "d", TypeInDB: "int", Virtual: true}}
+
+	td := &TableDiff{Type: DiffTypeAlter, From: table, To: table, supported: true, alterClauses: []TableAlterClause{narrow, widen, drop, dropVirtual}}
+	if cols := td.LossyColumns(); len(cols) != 2 || cols[0].Name != "a" || cols[1].Name != "c" {
+		t.Errorf("Unexpected result from LossyColumns: %+v", cols)
+	}
+	td.alterClauses = []TableAlterClause{widen, dropVirtual}
+	if cols := td.LossyColumns(); cols != nil {
+		t.Errorf("Expected no lossy columns, instead found %+v", cols)
+	}
+	if cols := NewDropTable(table).LossyColumns(); cols != nil {
+		t.Errorf("Expected no lossy columns for DROP TABLE, instead found %+v", cols)
+	}
+}
+
 func TestAlterTableStatementAllowUnsafeMods(t *testing.T) {
 	t1 := aTable(1)
 	t2 := aTable(1)
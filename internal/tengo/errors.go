@@ -48,3 +48,13 @@ func IsAccessError(err error) bool {
 	}
 	return IsDatabaseError(err, authErrors...)
 }
+
+// IsTransientError returns true if err indicates a problem which may not recur
+// if the same statement is retried: a lock wait timeout (which also covers
+// metadata lock timeouts from lock_wait_timeout), or a deadlock. In either
+// case, the server has rolled back the failed statement.
+// Losing the connection during a query is intentionally not considered
+// transient, since the statement may still be running on the server.
+func IsTransientError(err error) bool {
+	return IsDatabaseError(err, mysqlerr.ER_LOCK_WAIT_TIMEOUT, mysqlerr.ER_LOCK_DEADLOCK)
+}
@@ -1,9 +1,13 @@
 package tengo
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/VividCortex/mysqlerr"
+	"github.com/go-sql-driver/mysql"
 )
 
 func (s TengoIntegrationSuite) TestIsDatabaseError(t *testing.T) {
@@ -69,3 +73,20 @@ func (s TengoIntegrationSuite) TestIsAccessError(t *testing.T) {
 		t.Errorf("Error of type %T %+v unexpectedly considered access error", err, err)
 	}
 }
+
+func TestIsTransientError(t *testing.T) {
+	cases := map[error]bool{
+		errors.New("non-db error"):                               false,
+		&mysql.MySQLError{Number: mysqlerr.ER_LOCK_WAIT_TIMEOUT}: true,
+		&mysql.MySQLError{Number: mysqlerr.ER_LOCK_DEADLOCK}:     true,
+		&mysql.MySQLError{Number: 2013}:                          false, // CR_SERVER_LOST relayed by a proxy
+		&mysql.MySQLError{Number: mysqlerr.ER_PARSE_ERROR}:       false,
+		mysql.ErrInvalidConn:                                     false,
+		fmt.Errorf("wrapped: %w", driver.ErrBadConn):             false,
+	}
+	for err, expected := range cases {
+		if actual := IsTransientError(err); actual != expected {
+			t.Errorf("Expected IsTransientError(%v) to return %t, instead found %t", err, expected, actual)
+		}
+	}
+}
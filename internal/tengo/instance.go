@@ -650,6 +650,268 @@ func tableHasRows(db *sqlx.DB, schema, table string) (bool, error) {
 	return len(result) != 0, nil
 }
 
+// erStatementTimeout is MariaDB's error code for a statement exceeding
+// max_statement_time. MySQL uses mysqlerr.ER_QUERY_TIMEOUT instead.
+const erStatementTimeout = 1969
+
+// TableRowCount returns the number of rows in the table. An exact count is
+// attempted first, but it is cancelled if it does not complete within timeout,
+// in which case information_schema's row count estimate is returned instead.
+// The returned exact bool indicates which method was used. Note that the
+// estimate may be quite inaccurate for InnoDB tables.
+func (instance *Instance) TableRowCount(schema, table string, timeout time.Duration) (count int64, exact bool, err error) {
+	// Cap execution time server-side where supported, in addition to the client-
+	// side context timeout, so that a slow count doesn't linger on the server
+	var params string
+	if flavor := instance.Flavor(); flavor.Min(FlavorMySQL57) {
+		params = fmt.Sprintf("max_execution_time=%d", timeout.Milliseconds())
+	} else if flavor.Min(FlavorMariaDB101) {
+		params = fmt.Sprintf("max_statement_time=%g", timeout.Seconds())
+	}
+	db, err := instance.CachedConnectionPool("", params)
+	if err != nil {
+		return 0, false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", EscapeIdentifier(schema), EscapeIdentifier(table))
+	err = db.QueryRowContext(ctx, query).Scan(&count)
+	if err == nil {
+		return count, true, nil
+	} else if ctx.Err() == nil && !IsDatabaseError(err, mysqlerr.ER_QUERY_TIMEOUT, erStatementTimeout, mysqlerr.ER_QUERY_INTERRUPTED) {
+		return 0, false, err
+	}
+
+	// Exact count timed out: fall back to estimate
+	db, err = instance.CachedConnectionPool("", instance.introspectionParams())
+	if err != nil {
+		return 0, false, err
+	}
+	err = db.Get(&count, `
+		SELECT  COALESCE(table_rows, 0)
+		FROM    information_schema.tables
+		WHERE   table_schema = ? and table_name = ?`,
+		schema, table)
+	return count, false, err
+}
+
+// BlockingSession represents a database session which may block DDL on a
+// table, either by holding a metadata lock on the table, or by having an open
+// transaction or long-running query which might hold such a lock.
+type BlockingSession struct {
+	ID       uint64 `db:"id"`
+	User     string `db:"user"`
+	Host     string `db:"host"`
+	DB       string `db:"db"` // session's default database, if any
+	Command  string `db:"command"`
+	Time     int64  `db:"time"`    // seconds since the session's current state began
+	TrxAge   int64  `db:"trx_age"` // seconds since the session's transaction began, or 0 if none
+	Info     string `db:"info"`    // currently-running query, if any
+	HoldsMDL bool   `db:"-"`       // true if confirmed to hold a metadata lock on the table
+}
+
+// String returns a human-readable description of the session.
+func (bs BlockingSession) String() string {
+	var desc string
+	if bs.TrxAge > 0 {
+		desc = fmt.Sprintf("transaction open for %ds", bs.TrxAge)
+	} else {
+		desc = fmt.Sprintf("query running for %ds", bs.Time)
+	}
+	if bs.HoldsMDL {
+		desc += ", holding metadata lock"
+	}
+	if bs.Info != "" {
+		info := bs.Info
+		if len(info) > 100 {
+			info = info[0:100] + "..."
+		}
+		desc += ": " + strings.Join(strings.Fields(info), " ")
+	}
+	return fmt.Sprintf("session %d (%s@%s, %s)", bs.ID, bs.User, bs.Host, desc)
+}
+
+// BlockingSessions returns sessions which have had an open transaction, or a
+// running query, for at least minSeconds, and which may block DDL on the
+// supplied table. If performance_schema.metadata_locks is available and its
+// instrument is enabled, only sessions holding a metadata lock on the table are
+// returned, with HoldsMDL set to true. Otherwise, heuristics are used instead:
+// sessions with a sufficiently old transaction or query are returned if their
+// default database is the supplied schema, or if their current query appears
+// to reference the table name. Such heuristic results have HoldsMDL set to
+// false, and callers should not assume they actually block the DDL.
+func (instance *Instance) BlockingSessions(schema, table string, minSeconds int) ([]BlockingSession, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	var candidates []BlockingSession
+	query := `
+		SELECT    p.id AS id, p.user AS user, p.host AS host, COALESCE(p.db, '') AS db,
+		          p.command AS command,
+		          p.time AS time, COALESCE(p.info, '') AS info,
+		          COALESCE(TIMESTAMPDIFF(SECOND, t.trx_started, NOW()), 0) AS trx_age
+		FROM      information_schema.processlist p
+		LEFT JOIN information_schema.innodb_trx t ON t.trx_mysql_thread_id = p.id
+		WHERE     p.id <> CONNECTION_ID()
+		AND       ((t.trx_started IS NOT NULL AND t.trx_started <= NOW() - INTERVAL ? SECOND)
+		           OR (p.command IN ('Query', 'Execute') AND p.time >= ?))`
+	if err := db.Select(&candidates, query, minSeconds, minSeconds); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// If metadata_locks is available, use it to determine exactly which sessions
+	// hold locks on the table. This is not possible if performance_schema is
+	// disabled, the flavor lacks this table, or the MDL instrument is disabled
+	// (which is the default in MySQL 5.7), in which case we fall back to
+	// heuristics.
+	if !instance.mdlInstrumentEnabled(db) {
+		return blockingSessionsHeuristic(candidates, schema, table), nil
+	}
+	var holders []uint64
+	query = `
+		SELECT DISTINCT th.processlist_id
+		FROM   performance_schema.metadata_locks ml
+		JOIN   performance_schema.threads th ON th.thread_id = ml.owner_thread_id
+		WHERE  ml.object_type = 'TABLE' AND ml.object_schema = ? AND ml.object_name = ?
+		AND    ml.lock_status = 'GRANTED' AND th.processlist_id IS NOT NULL`
+	if err := db.Select(&holders, query, schema, table); err == nil {
+		holdsLock := make(map[uint64]bool, len(holders))
+		for _, id := range holders {
+			holdsLock[id] = true
+		}
+		var result []BlockingSession
+		for _, bs := range candidates {
+			if holdsLock[bs.ID] {
+				bs.HoldsMDL = true
+				result = append(result, bs)
+			}
+		}
+		return result, nil
+	}
+	return blockingSessionsHeuristic(candidates, schema, table), nil
+}
+
+// mdlInstrumentEnabled returns true if performance_schema is configured to
+// track metadata locks. If the instrument cannot be queried, false is
+// returned.
+func (instance *Instance) mdlInstrumentEnabled(db *sqlx.DB) bool {
+	var enabled string
+	query := `
+		SELECT enabled
+		FROM   performance_schema.setup_instruments
+		WHERE  name = 'wait/lock/metadata/sql/mdl'`
+	if err := db.Get(&enabled, query); err != nil {
+		return false
+	}
+	return strings.EqualFold(enabled, "YES")
+}
+
+// blockingSessionsHeuristic filters candidates to sessions which are likely to
+// hold a metadata lock on the supplied table: sessions using schema as their
+// default database, or sessions running a query which references the table
+// name as a whole identifier.
+func blockingSessionsHeuristic(candidates []BlockingSession, schema, table string) (result []BlockingSession) {
+	re := regexp.MustCompile(`(?i)(?:^|[^\w$])` + regexp.QuoteMeta(table) + `(?:[^\w$]|$)`)
+	for _, bs := range candidates {
+		if bs.DB == schema || re.MatchString(bs.Info) {
+			result = append(result, bs)
+		}
+	}
+	return result
+}
+
+// KillSession terminates the session with the supplied processlist ID.
+func (instance *Instance) KillSession(id uint64) error {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("KILL %d", id))
+	return err
+}
+
+// ReplicaHosts returns the addresses of replicas which have registered with
+// this instance, in host:port format. Only replicas which have set report_host
+// will have a known address; others are omitted from the result.
+func (instance *Instance) ReplicaHosts() ([]string, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	query := "SHOW SLAVE HOSTS"
+	if instance.Flavor().Min(FlavorMySQL80.Dot(22)) {
+		query = "SHOW REPLICAS"
+	}
+	rows, err := queryRowMaps(db, query)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, row := range rows {
+		if row["host"] != "" {
+			result = append(result, fmt.Sprintf("%s:%s", row["host"], row["port"]))
+		}
+	}
+	return result, nil
+}
+
+// ReplicationLag returns the number of seconds that this instance is behind
+// its replication source. If the instance is a replica but replication is not
+// currently running, running will be false. If the instance is not a replica
+// at all, a non-nil error is returned.
+func (instance *Instance) ReplicationLag() (lag int64, running bool, err error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return 0, false, err
+	}
+	query, column := "SHOW SLAVE STATUS", "seconds_behind_master"
+	if instance.Flavor().Min(FlavorMySQL80.Dot(22)) {
+		query, column = "SHOW REPLICA STATUS", "seconds_behind_source"
+	}
+	rows, err := queryRowMaps(db, query)
+	if err != nil {
+		return 0, false, err
+	} else if len(rows) == 0 {
+		return 0, false, fmt.Errorf("%s is not configured as a replica", instance)
+	}
+	value, ok := rows[0][column]
+	if !ok || value == "" {
+		return 0, false, nil
+	}
+	_, err = fmt.Sscanf(value, "%d", &lag)
+	return lag, err == nil, err
+}
+
+// queryRowMaps runs a query and returns each row as a map of lowercased column
+// name to string value. NULL values are returned as empty strings. This is
+// useful for SHOW commands with many columns, which vary between flavors.
+func queryRowMaps(db *sqlx.DB, query string) ([]map[string]string, error) {
+	rows, err := db.Queryx(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []map[string]string
+	for rows.Next() {
+		raw := make(map[string]interface{})
+		if err := rows.MapScan(raw); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if v != nil {
+				row[strings.ToLower(k)] = fmt.Sprintf("%s", v)
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
 func confirmTablesEmpty(db *sqlx.DB, schema string, tables []string) error {
 	g := new(errgroup.Group)
 	g.SetLimit(15)
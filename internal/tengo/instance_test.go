@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -595,6 +596,103 @@ func (s TengoIntegrationSuite) TestInstanceTableHasRows(t *testing.T) {
 	}
 }
 
+func (s TengoIntegrationSuite) TestInstanceTableRowCount(t *testing.T) {
+	s.SourceTestSQL(t, "rows.sql")
+	if count, exact, err := s.d.TableRowCount("testing", "has_rows", 5*time.Second); err != nil {
+		t.Errorf("Error from TableRowCount: %s", err)
+	} else if count != 4 || !exact {
+		t.Errorf("Expected TableRowCount to return exact count of 4, instead found count=%d exact=%t", count, exact)
+	}
+	if count, _, err := s.d.TableRowCount("testing", "no_rows", 5*time.Second); err != nil || count != 0 {
+		t.Errorf("Unexpected result from TableRowCount on no_rows: count=%d err=%v", count, err)
+	}
+
+	// Test nonexistent table
+	if _, _, err := s.d.TableRowCount("testing", "doesnt_exist", 5*time.Second); err == nil {
+		t.Error("Expected TableRowCount to return error for nonexistent table, but it did not")
+	}
+}
+
+func TestBlockingSessionsHeuristic(t *testing.T) {
+	candidates := []BlockingSession{
+		{ID: 1, DB: "testing", TrxAge: 10},
+		{ID: 2, DB: "other", TrxAge: 10},
+		{ID: 3, DB: "other", Info: "SELECT * FROM testing.users WHERE id = 1"},
+		{ID: 4, DB: "other", Info: "SELECT * FROM testing.user WHERE id = 1"},
+		{ID: 5, Info: "UPDATE `user` SET name = 'x'"},
+		{ID: 6, Info: "SELECT user_id FROM foo"},
+	}
+	var ids []uint64
+	for _, bs := range blockingSessionsHeuristic(candidates, "testing", "user") {
+		ids = append(ids, bs.ID)
+		if bs.HoldsMDL {
+			t.Errorf("Expected heuristic result to have HoldsMDL false: %+v", bs)
+		}
+	}
+	if expected := []uint64{1, 4, 5}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected heuristic to return sessions %v, instead found %v", expected, ids)
+	}
+}
+
+func (s TengoIntegrationSuite) TestInstanceBlockingSessions(t *testing.T) {
+	s.SourceTestSQL(t, "rows.sql")
+	if blockers, err := s.d.BlockingSessions("testing", "has_rows", 0); err != nil {
+		t.Fatalf("Unexpected error from BlockingSessions: %s", err)
+	} else if len(blockers) > 0 {
+		t.Fatalf("Expected no blocking sessions, instead found %v", blockers)
+	}
+
+	// Open a transaction in a separate connection pool, and confirm it is found
+	db, err := s.d.ConnectionPool("testing", "")
+	if err != nil {
+		t.Fatalf("Unable to connect: %s", err)
+	}
+	defer db.Close()
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatalf("Unable to begin transaction: %s", err)
+	}
+	var id uint64
+	if err := tx.Get(&id, "SELECT CONNECTION_ID()"); err != nil {
+		t.Fatalf("Unexpected error querying connection ID: %s", err)
+	}
+	if _, err := tx.Exec("SELECT * FROM has_rows FOR UPDATE"); err != nil {
+		t.Fatalf("Unexpected error querying has_rows: %s", err)
+	}
+	blockers, err := s.d.BlockingSessions("testing", "has_rows", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from BlockingSessions: %s", err)
+	} else if len(blockers) != 1 || blockers[0].ID != id {
+		t.Errorf("Expected to find session %d as a blocker, instead found %v", id, blockers)
+	}
+	if blockers, err := s.d.BlockingSessions("testing", "has_rows", 3600); err != nil || len(blockers) > 0 {
+		t.Errorf("Expected no blockers with a high minimum time, instead found %v, err=%v", blockers, err)
+	}
+
+	// Kill the session, and confirm it is no longer found
+	if err := s.d.KillSession(id); err != nil {
+		t.Fatalf("Unexpected error from KillSession: %s", err)
+	}
+	tx.Rollback()
+	time.Sleep(100 * time.Millisecond)
+	if blockers, err := s.d.BlockingSessions("testing", "has_rows", 0); err != nil || len(blockers) > 0 {
+		t.Errorf("Expected no blockers after kill, instead found %v, err=%v", blockers, err)
+	}
+}
+
+func (s TengoIntegrationSuite) TestInstanceReplication(t *testing.T) {
+	// Test images are standalone servers, so there are no replicas, and the
+	// instance itself is not a replica
+	if hosts, err := s.d.ReplicaHosts(); err != nil {
+		t.Errorf("Unexpected error from ReplicaHosts: %v", err)
+	} else if len(hosts) > 0 {
+		t.Errorf("Expected no replica hosts, instead found %v", hosts)
+	}
+	if _, running, err := s.d.ReplicationLag(); err == nil || running {
+		t.Errorf("Expected ReplicationLag to return an error on a non-replica, instead found running=%t err=%v", running, err)
+	}
+}
+
 func (s TengoIntegrationSuite) TestInstanceCreateSchema(t *testing.T) {
 	opts := SchemaCreationOptions{
 		DefaultCharSet:   "utf8mb4",
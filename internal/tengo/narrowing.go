@@ -0,0 +1,250 @@
+package tengo
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type narrowingKind int
+
+const (
+	narrowingLength narrowingKind = iota // string type with a smaller max length
+	narrowingRange                       // integer type with a smaller range of values
+	narrowingValues                      // enum or set type with some values removed
+)
+
+// NarrowingCheck describes how to confirm that the existing data in a column
+// fits within the narrower column definition of an unsafe ModifyColumn clause.
+// NarrowingChecks are obtained from TableDiff.NarrowingChecks, and may be run
+// using Instance.CheckNarrowing.
+type NarrowingCheck struct {
+	Clause    ModifyColumn
+	kind      narrowingKind
+	maxLength uint64   // narrowingLength: max length in new type
+	useBytes  bool     // narrowingLength: if true, maxLength is in bytes rather than characters
+	minValue  string   // narrowingRange: min value of new type
+	maxValue  string   // narrowingRange: max value of new type
+	removed   []string // narrowingValues: values present in old type but not new type
+}
+
+// NarrowingChecks returns a NarrowingCheck for each unsafe ModifyColumn clause
+// in td. The returned bool will be false if td has any unsafe clause that
+// cannot be checked in this manner, such as a dropped column, charset change,
+// or narrowing of a type other than a string, integer, enum, or set. It will
+// also be false if td has no unsafe clauses at all.
+func (td *TableDiff) NarrowingChecks() ([]NarrowingCheck, bool) {
+	if td == nil || td.Type != DiffTypeAlter || !td.supported {
+		return nil, false
+	}
+	var checks []NarrowingCheck
+	for _, clause := range td.alterClauses {
+		if unsafer, ok := clause.(Unsafer); !ok || !unsafer.Unsafe() {
+			continue
+		}
+		mc, ok := clause.(ModifyColumn)
+		if !ok {
+			return nil, false
+		}
+		check, ok := narrowingCheckForClause(mc)
+		if !ok {
+			return nil, false
+		}
+		checks = append(checks, check)
+	}
+	return checks, len(checks) > 0
+}
+
+func narrowingCheckForClause(mc ModifyColumn) (NarrowingCheck, bool) {
+	check := NarrowingCheck{Clause: mc}
+	if mc.OldColumn.CharSet != mc.NewColumn.CharSet || mc.OldColumn.Virtual || mc.NewColumn.Virtual {
+		return check, false
+	}
+	oldType := strings.ToLower(mc.OldColumn.TypeInDB)
+	newType := strings.ToLower(mc.NewColumn.TypeInDB)
+
+	// enum -> enum or set -> set with values removed. The remaining values must
+	// be in the same relative order as before, with no values added. Any other
+	// change, such as reordering, is not considered, since this may affect
+	// applications relying on the numeric value or sort order, which cannot be
+	// checked by examining the data.
+	for _, prefix := range []string{"enum(", "set("} {
+		if strings.HasPrefix(oldType, prefix) && strings.HasPrefix(newType, prefix) {
+			newValues := parseEnumSetValues(mc.NewColumn.TypeInDB)
+			var n int
+			for _, v := range parseEnumSetValues(mc.OldColumn.TypeInDB) {
+				if n < len(newValues) && strings.EqualFold(v, newValues[n]) {
+					n++
+				} else {
+					check.removed = append(check.removed, v)
+				}
+			}
+			check.kind = narrowingValues
+			return check, n == len(newValues) && len(check.removed) > 0
+		}
+	}
+
+	// integer -> integer with smaller range
+	if _, _, ok := intTypeRange(oldType); ok {
+		check.minValue, check.maxValue, ok = intTypeRange(newType)
+		check.kind = narrowingRange
+		return check, ok
+	}
+
+	// char, varchar, or text type -> char, varchar, or text type with smaller max
+	// length. For char/varchar the length is in characters, but for text types it
+	// is in bytes.
+	if oldLength, _ := stringTypeMaxLength(oldType); oldLength > 0 {
+		if check.maxLength, check.useBytes = stringTypeMaxLength(newType); check.maxLength > 0 {
+			check.kind = narrowingLength
+			return check, true
+		}
+	}
+	return check, false
+}
+
+// intTypeRange returns the min and max values of the supplied integer column
+// type, as strings. The returned bool is false if typ is not an integer type.
+func intTypeRange(typ string) (minValue, maxValue string, ok bool) {
+	ranges := []struct {
+		name           string
+		min, max, umax string
+	}{
+		{"tinyint", "-128", "127", "255"},
+		{"smallint", "-32768", "32767", "65535"},
+		{"mediumint", "-8388608", "8388607", "16777215"},
+		{"int", "-2147483648", "2147483647", "4294967295"},
+		{"bigint", "-9223372036854775808", "9223372036854775807", "18446744073709551615"},
+	}
+	base, _, _ := strings.Cut(typ, "(")
+	base, _, _ = strings.Cut(base, " ")
+	for _, r := range ranges {
+		if base == r.name {
+			if strings.Contains(typ, "unsigned") {
+				return "0", r.umax, true
+			}
+			return r.min, r.max, true
+		}
+	}
+	return "", "", false
+}
+
+var reCharLength = regexp.MustCompile(`^(?:varchar|char)\((\d+)\)`)
+
+// stringTypeMaxLength returns the max length of the supplied char, varchar, or
+// text column type. If isBytes is true, the length is in bytes, otherwise it is
+// in characters. A length of 0 is returned if typ is not one of these types.
+func stringTypeMaxLength(typ string) (maxLength uint64, isBytes bool) {
+	textMap := map[string]uint64{
+		"tinytext":   255,
+		"text":       65535,
+		"mediumtext": 16777215,
+		"longtext":   4294967295,
+	}
+	if textLen, ok := textMap[typ]; ok {
+		return textLen, true
+	}
+	if matches := reCharLength.FindStringSubmatch(typ); matches != nil {
+		maxLength, _ = strconv.ParseUint(matches[1], 10, 64)
+	}
+	return maxLength, false
+}
+
+// query returns a SQL query and args for performing the check on the supplied
+// table.
+func (check NarrowingCheck) query(schema, table string) (string, []interface{}) {
+	from := EscapeIdentifier(schema) + "." + EscapeIdentifier(table)
+	col := EscapeIdentifier(check.Clause.OldColumn.Name)
+	switch check.kind {
+	case narrowingLength:
+		fn := "CHAR_LENGTH"
+		if check.useBytes {
+			fn = "LENGTH"
+		}
+		return fmt.Sprintf("SELECT COALESCE(MAX(%s(%s)), 0) FROM %s", fn, col, from), nil
+	case narrowingRange:
+		return fmt.Sprintf("SELECT MIN(%s), MAX(%s), COALESCE(MIN(%s) >= %s AND MAX(%s) <= %s, 1) FROM %s",
+			col, col, col, check.minValue, col, check.maxValue, from), nil
+	default: // narrowingValues
+		args := make([]interface{}, len(check.removed))
+		conds := make([]string, len(check.removed))
+		for n, v := range check.removed {
+			args[n] = v
+			if strings.HasPrefix(strings.ToLower(check.Clause.OldColumn.TypeInDB), "set") {
+				conds[n] = fmt.Sprintf("FIND_IN_SET(?, %s) > 0", col)
+			} else {
+				conds[n] = fmt.Sprintf("%s = ?", col)
+			}
+		}
+		return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", from, strings.Join(conds, " OR ")), args
+	}
+}
+
+// CheckNarrowing runs the supplied check against the live table in schema,
+// returning true if all existing data in the column fits in the new column
+// definition. A human-readable description of the evidence is also returned.
+// The check requires scanning the entire table, so callers should confirm the
+// table is not too large first, for example using TableRowsExceed.
+func (instance *Instance) CheckNarrowing(schema string, check NarrowingCheck) (fits bool, evidence string, err error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return false, "", err
+	}
+	query, args := check.query(schema, check.Clause.Table.Name)
+	colName := EscapeIdentifier(check.Clause.OldColumn.Name)
+	newType := check.Clause.NewColumn.TypeInDB
+	switch check.kind {
+	case narrowingLength:
+		var maxLength uint64
+		if err := db.QueryRow(query).Scan(&maxLength); err != nil {
+			return false, "", err
+		}
+		unit := "characters"
+		if check.useBytes {
+			unit = "bytes"
+		}
+		fits = (maxLength <= check.maxLength)
+		evidence = fmt.Sprintf("longest value in %s is %d %s, vs max of %d for %s", colName, maxLength, unit, check.maxLength, newType)
+	case narrowingRange:
+		var minValue, maxValue sql.NullString
+		if err := db.QueryRow(query).Scan(&minValue, &maxValue, &fits); err != nil {
+			return false, "", err
+		}
+		if !minValue.Valid {
+			evidence = fmt.Sprintf("%s contains no non-NULL values", colName)
+		} else {
+			evidence = fmt.Sprintf("values in %s range from %s to %s, vs range of %s to %s for %s", colName, minValue.String, maxValue.String, check.minValue, check.maxValue, newType)
+		}
+	default: // narrowingValues
+		var count int64
+		if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+			return false, "", err
+		}
+		fits = (count == 0)
+		quoted := make([]string, len(check.removed))
+		for n, v := range check.removed {
+			quoted[n] = "'" + EscapeValueForCreateTable(v) + "'"
+		}
+		evidence = fmt.Sprintf("%d rows of %s contain removed values %s", count, colName, strings.Join(quoted, ","))
+	}
+	return fits, evidence, nil
+}
+
+// TableRowsExceed returns true if the table has more than limit rows. The
+// query examines at most limit+1 rows, so its cost is bounded by limit rather
+// than by the table's size. If an error occurs in querying, also returns true
+// (along with the error).
+func (instance *Instance) TableRowsExceed(schema, table string, limit int64) (bool, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return true, err
+	}
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s.%s LIMIT %d) sub", EscapeIdentifier(schema), EscapeIdentifier(table), limit+1)
+	if err := db.QueryRow(query).Scan(&count); err != nil {
+		return true, err
+	}
+	return count > limit, nil
+}
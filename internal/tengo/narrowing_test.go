@@ -0,0 +1,128 @@
+package tengo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNarrowingCheckForClause(t *testing.T) {
+	table := &Table{Name: "tbl"}
+	cases := []struct {
+		oldType     string
+		newType     string
+		expectOK    bool
+		expectQuery string // substring of query; only checked if expectOK
+	}{
+		{"varchar(20)", "varchar(19)", true, "MAX(CHAR_LENGTH(`col`))"},
+		{"text", "varchar(100)", true, "MAX(CHAR_LENGTH(`col`))"},
+		{"varchar(2000)", "tinytext", true, "MAX(LENGTH(`col`))"},
+		{"mediumtext", "tinytext", true, "MAX(LENGTH(`col`))"},
+		{"bigint(20)", "int(11)", true, "MIN(`col`) >= -2147483648 AND MAX(`col`) <= 2147483647"},
+		{"int unsigned", "int", true, "MIN(`col`) >= -2147483648 AND MAX(`col`) <= 2147483647"},
+		{"int", "smallint unsigned", true, "MIN(`col`) >= 0 AND MAX(`col`) <= 65535"},
+		{"enum('a','b','c')", "enum('a','c')", true, "`col` = ?"},
+		{"set('abc','def','ghi')", "set('abc','def')", true, "FIND_IN_SET(?, `col`) > 0"},
+		{"enum('a','b','c')", "enum('c','b','a')", false, ""},
+		{"enum('a','b','c')", "enum('c','a')", false, ""},
+		{"enum('a','b','c')", "enum('a','d')", false, ""},
+		{"set('abc','def','ghi')", "set('def','abc')", false, ""},
+		{"decimal(10,5)", "decimal(9,5)", false, ""},
+		{"varbinary(40)", "varbinary(35)", false, ""},
+		{"varchar(20)", "varbinary(20)", false, ""},
+		{"datetime(4)", "datetime(3)", false, ""},
+		{"int", "varchar(10)", false, ""},
+	}
+	for _, c := range cases {
+		mc := ModifyColumn{
+			Table:     table,
+			OldColumn: &Column{Name: "col", TypeInDB: c.oldType},
+			NewColumn: &Column{Name: "col", TypeInDB: c.newType},
+		}
+		check, ok := narrowingCheckForClause(mc)
+		if ok != c.expectOK {
+			t.Errorf("For %s -> %s, expected ok=%t, instead found ok=%t", c.oldType, c.newType, c.expectOK, ok)
+			continue
+		} else if !ok {
+			continue
+		}
+		query, args := check.query("sch", table.Name)
+		if !strings.Contains(query, c.expectQuery) || !strings.Contains(query, "FROM `sch`.`tbl`") {
+			t.Errorf("For %s -> %s, unexpected query %q", c.oldType, c.newType, query)
+		}
+		if strings.Contains(query, "?") && len(args) != strings.Count(query, "?") {
+			t.Errorf("For %s -> %s, query %q has mismatched arg count %d", c.oldType, c.newType, query, len(args))
+		}
+	}
+
+	// Charset changes cannot be checked
+	mc := ModifyColumn{
+		Table:     table,
+		OldColumn: &Column{Name: "col", TypeInDB: "varchar(20)", CharSet: "utf8mb4"},
+		NewColumn: &Column{Name: "col", TypeInDB: "varchar(10)", CharSet: "latin1"},
+	}
+	if _, ok := narrowingCheckForClause(mc); ok {
+		t.Error("Expected charset change to be uncheckable, but ok=true")
+	}
+}
+
+func TestTableDiffNarrowingChecks(t *testing.T) {
+	table := &Table{Name: "tbl"}
+	narrow := ModifyColumn{
+		Table:     table,
+		OldColumn: &Column{Name: "a", TypeInDB: "varchar(20)"},
+		NewColumn: &Column{Name: "a", TypeInDB: "varchar(10)"},
+	}
+	widen := ModifyColumn{
+		Table:     table,
+		OldColumn: &Column{Name: "b", TypeInDB: "int"},
+		NewColumn: &Column{Name: "b", TypeInDB: "bigint"},
+	}
+	drop := DropColumn{Column: &Column{Name: "c", TypeInDB: "int"}}
+
+	td := &TableDiff{Type: DiffTypeAlter, From: table, To: table, supported: true, alterClauses: []TableAlterClause{narrow, widen}}
+	if checks, ok := td.NarrowingChecks(); !ok || len(checks) != 1 || checks[0].Clause.OldColumn.Name != "a" {
+		t.Errorf("Unexpected result from NarrowingChecks: %+v, %t", checks, ok)
+	}
+	td.alterClauses = []TableAlterClause{widen}
+	if checks, ok := td.NarrowingChecks(); ok || len(checks) != 0 {
+		t.Errorf("Expected no checks with no unsafe clauses, instead found %+v, %t", checks, ok)
+	}
+	td.alterClauses = []TableAlterClause{narrow, drop}
+	if checks, ok := td.NarrowingChecks(); ok || checks != nil {
+		t.Errorf("Expected no checks with a dropped column, instead found %+v, %t", checks, ok)
+	}
+}
+
+func (s TengoIntegrationSuite) TestInstanceCheckNarrowing(t *testing.T) {
+	s.SourceTestSQL(t, "rows.sql")
+	table := s.GetTable(t, "testing", "has_rows")
+	assertCheck := func(colName, newType string, expectFits bool) {
+		t.Helper()
+		col := table.Columns[0]
+		if colName != col.Name {
+			col = table.Columns[1]
+		}
+		newCol := *col
+		newCol.TypeInDB = newType
+		check, ok := narrowingCheckForClause(ModifyColumn{Table: table, OldColumn: col, NewColumn: &newCol})
+		if !ok {
+			t.Fatalf("Unexpected failure to build check for %s -> %s", col.TypeInDB, newType)
+		}
+		if fits, evidence, err := s.d.CheckNarrowing("testing", check); err != nil {
+			t.Errorf("Unexpected error from CheckNarrowing: %v", err)
+		} else if fits != expectFits {
+			t.Errorf("For %s -> %s, expected fits=%t, instead found fits=%t (evidence: %s)", col.TypeInDB, newType, expectFits, fits, evidence)
+		}
+	}
+	assertCheck("name", "varchar(6)", true)
+	assertCheck("name", "varchar(5)", false)
+	assertCheck("id", "tinyint unsigned", true)
+	assertCheck("id", "tinyint", true)
+
+	if exceeds, err := s.d.TableRowsExceed("testing", "has_rows", 4); exceeds || err != nil {
+		t.Errorf("Unexpected result from TableRowsExceed: %t, %v", exceeds, err)
+	}
+	if exceeds, err := s.d.TableRowsExceed("testing", "has_rows", 3); !exceeds || err != nil {
+		t.Errorf("Unexpected result from TableRowsExceed: %t, %v", exceeds, err)
+	}
+}
@@ -0,0 +1,233 @@
+package tengo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Size limits enforced by MySQL and InnoDB, assuming the default 16KB page size.
+const (
+	MaxRowSize            = 65535 // max row size for any storage engine, excluding BLOB and TEXT contents
+	MaxInnoDBInPageRecord = 8126  // max InnoDB record size within a page, with innodb_page_size=16k
+	MaxIndexKeyLarge      = 3072  // max index key length with DYNAMIC or COMPRESSED row format
+	MaxIndexKeyAntelope   = 767   // max index column length with COMPACT or REDUNDANT row format
+)
+
+var reTypeLength = regexp.MustCompile(`^\w+\((\d+)(?:,(\d+))?\)`)
+
+// typeLengths returns the length and scale values from the parenthetical
+// portion of a column type, or zeroes if not present.
+func typeLengths(typ string) (length, scale int) {
+	if matches := reTypeLength.FindStringSubmatch(typ); matches != nil {
+		length, _ = strconv.Atoi(matches[1])
+		scale, _ = strconv.Atoi(matches[2])
+	}
+	return length, scale
+}
+
+// decimalBytes returns the number of bytes used to store a DECIMAL with the
+// supplied number of integer or fractional digits. Each group of 9 digits uses
+// 4 bytes, and leftover digits use a proportionate amount.
+func decimalBytes(digits int) int {
+	leftover := []int{0, 1, 1, 2, 2, 3, 3, 4, 4}
+	return (digits/9)*4 + leftover[digits%9]
+}
+
+// MaxBytes returns the maximum number of bytes used by the column's value
+// within a row, as counted by MySQL's limit of MaxRowSize. For BLOB, TEXT,
+// JSON, and spatial types, this only includes the length and pointer overhead.
+// For variable-length types, this includes the 1 or 2 byte length prefix.
+// Virtual generated columns are not stored, so they return 0.
+func (c *Column) MaxBytes() int {
+	if c.Virtual {
+		return 0
+	}
+	typ := strings.ToLower(c.TypeInDB)
+	base, _, _ := strings.Cut(typ, "(")
+	base, _, _ = strings.Cut(base, " ")
+	length, scale := typeLengths(typ)
+	varLength := func(maxLen int) int {
+		if maxLen < 256 {
+			return maxLen + 1
+		}
+		return maxLen + 2
+	}
+	switch base {
+	case "tinyint", "year":
+		return 1
+	case "smallint":
+		return 2
+	case "mediumint", "date":
+		return 3
+	case "int", "integer":
+		return 4
+	case "bigint", "double", "real":
+		return 8
+	case "float":
+		if length > 24 {
+			return 8
+		}
+		return 4
+	case "decimal", "numeric":
+		if length == 0 {
+			length = 10
+		}
+		return decimalBytes(length-scale) + decimalBytes(scale)
+	case "bit":
+		return (length + 7) / 8
+	case "time":
+		return 3 + (length+1)/2
+	case "datetime":
+		return 5 + (length+1)/2
+	case "timestamp":
+		return 4 + (length+1)/2
+	case "enum":
+		if len(parseEnumSetValues(typ)) < 256 {
+			return 1
+		}
+		return 2
+	case "set":
+		if n := (len(parseEnumSetValues(typ)) + 7) / 8; n <= 4 {
+			return n
+		}
+		return 8
+	case "char":
+		return length * charSetMaxBytes(c.CharSet)
+	case "binary":
+		return length
+	case "varchar":
+		return varLength(length * charSetMaxBytes(c.CharSet))
+	case "varbinary":
+		return varLength(length)
+	case "tinytext", "tinyblob":
+		return 9
+	case "text", "blob":
+		return 10
+	case "mediumtext", "mediumblob":
+		return 11
+	default: // longtext, longblob, json, geometry and other spatial types
+		return 12
+	}
+}
+
+// isLOB returns true if the column's values may be stored entirely off-page
+// by InnoDB, regardless of their length.
+func (c *Column) isLOB() bool {
+	base, _, _ := strings.Cut(strings.ToLower(c.TypeInDB), "(")
+	switch base {
+	case "json", "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		return true
+	}
+	return strings.HasSuffix(base, "text") || strings.HasSuffix(base, "blob")
+}
+
+// MaxRowSize returns the maximum size of a row in the table, as counted by
+// MySQL's limit of MaxRowSize. This includes one bit per nullable column.
+func (t *Table) MaxRowSize() int {
+	var size, nullable int
+	for _, col := range t.Columns {
+		size += col.MaxBytes()
+		if col.Nullable {
+			nullable++
+		}
+	}
+	return size + (nullable+7)/8
+}
+
+// largeIndexPrefixes returns true if the table's row format permits index
+// keys up to MaxIndexKeyLarge bytes per column. This depends on the default
+// row format of flavor, if the table does not specify a row format. If flavor
+// is not known, a modern default row format of DYNAMIC is assumed.
+func (t *Table) largeIndexPrefixes(flavor Flavor) bool {
+	switch strings.ToUpper(t.RowFormatClause()) {
+	case "DYNAMIC", "COMPRESSED":
+		return true
+	case "COMPACT", "REDUNDANT":
+		return false
+	default:
+		return !flavor.Known() || flavor.Min(FlavorMySQL57) || flavor.Min(FlavorMariaDB102)
+	}
+}
+
+// MaxInPageRecordSize returns an estimate of the maximum size of an InnoDB
+// clustered index record in the table, for comparison with
+// MaxInnoDBInPageRecord. Columns which InnoDB may store off-page are counted
+// using the size of their in-page portion, which depends on the row format.
+// This mirrors the calculation performed by InnoDB in strict mode, but
+// disregards some minor overhead, so it may slightly underestimate the size.
+// If the table doesn't use InnoDB, 0 is returned.
+func (t *Table) MaxInPageRecordSize(flavor Flavor) int {
+	if t.Engine != "InnoDB" {
+		return 0
+	}
+	// In DYNAMIC and COMPRESSED row formats, an off-page column just uses a
+	// 20-byte pointer, but InnoDB conservatively counts twice this amount. In
+	// COMPACT and REDUNDANT, a 768-byte prefix is also stored in-page.
+	extMaxSize := 788
+	if t.largeIndexPrefixes(flavor) {
+		extMaxSize = 40
+	}
+	size := 5 + 6 + 7 // record header, DB_TRX_ID, DB_ROLL_PTR
+	if t.ClusteredIndexKey() == nil {
+		size += 6 // DB_ROW_ID
+	}
+	var nullable int
+	for _, col := range t.Columns {
+		if col.Virtual {
+			continue
+		}
+		if col.Nullable {
+			nullable++
+		}
+		// Columns over 255 bytes may be stored off-page
+		colSize := col.MaxBytes()
+		if col.isLOB() || (colSize > 255 && colSize > extMaxSize) {
+			colSize = extMaxSize
+		}
+		size += colSize
+	}
+	return size + (nullable+7)/8
+}
+
+// IndexPartLimit returns the maximum length in bytes of each column in an
+// index in the table, based on its row format and flavor.
+func (t *Table) IndexPartLimit(flavor Flavor) int {
+	if t.largeIndexPrefixes(flavor) {
+		return MaxIndexKeyLarge
+	}
+	return MaxIndexKeyAntelope
+}
+
+// IndexPartBytes returns the maximum length in bytes of the supplied index
+// part of an index in the table. If the part is an expression or refers to a
+// nonexistent column, 0 is returned.
+func (t *Table) IndexPartBytes(part IndexPart) int {
+	var col *Column
+	for _, c := range t.Columns {
+		if c.Name == part.ColumnName {
+			col = c
+			break
+		}
+	}
+	if part.Expression != "" || col == nil {
+		return 0
+	}
+	typ := strings.ToLower(col.TypeInDB)
+	if part.PrefixLength > 0 {
+		if strings.Contains(typ, "binary") || strings.Contains(typ, "blob") {
+			return int(part.PrefixLength)
+		}
+		return int(part.PrefixLength) * charSetMaxBytes(col.CharSet)
+	}
+	size := col.MaxBytes()
+	if strings.HasPrefix(typ, "varchar") || strings.HasPrefix(typ, "varbinary") {
+		// Length prefix bytes don't count towards the index limits
+		if size > 256 {
+			size -= 2
+		} else {
+			size--
+		}
+	}
+	return size
+}
@@ -0,0 +1,154 @@
+package tengo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestColumnMaxBytes(t *testing.T) {
+	cases := []struct {
+		typ      string
+		charSet  string
+		expected int
+	}{
+		{"tinyint(1)", "", 1},
+		{"int unsigned", "", 4},
+		{"bigint(20) unsigned", "", 8},
+		{"float", "", 4},
+		{"float(30)", "", 8},
+		{"decimal(10,2)", "", 5},
+		{"decimal(18,9)", "", 8},
+		{"decimal(65,30)", "", 30},
+		{"bit(9)", "", 2},
+		{"datetime", "", 5},
+		{"datetime(6)", "", 8},
+		{"timestamp(3)", "", 6},
+		{"enum('a','b')", "", 1},
+		{"set('a','b','c','d','e','f','g','h','i')", "", 2},
+		{"char(10)", "utf8mb4", 40},
+		{"binary(16)", "", 16},
+		{"varchar(60)", "latin1", 61},
+		{"varchar(255)", "latin1", 256},
+		{"varchar(255)", "utf8mb4", 1022},
+		{"varbinary(300)", "", 302},
+		{"text", "utf8mb4", 10},
+		{"longblob", "", 12},
+		{"json", "", 12},
+	}
+	for _, c := range cases {
+		col := &Column{Name: "c", TypeInDB: c.typ, CharSet: c.charSet}
+		if actual := col.MaxBytes(); actual != c.expected {
+			t.Errorf("Expected MaxBytes() of %s %s to return %d, instead found %d", c.typ, c.charSet, c.expected, actual)
+		}
+	}
+	col := &Column{Name: "c", TypeInDB: "varchar(100)", CharSet: "latin1", GenerationExpr: "UPPER(d)", Virtual: true}
+	if actual := col.MaxBytes(); actual != 0 {
+		t.Errorf("Expected MaxBytes() of virtual column to return 0, instead found %d", actual)
+	}
+}
+
+// rowSizeTable returns an InnoDB table with an int primary key, followed by
+// count columns of the supplied type.
+func rowSizeTable(count int, typ, charSet, createOptions string) *Table {
+	table := &Table{
+		Name:          "rowsize",
+		Engine:        "InnoDB",
+		CharSet:       charSet,
+		CreateOptions: createOptions,
+		Columns:       []*Column{{Name: "id", TypeInDB: "int unsigned"}},
+	}
+	for n := 0; n < count; n++ {
+		table.Columns = append(table.Columns, &Column{Name: fmt.Sprintf("c%d", n), TypeInDB: typ, CharSet: charSet, Nullable: true})
+	}
+	table.PrimaryKey = &Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Parts: []IndexPart{{ColumnName: "id"}}}
+	return table
+}
+
+func TestTableMaxRowSize(t *testing.T) {
+	table := rowSizeTable(4, "varchar(4000)", "utf8mb4", "")
+	if actual, expected := table.MaxRowSize(), 4+4*16002+1; actual != expected {
+		t.Errorf("Expected MaxRowSize() to return %d, instead found %d", expected, actual)
+	}
+	table = rowSizeTable(20, "text", "utf8mb4", "")
+	if actual, expected := table.MaxRowSize(), 4+20*10+3; actual != expected {
+		t.Errorf("Expected MaxRowSize() to return %d, instead found %d", expected, actual)
+	}
+}
+
+func TestTableMaxInPageRecordSize(t *testing.T) {
+	// Many varchars over 255 bytes: each counts as 40 bytes with DYNAMIC, but in
+	// full with COMPACT if they're within the 788 byte in-page prefix
+	table := rowSizeTable(20, "varchar(100)", "utf8mb4", "")
+	if actual, expected := table.MaxInPageRecordSize(FlavorMySQL80), 18+4+20*40+3; actual != expected {
+		t.Errorf("Expected MaxInPageRecordSize() to return %d, instead found %d", expected, actual)
+	}
+	table.CreateOptions = "ROW_FORMAT=COMPACT"
+	if actual, expected := table.MaxInPageRecordSize(FlavorMySQL80), 18+4+20*402+3; actual != expected {
+		t.Errorf("Expected MaxInPageRecordSize() to return %d, instead found %d", expected, actual)
+	}
+
+	// Default row format depends on flavor
+	table = rowSizeTable(10, "text", "utf8mb4", "")
+	if actual, expected := table.MaxInPageRecordSize(FlavorMySQL57), 18+4+10*40+2; actual != expected {
+		t.Errorf("Expected MaxInPageRecordSize() to return %d, instead found %d", expected, actual)
+	}
+	if actual, expected := table.MaxInPageRecordSize(FlavorMySQL56), 18+4+10*788+2; actual != expected {
+		t.Errorf("Expected MaxInPageRecordSize() to return %d, instead found %d", expected, actual)
+	}
+
+	// Fixed-size columns always count in full; no PK means a hidden row ID
+	table = rowSizeTable(300, "char(10)", "utf8mb4", "")
+	table.PrimaryKey = nil
+	if actual, expected := table.MaxInPageRecordSize(FlavorMySQL80), 24+4+300*40+38; actual != expected {
+		t.Errorf("Expected MaxInPageRecordSize() to return %d, instead found %d", expected, actual)
+	} else if actual <= MaxInnoDBInPageRecord {
+		t.Errorf("Expected MaxInPageRecordSize() to exceed %d, instead found %d", MaxInnoDBInPageRecord, actual)
+	}
+
+	table.Engine = "MyISAM"
+	if actual := table.MaxInPageRecordSize(FlavorMySQL80); actual != 0 {
+		t.Errorf("Expected MaxInPageRecordSize() to return 0 for non-InnoDB table, instead found %d", actual)
+	}
+}
+
+func TestTableIndexPartBytes(t *testing.T) {
+	table := rowSizeTable(0, "", "", "")
+	table.Columns = append(table.Columns,
+		&Column{Name: "name", TypeInDB: "varchar(255)", CharSet: "utf8mb4"},
+		&Column{Name: "code", TypeInDB: "varchar(20)", CharSet: "latin1"},
+		&Column{Name: "body", TypeInDB: "text", CharSet: "utf8mb4"},
+		&Column{Name: "data", TypeInDB: "blob"},
+	)
+	cases := []struct {
+		part     IndexPart
+		expected int
+	}{
+		{IndexPart{ColumnName: "id"}, 4},
+		{IndexPart{ColumnName: "name"}, 1020},
+		{IndexPart{ColumnName: "code"}, 20},
+		{IndexPart{ColumnName: "name", PrefixLength: 100}, 400},
+		{IndexPart{ColumnName: "body", PrefixLength: 1000}, 4000},
+		{IndexPart{ColumnName: "data", PrefixLength: 1000}, 1000},
+		{IndexPart{Expression: "(lower(`name`))"}, 0},
+		{IndexPart{ColumnName: "nonexistent"}, 0},
+	}
+	for _, c := range cases {
+		if actual := table.IndexPartBytes(c.part); actual != c.expected {
+			t.Errorf("Expected IndexPartBytes(%+v) to return %d, instead found %d", c.part, c.expected, actual)
+		}
+	}
+
+	if limit := table.IndexPartLimit(FlavorMySQL80); limit != MaxIndexKeyLarge {
+		t.Errorf("Unexpected IndexPartLimit: %d", limit)
+	}
+	if limit := table.IndexPartLimit(FlavorMariaDB101); limit != MaxIndexKeyAntelope {
+		t.Errorf("Unexpected IndexPartLimit: %d", limit)
+	}
+	if limit := table.IndexPartLimit(FlavorUnknown); limit != MaxIndexKeyLarge {
+		t.Errorf("Unexpected IndexPartLimit: %d", limit)
+	}
+	table.CreateOptions = "ROW_FORMAT=REDUNDANT"
+	if limit := table.IndexPartLimit(FlavorMySQL80); limit != MaxIndexKeyAntelope {
+		t.Errorf("Unexpected IndexPartLimit: %d", limit)
+	}
+}
@@ -32,13 +32,25 @@ func AddGlobalOptions(cmd *mybase.Command) {
 	cmd.AddOptions("global",
 		mybase.StringOption("user", 'u', "root", "Username to connect to database host"),
 		mybase.StringOption("password", 'p', "$MYSQL_PWD", "Password for database user; omit value to prompt from TTY").ValueOptional(),
+		mybase.StringOption("password-command", 0, "", "External command to shell out to for obtaining password for each host; see manual for template vars"),
+		mybase.StringOption("login-path", 0, "client", "Section of ~/.mylogin.cnf to read for user, password, port, and socket"),
 		mybase.StringOption("host-wrapper", 'H', "", "External bin to shell out to for host lookup; see manual for template vars"),
+		mybase.StringOption("host-inventory", 0, "", "Path to YAML or JSON file mapping environment and cluster names to hosts"),
 		mybase.StringOption("connect-options", 'o', "", "Comma-separated session options to set upon connecting to each database instance"),
 		mybase.StringOption("ignore-schema", 0, "", "Ignore schemas that match regex"),
 		mybase.StringOption("ignore-table", 0, "", "Ignore tables that match regex"),
 		mybase.StringOption("ignore-proc", 0, "", "Ignore stored procedures that match regex"),
 		mybase.StringOption("ignore-func", 0, "", "Ignore functions that match regex"),
-		mybase.StringOption("ssl-mode", 0, "", `Specify desired connection security SSL/TLS usage (valid values: "disabled", "preferred", "required")`),
+		mybase.StringOption("ssh-host", 0, "", "Connect to database hosts through an SSH tunnel via this bastion host"),
+		mybase.StringOption("ssh-user", 0, "", "Username for --ssh-host (default current OS user)"),
+		mybase.StringOption("ssh-key", 0, "", "Path to private key file for --ssh-host (default use ssh-agent only)"),
+		mybase.StringOption("ssh-known-hosts", 0, "", "Path to known_hosts file for verifying --ssh-host (default ~/.ssh/known_hosts)"),
+		mybase.BoolOption("ssh-forward-agent", 0, false, "Forward the running ssh-agent to --ssh-host"),
+		mybase.StringOption("ssl-mode", 0, "", `Specify desired connection security SSL/TLS usage (valid values: "disabled", "preferred", "required", "verify_ca", "verify_identity")`),
+		mybase.StringOption("ssl-ca", 0, "", "Path to PEM file of trusted SSL/TLS certificate authorities; implies --ssl-mode=verify_ca unless otherwise set"),
+		mybase.StringOption("ssl-cert", 0, "", "Path to PEM file of SSL/TLS client certificate"),
+		mybase.StringOption("ssl-key", 0, "", "Path to PEM file of SSL/TLS client private key"),
+		mybase.StringOption("ssl-cipher", 0, "", "Colon-separated list of permissible ciphers for SSL/TLS connections"),
 		mybase.BoolOption("debug", 0, false, "Enable debug logging"),
 		mybase.BoolOption("my-cnf", 0, true, "Parse ~/.my.cnf for configuration"),
 	)
@@ -47,13 +59,15 @@ func AddGlobalOptions(cmd *mybase.Command) {
 // AddGlobalConfigFiles takes the mybase.Config generated from the CLI and adds
 // global option files as sources.
 func AddGlobalConfigFiles(cfg *mybase.Config) {
-	globalFilePaths := make([]string, 0, 4)
+	globalFilePaths := make([]string, 0, 5)
+	var loginPathFile string
 
 	// Avoid using "real" global paths in test logic. Otherwise, if the user
 	// running the test happens to have a ~/.my.cnf, ~/.skeema, /etc/skeema, it
 	// it would affect the test logic.
 	if cfg.IsTest {
-		globalFilePaths = append(globalFilePaths, "fake-etc/skeema", "fake-home/.my.cnf")
+		loginPathFile = "fake-home/.mylogin.cnf"
+		globalFilePaths = append(globalFilePaths, "fake-etc/skeema", "fake-home/.my.cnf", loginPathFile)
 	} else {
 		if runtime.GOOS == "windows" {
 			globalFilePaths = append(globalFilePaths, "C:\\Program Files\\Skeema\\skeema.cnf")
@@ -61,11 +75,20 @@ func AddGlobalConfigFiles(cfg *mybase.Config) {
 			globalFilePaths = append(globalFilePaths, "/etc/skeema", "/usr/local/etc/skeema")
 		}
 		if home, err := os.UserHomeDir(); home != "" && err == nil {
-			globalFilePaths = append(globalFilePaths, filepath.Join(home, ".my.cnf"), filepath.Join(home, ".skeema"))
+			loginPathFile = filepath.Join(home, ".mylogin.cnf")
+			if envPath := os.Getenv("MYSQL_TEST_LOGIN_FILE"); envPath != "" {
+				loginPathFile = envPath // same override as MySQL client programs
+			}
+			globalFilePaths = append(globalFilePaths, filepath.Join(home, ".my.cnf"), loginPathFile, filepath.Join(home, ".skeema"))
 		}
 	}
 
 	for _, path := range globalFilePaths {
+		// The login path file is obfuscated, requiring special handling
+		if path == loginPathFile {
+			addLoginPathSource(cfg, path)
+			continue
+		}
 		f := mybase.NewFile(path)
 		if !f.Exists() {
 			continue
@@ -95,6 +118,25 @@ func AddGlobalConfigFiles(cfg *mybase.Config) {
 	}
 }
 
+// addLoginPathSource adds values from the login path file as a source for cfg,
+// using the section specified by the login-path option. Nothing is added if the
+// file does not exist, or if the my-cnf option has been disabled.
+func addLoginPathSource(cfg *mybase.Config, path string) {
+	if !cfg.GetBool("my-cnf") {
+		return
+	} else if _, err := os.Stat(path); err != nil {
+		return
+	}
+	values, err := ReadLoginPath(path, cfg.Get("login-path"))
+	if err != nil {
+		log.Warnf("Ignoring login path file %s due to read error: %s", path, err)
+		return
+	}
+	if len(values) > 0 {
+		cfg.AddSource(mybase.StringMapValues(values))
+	}
+}
+
 // ProcessSpecialGlobalOptions performs special handling of global options with
 // unusual semantics -- handling restricted placement of host and schema;
 // obtaining a password from STDIN if requested; enable debug logging.
@@ -170,7 +212,7 @@ func NoInteractiveInput() (string, error) {
 // returns the specified string.
 func NewMockPasswordInput(mockPassword string) PasswordInputSource {
 	return PasswordInputSource(func() (string, error) {
-		fmt.Fprintf(os.Stderr, strings.Repeat("*", len(mockPassword)))
+		fmt.Fprint(os.Stderr, strings.Repeat("*", len(mockPassword)))
 		return mockPassword, nil
 	})
 }
@@ -367,3 +409,35 @@ func IgnorePatterns(cfg *mybase.Config) ([]tengo.ObjectPattern, error) {
 	}
 	return patterns, nil
 }
+
+// This mapping of only-options to object types is stored in a slice for the
+// same reason as ignoreOptionToTypes.
+var onlyOptionToTypes = []struct {
+	optionName string
+	types      []tengo.ObjectType
+}{
+	{"only-table", []tengo.ObjectType{tengo.ObjectTypeTable}},
+	{"only-proc", []tengo.ObjectType{tengo.ObjectTypeProc}},
+	{"only-func", []tengo.ObjectType{tengo.ObjectTypeFunc}},
+}
+
+// OnlyPatterns compiles the regexes in the supplied mybase.Config's only-*
+// options, which are only available in commands that generate DDL. If none of
+// these options are set, a nil slice is returned, meaning that objects should
+// not be filtered. Otherwise, only objects matching at least one of the
+// returned patterns should be operated upon; objects of types lacking a
+// corresponding only-* option are excluded entirely.
+func OnlyPatterns(cfg *mybase.Config) ([]tengo.ObjectPattern, error) {
+	var patterns []tengo.ObjectPattern
+	for _, opt := range onlyOptionToTypes {
+		re, err := cfg.GetRegexp(opt.optionName)
+		if err != nil {
+			return nil, err
+		} else if re != nil {
+			for _, objType := range opt.types {
+				patterns = append(patterns, tengo.ObjectPattern{Type: objType, Pattern: re})
+			}
+		}
+	}
+	return patterns, nil
+}
@@ -322,3 +322,35 @@ func TestIgnorePatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestOnlyPatterns(t *testing.T) {
+	cmd := mybase.NewCommand("skeematest", "", "", nil)
+	AddGlobalOptions(cmd)
+	cmd.AddOption(mybase.StringOption("only-table", 0, "", "Restrict DDL to tables matching regex"))
+	cmd.AddOption(mybase.StringOption("only-proc", 0, "", "Restrict DDL to stored procedures matching regex"))
+	cmd.AddOption(mybase.StringOption("only-func", 0, "", "Restrict DDL to functions matching regex"))
+
+	cfg := mybase.ParseFakeCLI(t, cmd, "skeematest")
+	if only, err := OnlyPatterns(cfg); only != nil || err != nil {
+		t.Errorf("Expected nil patterns and nil error without only-* options, instead found %v, %v", only, err)
+	}
+
+	cfg = mybase.ParseFakeCLI(t, cmd, "skeematest --only-table='^foo$' --only-func=bar")
+	only, err := OnlyPatterns(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error from OnlyPatterns: %v", err)
+	} else if len(only) != 2 {
+		t.Fatalf("Expected OnlyPatterns to return 2 patterns, instead found %d", len(only))
+	}
+	if only[0].Type != tengo.ObjectTypeTable || only[1].Type != tengo.ObjectTypeFunc {
+		t.Errorf("Unexpected pattern types returned: %s, %s", only[0].Type, only[1].Type)
+	}
+	if !only[0].Match(tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "foo"}) || only[0].Match(tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "foobar"}) {
+		t.Errorf("Unexpected matching behavior from pattern %s", only[0].String())
+	}
+
+	cfg = mybase.ParseFakeCLI(t, cmd, "skeematest --only-proc='+'")
+	if _, err := OnlyPatterns(cfg); err == nil {
+		t.Error("Expected error from invalid regex, but err was nil")
+	}
+}
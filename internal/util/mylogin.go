@@ -0,0 +1,117 @@
+package util
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// loginPathOptions lists the options which may be obtained from a login path
+// file. mysql_config_editor also supports host, but Skeema does not permit
+// host to be configured globally.
+var loginPathOptions = []string{"user", "password", "port", "socket"}
+
+// ReadLoginPath reads MySQL's obfuscated login path file (typically
+// ~/.mylogin.cnf, as written by mysql_config_editor) and returns values for the
+// options in the named section. As with MySQL client programs, values in the
+// [client] section are also used, but are overridden by the named section.
+// Only user, password, port, and socket are returned.
+func ReadLoginPath(path, loginPath string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	contents, err := decryptLoginPathFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decrypt %s: %w", path, err)
+	}
+
+	sections := parseLoginPathContents(contents)
+	result := make(map[string]string)
+	for _, name := range []string{"client", loginPath} {
+		for _, option := range loginPathOptions {
+			if value, ok := sections[name][option]; ok {
+				result[option] = value
+			}
+		}
+	}
+	return result, nil
+}
+
+// decryptLoginPathFile decrypts the contents of a login path file. The file
+// format consists of 4 unused bytes; a 20-byte key; and then any number of
+// lines, each encrypted separately using AES-128-ECB with PKCS padding, and
+// prefixed with a 4-byte little-endian length.
+func decryptLoginPathFile(r io.Reader) (string, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", errors.New("file is too short")
+	}
+	var key [16]byte
+	for n, b := range header[4:] {
+		key[n%16] ^= b
+	}
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for {
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err == io.EOF {
+			return b.String(), nil
+		} else if err != nil {
+			return "", err
+		} else if length == 0 || length%aes.BlockSize != 0 || length > 65536 {
+			return "", fmt.Errorf("invalid encrypted line length %d", length)
+		}
+		line := make([]byte, length)
+		if _, err := io.ReadFull(r, line); err != nil {
+			return "", err
+		}
+		for pos := 0; pos < len(line); pos += aes.BlockSize {
+			block.Decrypt(line[pos:pos+aes.BlockSize], line[pos:pos+aes.BlockSize])
+		}
+		padding := int(line[len(line)-1])
+		if padding == 0 || padding > aes.BlockSize || !bytes.Equal(line[len(line)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+			return "", errors.New("invalid padding")
+		}
+		b.Write(line[:len(line)-padding])
+	}
+}
+
+// parseLoginPathContents parses the decrypted contents of a login path file,
+// which uses a simple option file format, returning a map of section name to
+// option name to value. Values are returned as-is, including any quotes.
+func parseLoginPathContents(contents string) map[string]map[string]string {
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if sections[name] == nil {
+				sections[name] = make(map[string]string)
+			}
+			current = sections[name]
+			continue
+		}
+		if current == nil {
+			continue // option outside of any section
+		}
+		name, value, _ := strings.Cut(line, "=")
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+		current[name] = strings.TrimSpace(value)
+	}
+	return sections
+}
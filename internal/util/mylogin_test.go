@@ -0,0 +1,115 @@
+package util
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+// writeLoginPathFile writes contents to path in the same obfuscated format
+// used by mysql_config_editor.
+func writeLoginPathFile(t *testing.T, path, contents string) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0, 0})
+	rawKey := []byte("0123456789abcdefghij")
+	buf.Write(rawKey)
+	var key [16]byte
+	for n, b := range rawKey {
+		key[n%16] ^= b
+	}
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		t.Fatalf("Unexpected error from aes.NewCipher: %v", err)
+	}
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		if line == "" {
+			continue
+		}
+		padding := aes.BlockSize - len(line)%aes.BlockSize
+		plain := append([]byte(line), bytes.Repeat([]byte{byte(padding)}, padding)...)
+		for pos := 0; pos < len(plain); pos += aes.BlockSize {
+			block.Encrypt(plain[pos:pos+aes.BlockSize], plain[pos:pos+aes.BlockSize])
+		}
+		binary.Write(&buf, binary.LittleEndian, uint32(len(plain)))
+		buf.Write(plain)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("Unable to write %s: %v", path, err)
+	}
+}
+
+func TestReadLoginPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".mylogin.cnf")
+	writeLoginPathFile(t, path, "[client]\nuser = \"base\"\npassword = \"basepw\"\n[prod]\nuser = \"produser\"\npassword = \"it's a secret\"\nhost = \"db.example.com\"\nport = 3307\n")
+
+	expected := map[string]map[string]string{
+		"client": {"user": `"base"`, "password": `"basepw"`},
+		"prod":   {"user": `"produser"`, "password": `"it's a secret"`, "port": "3307"},
+		"nope":   {"user": `"base"`, "password": `"basepw"`},
+	}
+	for loginPath, expect := range expected {
+		values, err := ReadLoginPath(path, loginPath)
+		if err != nil {
+			t.Fatalf("Unexpected error from ReadLoginPath: %v", err)
+		}
+		if len(values) != len(expect) {
+			t.Errorf("login-path=%s: expected %v, instead found %v", loginPath, expect, values)
+		}
+		for k, v := range expect {
+			if values[k] != v {
+				t.Errorf("login-path=%s: expected %s=%s, instead found %s", loginPath, k, v, values[k])
+			}
+		}
+	}
+
+	// Corrupted or truncated files should error
+	if err := os.WriteFile(path, []byte("short"), 0600); err != nil {
+		t.Fatalf("Unable to write %s: %v", path, err)
+	}
+	if _, err := ReadLoginPath(path, "client"); err == nil {
+		t.Error("Expected error from truncated file, but err was nil")
+	}
+	if err := os.WriteFile(path, append(make([]byte, 24), 5, 0, 0, 0, 1, 2, 3, 4, 5), 0600); err != nil {
+		t.Fatalf("Unable to write %s: %v", path, err)
+	}
+	if _, err := ReadLoginPath(path, "client"); err == nil {
+		t.Error("Expected error from invalid line length, but err was nil")
+	}
+}
+
+func TestAddGlobalConfigFilesLoginPath(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddArg("environment", "production", false)
+	cmdSuite.AddSubCommand(cmd)
+
+	os.MkdirAll("fake-home", 0777)
+	defer os.RemoveAll("fake-home")
+	os.WriteFile("fake-home/.my.cnf", []byte("user=fromcnf\n"), 0777)
+	writeLoginPathFile(t, "fake-home/.mylogin.cnf", "[client]\npassword = \"clientpw\"\n[prod]\nuser = \"produser\"\npassword = \"prodpw\"\n")
+
+	// Login path file overrides .my.cnf; default login-path is client
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	AddGlobalConfigFiles(cfg)
+	if user, password := cfg.GetAllowEnvVar("user"), cfg.GetAllowEnvVar("password"); user != "fromcnf" || password != "clientpw" {
+		t.Errorf("Unexpected user=%q password=%q", user, password)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --login-path=prod")
+	AddGlobalConfigFiles(cfg)
+	if user, password := cfg.GetAllowEnvVar("user"), cfg.GetAllowEnvVar("password"); user != "produser" || password != "prodpw" {
+		t.Errorf("Unexpected user=%q password=%q", user, password)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --login-path=prod --skip-my-cnf")
+	AddGlobalConfigFiles(cfg)
+	if cfg.Supplied("password") {
+		t.Errorf("Expected --skip-my-cnf to also skip login path file, but password was supplied as %q", cfg.GetRaw("password"))
+	}
+}
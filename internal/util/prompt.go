@@ -0,0 +1,87 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LineInputSource is a function that can be used to obtain a line of input
+// interactively.
+type LineInputSource func() (string, error)
+
+// InteractiveLineInput reads a line from STDIN. This only works if STDIN is a
+// terminal.
+func InteractiveLineInput() (string, error) {
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// NoInteractiveLineInput always returns an error instead of attempting to read
+// input.
+func NoInteractiveLineInput() (string, error) {
+	return "", errors.New("STDIN must be a TTY to read interactive input")
+}
+
+// NewMockLineInput returns a LineInputSource function which returns the
+// supplied responses in order, and then returns errors once they have all been
+// used.
+func NewMockLineInput(responses ...string) LineInputSource {
+	return LineInputSource(func() (string, error) {
+		if len(responses) == 0 {
+			return NoInteractiveLineInput()
+		}
+		response := responses[0]
+		responses = responses[1:]
+		fmt.Fprintln(os.Stderr, response)
+		return response, nil
+	})
+}
+
+// LinePromptInput is the input source used by PromptLine and PromptConfirm to
+// obtain input interactively, or to mock such an input for testing purposes.
+var LinePromptInput LineInputSource
+
+func init() {
+	// Don't attempt interactive prompts if STDIN isn't a TTY, or if running a
+	// test suite
+	if !StdinIsTerminal() || strings.HasSuffix(os.Args[0], ".test") || strings.HasSuffix(os.Args[0], ".test.exe") {
+		LinePromptInput = LineInputSource(NoInteractiveLineInput)
+	} else {
+		LinePromptInput = LineInputSource(InteractiveLineInput)
+	}
+}
+
+// PromptLine writes a prompt and reads a line of input, which is returned with
+// surrounding whitespace trimmed. Requires that STDIN is a TTY. Args behave
+// like those to fmt.Printf(). As with PromptPassword, the prompt will be
+// written to STDERR, unless STDERR is a non-terminal and STDOUT is a terminal,
+// in which case STDOUT is used.
+func PromptLine(format string, a ...interface{}) (string, error) {
+	w := os.Stderr
+	if !StderrIsTerminal() && StdoutIsTerminal() {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, format, a...)
+	line, err := LinePromptInput()
+	return strings.TrimSpace(line), err
+}
+
+// PromptConfirm writes a prompt and reads a yes/no answer, returning true only
+// if the answer was "y" or "yes" (case-insensitive). Any other answer, or any
+// error reading input, is treated as a no.
+func PromptConfirm(format string, a ...interface{}) (bool, error) {
+	answer, err := PromptLine(format+" [y/N] ", a...)
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
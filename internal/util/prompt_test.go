@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestPromptConfirm(t *testing.T) {
+	orig := LinePromptInput
+	defer func() {
+		LinePromptInput = orig
+	}()
+
+	LinePromptInput = NewMockLineInput("y", " YES ", "n", "", "yeah")
+	for _, expected := range []bool{true, true, false, false, false} {
+		if actual, err := PromptConfirm("Continue?"); err != nil || actual != expected {
+			t.Errorf("Expected PromptConfirm to return %t, nil; instead found %t, %v", expected, actual, err)
+		}
+	}
+	if actual, err := PromptConfirm("Continue?"); err == nil || actual {
+		t.Errorf("Expected PromptConfirm to return false and an error once input exhausted; instead found %t, %v", actual, err)
+	}
+
+	LinePromptInput = NewMockLineInput("  hello world ")
+	if line, err := PromptLine("Say something: "); err != nil || line != "hello world" {
+		t.Errorf("Unexpected return from PromptLine: %q, %v", line, err)
+	}
+}
@@ -0,0 +1,249 @@
+package util
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/skeema/internal/tengo"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnelConfig describes how to reach database hosts through an SSH bastion.
+// Authentication to the bastion may use a running ssh-agent. If ForwardAgent is
+// true, that agent is also forwarded to the bastion, like ssh -A.
+type SSHTunnelConfig struct {
+	Host           string // bastion hostname, optionally with :port suffix; port 22 if omitted
+	User           string // defaults to the current OS user if blank
+	KeyFile        string // path to an unencrypted private key; if blank, only ssh-agent is used
+	KnownHostsFile string // defaults to ~/.ssh/known_hosts if blank
+	ForwardAgent   bool   // forward the ssh-agent at SSH_AUTH_SOCK to the bastion
+}
+
+// sshTunnel is a lazily-connected SSH client, shared by all database
+// connections using the same SSHTunnelConfig.
+type sshTunnel struct {
+	config  SSHTunnelConfig
+	client  *ssh.Client
+	session *ssh.Session // only used for requesting agent forwarding
+	m       sync.Mutex
+}
+
+var sshTunnels struct {
+	sync.Mutex
+	byNetwork map[string]*sshTunnel
+}
+
+func init() {
+	sshTunnels.byNetwork = make(map[string]*sshTunnel)
+}
+
+// RegisterSSHTunnel registers a dial function with the MySQL driver, which
+// routes database connections through an SSH bastion host. It returns a
+// network name which should be used in place of "tcp" in DSNs. Repeated calls
+// with the same config return the same network name, and share the same SSH
+// connection. The SSH connection is not established until first use.
+func RegisterSSHTunnel(config SSHTunnelConfig) (string, error) {
+	if config.Host == "" {
+		return "", errors.New("SSH tunnel requires a host")
+	}
+	if _, _, err := tengo.SplitHostOptionalPort(config.Host); err != nil {
+		return "", fmt.Errorf("Invalid SSH host %q: %w", config.Host, err)
+	}
+	if config.User == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("Unable to determine default SSH user: %w", err)
+		}
+		config.User = u.Username
+	}
+	if config.KnownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("Unable to determine location of known_hosts file: %w", err)
+		}
+		config.KnownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	if config.ForwardAgent && os.Getenv("SSH_AUTH_SOCK") == "" {
+		return "", errors.New("SSH agent forwarding requires a running ssh-agent with SSH_AUTH_SOCK set")
+	}
+
+	hash := sha1.Sum([]byte(strings.Join([]string{config.Host, config.User, config.KeyFile, config.KnownHostsFile, fmt.Sprint(config.ForwardAgent)}, "\x00")))
+	network := "ssh-" + hex.EncodeToString(hash[:])[:12]
+	sshTunnels.Lock()
+	defer sshTunnels.Unlock()
+	if _, already := sshTunnels.byNetwork[network]; !already {
+		tunnel := &sshTunnel{config: config}
+		sshTunnels.byNetwork[network] = tunnel
+		mysql.RegisterDialContext(network, tunnel.dial)
+	}
+	return network, nil
+}
+
+// dial opens a connection to addr from the bastion host, connecting to the
+// bastion first if needed. It satisfies mysql.DialContextFunc.
+func (tunnel *sshTunnel) dial(ctx context.Context, addr string) (net.Conn, error) {
+	client, err := tunnel.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, "tcp", addr)
+	if err == nil {
+		return conn, nil
+	}
+
+	// If the bastion rejected the channel (e.g. the database host is down), or
+	// the SSH connection is otherwise still working, reconnecting would not help,
+	// and would break all other database connections using this tunnel.
+	var chanErr *ssh.OpenChannelError
+	if errors.As(err, &chanErr) || sshClientAlive(client) {
+		return nil, err
+	}
+
+	// The SSH connection has dropped; reconnect once and try again
+	tunnel.reset(client)
+	if client, err = tunnel.connect(ctx); err != nil {
+		return nil, err
+	}
+	return client.DialContext(ctx, "tcp", addr)
+}
+
+// sshClientAlive returns true if client's underlying SSH transport is still
+// responsive. The server's reply to the keepalive request is irrelevant; only
+// a transport failure results in an error.
+func sshClientAlive(client *ssh.Client) bool {
+	_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+	return err == nil
+}
+
+// connect returns the tunnel's SSH client, establishing the SSH connection if
+// it is not already connected.
+func (tunnel *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	tunnel.m.Lock()
+	defer tunnel.m.Unlock()
+	if tunnel.client != nil {
+		return tunnel.client, nil
+	}
+	clientConfig, agentConn, err := tunnel.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if agentConn != nil {
+		// This agent connection is only needed during authentication; agent
+		// forwarding uses separate connections to the agent
+		defer agentConn.Close()
+	}
+	host, port, _ := tengo.SplitHostOptionalPort(tunnel.config.Host)
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(host, fmt.Sprint(port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to connect to SSH host %s: %w", addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Unable to establish SSH connection to %s: %w", addr, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	if tunnel.config.ForwardAgent {
+		if tunnel.session, err = forwardAgent(client); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("Unable to forward SSH agent to %s: %w", addr, err)
+		}
+	}
+	tunnel.client = client
+	return tunnel.client, nil
+}
+
+// forwardAgent forwards the local ssh-agent to the SSH server. The server only
+// accepts agent forwarding as part of a session, so a session is opened and
+// returned; it should be kept open for as long as forwarding is needed.
+func forwardAgent(client *ssh.Client) (*ssh.Session, error) {
+	if err := agent.ForwardToRemote(client, os.Getenv("SSH_AUTH_SOCK")); err != nil {
+		return nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if err := agent.RequestAgentForwarding(session); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+// reset closes and discards client, if it is still the tunnel's current
+// client.
+func (tunnel *sshTunnel) reset(client *ssh.Client) {
+	tunnel.m.Lock()
+	defer tunnel.m.Unlock()
+	if tunnel.client == client {
+		if tunnel.session != nil {
+			tunnel.session.Close()
+			tunnel.session = nil
+		}
+		tunnel.client.Close()
+		tunnel.client = nil
+	}
+}
+
+// clientConfig returns an ssh.ClientConfig which verifies the bastion's host
+// key using the known_hosts file. Authentication uses the configured private
+// key file if any, as well as any keys available from a running ssh-agent. If
+// an ssh-agent is used, its connection is also returned, and the caller must
+// close it once authentication is complete.
+func (tunnel *sshTunnel) clientConfig() (*ssh.ClientConfig, net.Conn, error) {
+	hostKeyCallback, err := knownhosts.New(tunnel.config.KnownHostsFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read SSH known_hosts file: %w", err)
+	}
+	var auths []ssh.AuthMethod
+	if tunnel.config.KeyFile != "" {
+		keyBytes, err := os.ReadFile(tunnel.config.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to read SSH key file: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyBytes)
+		if err != nil {
+			var ppErr *ssh.PassphraseMissingError
+			if errors.As(err, &ppErr) {
+				return nil, nil, fmt.Errorf("SSH key file %s is encrypted with a passphrase. Add it to ssh-agent, and omit ssh-key to use ssh-agent instead.", tunnel.config.KeyFile)
+			}
+			return nil, nil, fmt.Errorf("Unable to parse SSH key file %s: %w", tunnel.config.KeyFile, err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	var agentConn net.Conn
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if agentConn, err = net.Dial("unix", sock); err == nil {
+			auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		} else {
+			agentConn = nil
+		}
+	}
+	if len(auths) == 0 {
+		return nil, nil, errors.New("No SSH authentication method available: supply ssh-key, or run ssh-agent with SSH_AUTH_SOCK set")
+	}
+	return &ssh.ClientConfig{
+		User:            tunnel.config.User,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+	}, agentConn, nil
+}
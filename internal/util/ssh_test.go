@@ -0,0 +1,338 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestSSHTunnel(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "") // only test key file auth, not any running agent
+	tempDir := t.TempDir()
+	hostSigner := newTestSSHSigner(t, "")
+	clientSigner := newTestSSHSigner(t, filepath.Join(tempDir, "id_ed25519"))
+	sshAddr, _ := startTestSSHServer(t, hostSigner, clientSigner.PublicKey())
+
+	// Simple TCP server to connect to through the tunnel
+	targetListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	t.Cleanup(func() { targetListener.Close() })
+	go func() {
+		for {
+			conn, err := targetListener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("hello"))
+			conn.Close()
+		}
+	}()
+
+	knownHostsFile := filepath.Join(tempDir, "known_hosts")
+	knownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(sshAddr)}, hostSigner.PublicKey())
+	if err := os.WriteFile(knownHostsFile, []byte(knownHostsLine+"\n"), 0600); err != nil {
+		t.Fatalf("Unable to write known_hosts: %v", err)
+	}
+	config := SSHTunnelConfig{
+		Host:           sshAddr,
+		User:           "tester",
+		KeyFile:        filepath.Join(tempDir, "id_ed25519"),
+		KnownHostsFile: knownHostsFile,
+	}
+	network, err := RegisterSSHTunnel(config)
+	if err != nil {
+		t.Fatalf("Unexpected error from RegisterSSHTunnel: %v", err)
+	}
+	if network2, err := RegisterSSHTunnel(config); err != nil || network2 != network {
+		t.Errorf("Expected repeated call to RegisterSSHTunnel to return %q, nil; instead found %q, %v", network, network2, err)
+	}
+	assertDial := func(network string, expectErr bool) {
+		t.Helper()
+		conn, err := sshTunnels.byNetwork[network].dial(context.Background(), targetListener.Addr().String())
+		if expectErr {
+			if err == nil {
+				conn.Close()
+				t.Error("Expected error from dial, but err was nil")
+			}
+			return
+		} else if err != nil {
+			t.Fatalf("Unexpected error from dial: %v", err)
+		}
+		defer conn.Close()
+		if data, err := io.ReadAll(conn); err != nil || string(data) != "hello" {
+			t.Errorf("Unexpected result reading through tunnel: %q, %v", data, err)
+		}
+	}
+	assertDial(network, false)
+	assertDial(network, false) // second dial reuses same SSH connection
+
+	// Failure to reach a target through the bastion must not reset the shared
+	// SSH connection, since that would break other connections using it
+	tunnel := sshTunnels.byNetwork[network]
+	client := tunnel.client
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	closedAddr := closedListener.Addr().String()
+	closedListener.Close()
+	if conn, err := tunnel.dial(context.Background(), closedAddr); err == nil {
+		conn.Close()
+		t.Error("Expected error dialing closed port through tunnel, but err was nil")
+	}
+	if tunnel.client != client {
+		t.Error("Expected failed dial to retain existing SSH connection, but it was reset")
+	}
+	assertDial(network, false)
+
+	// If the SSH connection drops, the next dial should reconnect
+	client.Close()
+	assertDial(network, false)
+	if tunnel.client == client {
+		t.Error("Expected dial to reconnect after SSH connection dropped, but it did not")
+	}
+
+	// Host key mismatch must fail
+	otherKnownHostsFile := filepath.Join(tempDir, "other_known_hosts")
+	otherLine := knownhosts.Line([]string{knownhosts.Normalize(sshAddr)}, newTestSSHSigner(t, "").PublicKey())
+	if err := os.WriteFile(otherKnownHostsFile, []byte(otherLine+"\n"), 0600); err != nil {
+		t.Fatalf("Unable to write known_hosts: %v", err)
+	}
+	config.KnownHostsFile = otherKnownHostsFile
+	if network, err = RegisterSSHTunnel(config); err != nil {
+		t.Fatalf("Unexpected error from RegisterSSHTunnel: %v", err)
+	}
+	assertDial(network, true)
+
+	// Unauthorized client key must fail
+	config.KnownHostsFile = knownHostsFile
+	config.KeyFile = filepath.Join(tempDir, "id_other")
+	newTestSSHSigner(t, config.KeyFile)
+	if network, err = RegisterSSHTunnel(config); err != nil {
+		t.Fatalf("Unexpected error from RegisterSSHTunnel: %v", err)
+	}
+	assertDial(network, true)
+
+	// Invalid configurations
+	if _, err := RegisterSSHTunnel(SSHTunnelConfig{}); err == nil {
+		t.Error("Expected error from blank host, but err was nil")
+	}
+	if _, err := RegisterSSHTunnel(SSHTunnelConfig{Host: "bastion:banana"}); err == nil {
+		t.Error("Expected error from invalid port, but err was nil")
+	}
+}
+
+func TestSSHTunnelForwardAgent(t *testing.T) {
+	tempDir := t.TempDir()
+	hostSigner := newTestSSHSigner(t, "")
+	_, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatalf("Unable to create signer: %v", err)
+	}
+	sshAddr, forwardedKeys := startTestSSHServer(t, hostSigner, clientSigner.PublicKey())
+	knownHostsFile := filepath.Join(tempDir, "known_hosts")
+	knownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(sshAddr)}, hostSigner.PublicKey())
+	if err := os.WriteFile(knownHostsFile, []byte(knownHostsLine+"\n"), 0600); err != nil {
+		t.Fatalf("Unable to write known_hosts: %v", err)
+	}
+	config := SSHTunnelConfig{
+		Host:           sshAddr,
+		User:           "tester",
+		KnownHostsFile: knownHostsFile,
+		ForwardAgent:   true,
+	}
+
+	// Forwarding requires an agent
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := RegisterSSHTunnel(config); err == nil {
+		t.Error("Expected error from ForwardAgent without SSH_AUTH_SOCK, but err was nil")
+	}
+
+	// Run an in-process agent holding the client key, which is used both for
+	// authentication and for verifying that the server can use the forwarded
+	// agent
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: clientKey}); err != nil {
+		t.Fatalf("Unable to add key to agent: %v", err)
+	}
+	sockPath := filepath.Join(tempDir, "agent.sock")
+	agentListener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("Unable to listen on unix socket: %v", err)
+	}
+	t.Cleanup(func() { agentListener.Close() })
+	go func() {
+		for {
+			conn, err := agentListener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				agent.ServeAgent(keyring, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sockPath)
+
+	network, err := RegisterSSHTunnel(config)
+	if err != nil {
+		t.Fatalf("Unexpected error from RegisterSSHTunnel: %v", err)
+	}
+	tunnel := sshTunnels.byNetwork[network]
+	if _, err := tunnel.connect(context.Background()); err != nil {
+		t.Fatalf("Unexpected error from connect: %v", err)
+	}
+	defer tunnel.reset(tunnel.client)
+	select {
+	case keys := <-forwardedKeys:
+		if len(keys) != 1 || !bytes.Equal(keys[0].Marshal(), clientSigner.PublicKey().Marshal()) {
+			t.Errorf("Unexpected keys listed through forwarded agent: %v", keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Timed out waiting for server to use forwarded agent")
+	}
+}
+
+// newTestSSHSigner generates a new ed25519 key. If keyFile is non-empty, the
+// private key is also written there in PEM format.
+func newTestSSHSigner(t *testing.T, keyFile string) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Unable to create signer: %v", err)
+	}
+	if keyFile != "" {
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			t.Fatalf("Unable to marshal key: %v", err)
+		}
+		pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(keyFile, pemBytes, 0600); err != nil {
+			t.Fatalf("Unable to write key file: %v", err)
+		}
+	}
+	return signer
+}
+
+// startTestSSHServer runs an in-process SSH server which only supports port
+// forwarding (direct-tcpip channels) and agent forwarding, and only permits
+// authentication using authorizedKey. It returns the server's address, and a
+// channel which receives the keys listed by any forwarded agent.
+func startTestSSHServer(t *testing.T, hostSigner ssh.Signer, authorizedKey ssh.PublicKey) (string, <-chan []*agent.Key) {
+	t.Helper()
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	forwardedKeys := make(chan []*agent.Key, 1)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				defer sshConn.Close()
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					if newChan.ChannelType() == "session" {
+						ch, chReqs, err := newChan.Accept()
+						if err != nil {
+							continue
+						}
+						go func() {
+							defer ch.Close()
+							for req := range chReqs {
+								req.Reply(req.Type == "auth-agent-req@openssh.com", nil)
+								if req.Type != "auth-agent-req@openssh.com" {
+									continue
+								}
+								agentCh, agentReqs, err := sshConn.OpenChannel("auth-agent@openssh.com", nil)
+								if err != nil {
+									continue
+								}
+								go ssh.DiscardRequests(agentReqs)
+								keys, _ := agent.NewClient(agentCh).List()
+								agentCh.Close()
+								forwardedKeys <- keys
+							}
+						}()
+						continue
+					} else if newChan.ChannelType() != "direct-tcpip" {
+						newChan.Reject(ssh.UnknownChannelType, "only direct-tcpip is supported")
+						continue
+					}
+					var payload struct {
+						DestAddr string
+						DestPort uint32
+						OrigAddr string
+						OrigPort uint32
+					}
+					if err := ssh.Unmarshal(newChan.ExtraData(), &payload); err != nil {
+						newChan.Reject(ssh.Prohibited, "invalid payload")
+						continue
+					}
+					target, err := net.Dial("tcp", net.JoinHostPort(payload.DestAddr, fmt.Sprint(payload.DestPort)))
+					if err != nil {
+						newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, chReqs, err := newChan.Accept()
+					if err != nil {
+						target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(chReqs)
+					go func() {
+						io.Copy(ch, target)
+						ch.Close()
+						target.Close()
+					}()
+					go io.Copy(target, ch)
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), forwardedKeys
+}
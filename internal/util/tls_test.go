@@ -0,0 +1,172 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	caCert, caKey := newTestCert(t, "Test CA", nil, nil, tempDir, "ca")
+	serverCert, serverKey := newTestCert(t, "db.example.com", caCert, caKey, tempDir, "server")
+	newTestCert(t, "client", caCert, caKey, tempDir, "client")
+	otherCACert, otherCAKey := newTestCert(t, "Other CA", nil, nil, tempDir, "otherca")
+	newTestCert(t, "client", otherCACert, otherCAKey, tempDir, "otherclient")
+
+	// TLS server requiring a client cert signed by the CA
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Write([]byte("x"))
+			conn.Close()
+		}
+	}()
+
+	path := func(name string) string { return filepath.Join(tempDir, name) }
+	assertHandshake := func(opts TLSOptions, serverName string, expectSuccess bool) {
+		t.Helper()
+		config, err := opts.tlsConfig()
+		if err != nil {
+			t.Fatalf("Unexpected error from tlsConfig with %+v: %v", opts, err)
+		}
+		config.ServerName = serverName // the MySQL driver does this automatically for verify_identity
+		conn, err := tls.Dial("tcp", listener.Addr().String(), config)
+		if err == nil {
+			// With TLS 1.3 the client cert is verified after the client handshake
+			// completes, so a read is required to detect rejection by the server
+			_, err = conn.Read(make([]byte, 1))
+			conn.Close()
+		}
+		if expectSuccess && err != nil {
+			t.Errorf("Expected success with %+v serverName=%q, instead found %v", opts, serverName, err)
+		} else if !expectSuccess && err == nil {
+			t.Errorf("Expected failure with %+v serverName=%q, but err was nil", opts, serverName)
+		}
+	}
+	withClient := TLSOptions{CertFile: path("client.pem"), KeyFile: path("client-key.pem")}
+
+	opts := withClient
+	opts.Mode = "required"
+	assertHandshake(opts, "", true)
+	opts.Mode, opts.CAFile = "verify_ca", path("ca.pem")
+	assertHandshake(opts, "wrong.example.com", true)
+	opts.CAFile = path("otherca.pem")
+	assertHandshake(opts, "wrong.example.com", false)
+	opts.Mode, opts.CAFile = "verify_identity", path("ca.pem")
+	assertHandshake(opts, "db.example.com", true)
+	assertHandshake(opts, "wrong.example.com", false)
+	opts.CertFile, opts.KeyFile = path("otherclient.pem"), path("otherclient-key.pem")
+	assertHandshake(opts, "db.example.com", false)
+	assertHandshake(TLSOptions{Mode: "verify_ca", CAFile: path("ca.pem")}, "", false) // no client cert
+
+	// Invalid options
+	invalid := []TLSOptions{
+		{Mode: "preferred"},
+		{Mode: "required", CAFile: path("nonexistent.pem")},
+		{Mode: "required", CAFile: path("client-key.pem")},
+		{Mode: "required", CertFile: path("client.pem")},
+		{Mode: "required", CertFile: path("client.pem"), KeyFile: path("otherclient-key.pem")},
+		{Mode: "required", Ciphers: "NOT-A-REAL-CIPHER"},
+	}
+	for _, opts := range invalid {
+		if _, err := RegisterTLSConfig(opts); err == nil {
+			t.Errorf("Expected error from RegisterTLSConfig with %+v, but err was nil", opts)
+		}
+	}
+	opts = withClient
+	opts.Mode = "required"
+	name1, err := RegisterTLSConfig(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from RegisterTLSConfig: %v", err)
+	}
+	if name2, err := RegisterTLSConfig(opts); err != nil || name2 != name1 {
+		t.Errorf("Expected repeated RegisterTLSConfig to return %q, nil; instead found %q, %v", name1, name2, err)
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := parseCipherSuites("ECDHE-RSA-AES128-GCM-SHA256:TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: ")
+	if err != nil {
+		t.Fatalf("Unexpected error from parseCipherSuites: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 || ids[1] != tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384 {
+		t.Errorf("Unexpected result from parseCipherSuites: %v", ids)
+	}
+	if _, err := parseCipherSuites("AES128-SHA:bogus"); err == nil {
+		t.Error("Expected error from unknown cipher, but err was nil")
+	}
+}
+
+// newTestCert generates a certificate and key, writing them to dir as
+// name.pem and name-key.pem. If parent is nil, a self-signed CA is generated.
+func newTestCert(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, dir, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		template.DNSNames = []string{commonName}
+		signer, signerKey = parent, parentKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unable to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unable to marshal key: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0600); err != nil {
+		t.Fatalf("Unable to write certificate: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600); err != nil {
+		t.Fatalf("Unable to write key: %v", err)
+	}
+	return cert, key
+}
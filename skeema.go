@@ -38,12 +38,12 @@ func main() {
 	var cfg *mybase.Config
 	cfg, err := mybase.ParseCLI(CommandSuite, os.Args)
 	if err != nil {
-		Exit(NewExitValue(CodeBadConfig, err.Error()))
+		Exit(NewExitValue(CodeBadConfig, "%s", err))
 	}
 
 	util.AddGlobalConfigFiles(cfg)
 	if err := util.ProcessSpecialGlobalOptions(cfg); err != nil {
-		Exit(NewExitValue(CodeBadConfig, err.Error()))
+		Exit(NewExitValue(CodeBadConfig, "%s", err))
 	}
 
 	err = cfg.HandleCommand()
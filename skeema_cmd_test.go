@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -295,6 +296,41 @@ func (s SkeemaIntegrationSuite) TestLintHandler(t *testing.T) {
 	// Invalid options should error with CodeBadConfig
 	s.handleCommand(t, CodeBadConfig, ".", "skeema lint --workspace=doesnt-exist")
 	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema lint --password=wrong")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema lint --write-baseline")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema lint --baseline=doesnt-exist.json")
+
+	// Writing a baseline should succeed even if problems are found, and then
+	// linting against that baseline should report nothing
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --lint-pk=warning --baseline=lint-baseline.json --write-baseline")
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --lint-pk=warning --baseline=lint-baseline.json --baseline-report-fixed")
+	if err := os.Remove("lint-baseline.json"); err != nil {
+		t.Fatalf("Unable to remove baseline file: %v", err)
+	}
+
+	// A relative baseline path in an option file is relative to that file's dir,
+	// not the working directory
+	file := getOptionFile(t, "mydb", cfg)
+	file.SetOptionValue("", "baseline", "lint-baseline.json")
+	if err := file.Write(true); err != nil {
+		t.Fatalf("Unable to write %s: %v", file.Path(), err)
+	}
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema lint --lint-pk=warning --write-baseline")
+	baselinePath := filepath.Join(s.scratchPath(), "mydb", "lint-baseline.json")
+	if _, err := os.Stat(baselinePath); err != nil {
+		t.Errorf("Expected baseline file to be written in dir of option file: %v", err)
+	}
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema lint --lint-pk=warning")
+	file.UnsetOptionValue("", "baseline")
+	if err := file.Write(true); err != nil {
+		t.Fatalf("Unable to write %s: %v", file.Path(), err)
+	}
+	if err := os.Remove(baselinePath); err != nil {
+		t.Fatalf("Unable to remove baseline file: %v", err)
+	}
+
+	// With nothing to fix, --fix should be a no-op
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --fix")
+	s.verifyFiles(t, cfg, "../golden/init")
 
 	// Alter a few files in a way that is still valid SQL, but doesn't match
 	// the database's native format. Lint with --skip-format should do nothing;